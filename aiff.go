@@ -0,0 +1,189 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+)
+
+/*
+ReadAiffFile returns the demultiplexed channels of an AIFF or uncompressed
+AIFF-C file, the sample rate in Hz and the bits per sample. The samples are
+mapped linearly from their integer range to [0,1], as by ReadWavFile.
+*/
+func ReadAiffFile(aiffName string) (channels [][]float64, sampleRate, bitsPerSample int) {
+	channels, sampleRate, bitsPerSample, err := ReadAiffFileErr(aiffName)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+ReadAiffFileErr returns ReadAiffFile(aiffName) or the error reading or
+decoding the file.
+*/
+func ReadAiffFileErr(aiffName string) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	b, err := ioutil.ReadFile(aiffName)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return decodeAiff(b)
+}
+
+/*
+ReadAiffFrom returns the demultiplexed channels, sample rate and bits per
+sample of the AIFF file read from r, as by ReadAiffFile, or the error reading
+or decoding r.
+*/
+func ReadAiffFrom(r io.Reader) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return decodeAiff(b)
+}
+
+// decodeAiff returns the channels, sample rate and bits per sample of the AIFF file b
+func decodeAiff(b []byte) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	if len(b) < 12 || string(b[:4]) != "FORM" || (string(b[8:12]) != "AIFF" && string(b[8:12]) != "AIFC") {
+		return nil, 0, 0, fmt.Errorf("%w: not an AIFF file", ErrArgument)
+	}
+	var numChannels int
+	var data []byte
+	bigEndian, hasComm := true, false
+	for pos := 12; pos+8 <= len(b); {
+		id, size32 := string(b[pos:pos+4]), binary.BigEndian.Uint32(b[pos+4:])
+		pos += 8
+		// The size is compared unsigned because it may not fit an int
+		if uint64(size32) > uint64(len(b)-pos) {
+			return nil, 0, 0, fmt.Errorf("%w: %q chunk size %d exceeds file", ErrLength, id, size32)
+		}
+		size := int(size32)
+		chunk := b[pos : pos+size]
+		switch id {
+		case "COMM":
+			if len(chunk) < 18 {
+				return nil, 0, 0, fmt.Errorf("%w: COMM chunk size %d", ErrLength, len(chunk))
+			}
+			numChannels = int(binary.BigEndian.Uint16(chunk))
+			bitsPerSample = int(binary.BigEndian.Uint16(chunk[6:]))
+			sampleRate = int(math.Round(extendedToFloat64(chunk[8:18])))
+			if string(b[8:12]) == "AIFC" && len(chunk) >= 22 {
+				switch compression := string(chunk[18:22]); compression {
+				case "NONE", "twos":
+				case "sowt":
+					bigEndian = false
+				default:
+					return nil, 0, 0, fmt.Errorf("%w: AIFF-C compression %q", ErrArgument, compression)
+				}
+			}
+			hasComm = true
+		case "SSND":
+			if len(chunk) < 8 {
+				return nil, 0, 0, fmt.Errorf("%w: SSND chunk size %d", ErrLength, len(chunk))
+			}
+			offset := binary.BigEndian.Uint32(chunk)
+			if uint64(offset) > uint64(len(chunk)-8) {
+				return nil, 0, 0, fmt.Errorf("%w: SSND offset %d", ErrLength, offset)
+			}
+			data = chunk[8+int(offset):]
+		}
+		pos += size + size%2
+	}
+	if !hasComm || data == nil || numChannels == 0 {
+		return nil, 0, 0, fmt.Errorf("%w: AIFF file without COMM or SSND chunk", ErrArgument)
+	}
+	// Samples are stored in whole bytes
+	samples, err := decodePCM(data, (bitsPerSample+7)/8*8, bigEndian)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return Demultiplex(samples, numChannels), sampleRate, bitsPerSample, nil
+}
+
+/*
+ReadRawPCM returns the demultiplexed channels of a headerless file of
+interleaved signed integer PCM samples with bitsPerSample of 8, 16, 24 or 32.
+The samples are mapped linearly from their integer range to [0,1], as by
+ReadWavFile. sampleRate and bitsPerSample are returned unchanged, so that the
+result has the shape of the result of ReadWavFile.
+*/
+func ReadRawPCM(fname string, sampleRate, bitsPerSample, numChannels int, bigEndian bool) (
+	channels [][]float64, rate, bits int) {
+
+	channels, rate, bits, err := ReadRawPCMErr(fname, sampleRate, bitsPerSample, numChannels, bigEndian)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+ReadRawPCMErr returns ReadRawPCM(fname, sampleRate, bitsPerSample,
+numChannels, bigEndian) or the error reading the file or an error wrapping
+ErrArgument for an invalid bitsPerSample or numChannels.
+*/
+func ReadRawPCMErr(fname string, sampleRate, bitsPerSample, numChannels int, bigEndian bool) (
+	channels [][]float64, rate, bits int, err error) {
+
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+	return ReadRawPCMFrom(f, sampleRate, bitsPerSample, numChannels, bigEndian)
+}
+
+/*
+ReadRawPCMFrom returns ReadRawPCM for the samples read from r, or the error
+reading r or an error wrapping ErrArgument for an invalid bitsPerSample or
+numChannels.
+*/
+func ReadRawPCMFrom(r io.Reader, sampleRate, bitsPerSample, numChannels int, bigEndian bool) (
+	channels [][]float64, rate, bits int, err error) {
+
+	if numChannels < 1 {
+		return nil, 0, 0, fmt.Errorf("%w: numChannels = %d", ErrArgument, numChannels)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	samples, err := decodePCM(b, bitsPerSample, bigEndian)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return Demultiplex(samples, numChannels), sampleRate, bitsPerSample, nil
+}
+
+// extendedToFloat64 returns the 80 bit IEEE 754 extended precision number in b
+func extendedToFloat64(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b) & 0x7FFF)
+	mantissa := binary.BigEndian.Uint64(b[2:])
+	if exp == 0 && mantissa == 0 {
+		return 0
+	}
+	f := math.Ldexp(float64(mantissa), exp-16383-63)
+	if b[0]&0x80 != 0 {
+		f = -f
+	}
+	return f
+}
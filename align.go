@@ -0,0 +1,73 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Shift returns a copy of x delayed by lag samples: y[n] = x[n-lag]. A negative
+lag advances x. Samples shifted in from outside x are zero.
+*/
+func Shift[T Float](x []T, lag int) []T {
+	y := make([]T, len(x))
+	for n := range y {
+		if k := n - lag; k >= 0 && k < len(x) {
+			y[n] = x[k]
+		}
+	}
+	return y
+}
+
+/*
+AlignByXcorr returns copies of the overlapping parts of x and y aligned by the
+lag in [-maxLag,maxLag] of the maximum of XcorrNormalized(x, y, maxLag), and
+the lag. A positive lag means that y is delayed with respect to x. Sample i of
+xa and ya is sample i of x and sample i+lag of y for lag >= 0, and sample i-lag
+of x and sample i of y for lag < 0. If x or y has zero energy the lag is 0.
+The function panics if maxLag < 0.
+*/
+func AlignByXcorr(x, y []float64, maxLag int) (xa, ya []float64, lag int) {
+	if maxLag < 0 {
+		panic(fmt.Errorf("%w: maxLag = %d", ErrArgument, maxLag))
+	}
+	corr := XcorrNormalized(x, y, maxLag)
+	best := math.Inf(-1)
+	for i, c := range corr {
+		if c > best {
+			best, lag = c, i-maxLag
+		}
+	}
+	if Max(Abs(corr)) == 0 {
+		lag = 0
+	}
+	xs, ys := 0, lag
+	if lag < 0 {
+		xs, ys = -lag, 0
+	}
+	n := len(x) - xs
+	if len(y)-ys < n {
+		n = len(y) - ys
+	}
+	if n <= 0 {
+		return []float64{}, []float64{}, lag
+	}
+	xa, ya = make([]float64, n), make([]float64, n)
+	copy(xa, x[xs:])
+	copy(ya, y[ys:])
+	return xa, ya, lag
+}
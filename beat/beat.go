@@ -0,0 +1,125 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package beat estimates the tempo of onset envelopes and tracks their beats.
+The onset envelope may be an onset detection function (package onset) or the
+rectified and downsampled coefficients of the DWT (package dwt). envRate is
+the number of envelope samples per second.
+
+BeatDetector composes the stages from audio samples to beats and tempo.
+
+The beat tracker is the dynamic programming algorithm of:
+D. P. W. Ellis. Beat Tracking by Dynamic Programming.
+Journal of New Music Research, 36(1), 2007.
+*/
+package beat
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/goccmack/godsp"
+)
+
+// DefaultTightness is the default weight of the tempo consistency of Track
+const DefaultTightness = 100
+
+/*
+Tempo returns the tempo in BPM of the onset envelope env in the range minBPM
+to maxBPM. It is the lag of the maximum of the unbiased autocorrelation of env.
+The function panics if the range is empty or outside the length of env.
+*/
+func Tempo(env []float64, envRate, minBPM, maxBPM float64) float64 {
+	minLag := int(math.Floor(60 * envRate / maxBPM))
+	maxLag := int(math.Ceil(60 * envRate / minBPM))
+	if minLag < 1 {
+		minLag = 1
+	}
+	if maxLag >= len(env) || minLag > maxLag {
+		panic(fmt.Sprintf("Invalid BPM range %f to %f for envelope of %d samples",
+			minBPM, maxBPM, len(env)))
+	}
+	env0 := godsp.Detrend(env, godsp.Constant)
+	r := godsp.Autocorr(env0, maxLag, godsp.Unbiased)
+	_, lag := godsp.FindMax(r[minLag : maxLag+1])
+	return 60 * envRate / float64(lag+minLag)
+}
+
+/*
+Track returns the indices in env of the beats of the onset envelope env with
+tempo bpm. tightness weights the consistency of the inter-beat intervals with
+the tempo against the strength of the onsets at the beats. See
+DefaultTightness.
+*/
+func Track(env []float64, envRate, bpm, tightness float64) []int {
+	if len(env) == 0 {
+		return []int{}
+	}
+	period := 60 * envRate / bpm
+	std := godsp.Std(env)
+	if std == 0 {
+		std = 1
+	}
+	score := make([]float64, len(env))
+	backlink := make([]int, len(env))
+	for t := range env {
+		localScore := env[t] / std
+		backlink[t] = -1
+		best := math.Inf(-1)
+		from, to := t-int(math.Round(2*period)), t-int(math.Round(period/2))
+		if from < 0 {
+			from = 0
+		}
+		for tau := from; tau <= to; tau++ {
+			d := math.Log(float64(t-tau) / period)
+			if s := score[tau] - tightness*d*d; s > best {
+				best, backlink[t] = s, tau
+			}
+		}
+		if backlink[t] >= 0 {
+			score[t] = localScore + best
+		} else {
+			score[t] = localScore
+		}
+	}
+	// The last beat is the best scoring sample in the last period
+	from := len(env) - int(math.Round(period))
+	if from < 0 {
+		from = 0
+	}
+	_, last := godsp.FindMax(score[from:])
+	var beats []int
+	for t := last + from; t >= 0; t = backlink[t] {
+		beats = append(beats, t)
+	}
+	for i, j := 0, len(beats)-1; i < j; i, j = i+1, j-1 {
+		beats[i], beats[j] = beats[j], beats[i]
+	}
+	return beats
+}
+
+/*
+Beats returns the times in seconds of the beats of the onset envelope env and
+its tempo in the range minBPM to maxBPM.
+*/
+func Beats(env []float64, envRate, minBPM, maxBPM float64) (beats []float64, bpm float64) {
+	bpm = Tempo(env, envRate, minBPM, maxBPM)
+	idx := Track(env, envRate, bpm, DefaultTightness)
+	beats = make([]float64, len(idx))
+	for i, t := range idx {
+		beats[i] = float64(t) / envRate
+	}
+	return
+}
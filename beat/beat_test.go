@@ -0,0 +1,93 @@
+package beat
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestBeats(t *testing.T) {
+	envRate, bpm := 100.0, 120.0
+	env := make([]float64, 3000)
+	// Onsets every 0.5 s starting at 0.2 s
+	for i := 20; i < len(env); i += 50 {
+		env[i], env[i+1] = 1, 0.5
+	}
+	beats, tempo := Beats(env, envRate, 60, 200)
+	if math.Abs(tempo-bpm) > 2 {
+		t.Errorf("tempo = %f, want %f", tempo, bpm)
+	}
+	if len(beats) < 55 {
+		t.Fatalf("len(beats) = %d", len(beats))
+	}
+	for _, b := range beats {
+		if d := math.Mod(b-0.2, 0.5); d > 0.02 && d < 0.48 {
+			t.Fatalf("beat %f is not on an onset", b)
+		}
+	}
+}
+
+func TestCombTempo(t *testing.T) {
+	envRate, bpm := 100.0, 120.0
+	// A low band with onsets every beat and a high band with onsets every half beat
+	low, high := make([]float64, 2000), make([]float64, 2000)
+	for i := 20; i < len(low); i += 50 {
+		low[i] = 1
+		high[i], high[i+25] = 0.5, 0.5
+	}
+	bpms, salience := CombSalience([][]float64{low, high}, envRate, 80, 160, 1)
+	if len(bpms) != 81 || len(salience) != 81 || bpms[80] != 160 {
+		t.Fatalf("len(bpms) = %d, len(salience) = %d", len(bpms), len(salience))
+	}
+	if tempo := CombTempo([][]float64{low, high}, envRate, 80, 160); math.Abs(tempo-bpm) > 2 {
+		t.Errorf("tempo = %f, want %f", tempo, bpm)
+	}
+}
+
+func TestIOIs(t *testing.T) {
+	if h := IOIHistogram([]int{10, 0, 5, 30}, 12); fmt.Sprint(h) != "[0 0 0 0 0 2 0 0 0 0 1 0 0]" {
+		t.Errorf("IOIHistogram = %v", h)
+	}
+	// Onsets every 0.5 s with up to 5 ms of jitter and 2 unrelated onsets
+	sampleRate := 44100.0
+	onsets := []int{}
+	for i := 0; i < 40; i++ {
+		jitter := 0.005 * math.Sin(float64(i))
+		onsets = append(onsets, int((1+0.5*float64(i)+jitter)*sampleRate))
+	}
+	onsets = append(onsets, int(3.3*sampleRate), int(11.1*sampleRate))
+	cs := ClusterIOIs(onsets, sampleRate, 80, 160)
+	if len(cs) == 0 || math.Abs(cs[0].BPM-120) > 1 || cs[0].Weight < 39 {
+		t.Errorf("candidates = %+v", cs)
+	}
+}
+
+func TestBeatDetector(t *testing.T) {
+	// Decaying 1 kHz clicks at 120 BPM starting at 0.3 s on 2 channels
+	sampleRate := 8000
+	x := make([]float64, 20*sampleRate)
+	for start := int(0.3 * float64(sampleRate)); start < len(x); start += sampleRate / 2 {
+		for i := 0; i < 400 && start+i < len(x); i++ {
+			x[start+i] = math.Sin(2*math.Pi*1000*float64(i)/float64(sampleRate)) * math.Exp(-float64(i)/80)
+		}
+	}
+	beats, bpm := NewBeatDetector().Process([][]float64{x, x}, sampleRate)
+	if math.Abs(bpm-120) > 2 {
+		t.Errorf("bpm = %f", bpm)
+	}
+	if len(beats) < 35 {
+		t.Fatalf("len(beats) = %d", len(beats))
+	}
+	for _, b := range beats {
+		if d := math.Mod(b-0.3, 0.5); d > 0.03 && d < 0.47 {
+			t.Fatalf("beat %f is not on a click", b)
+		}
+	}
+	// A replaced tempo stage
+	_, bpm = NewBeatDetector(WithTempo(func(env []float64, _ []int, envRate, minBPM, maxBPM float64) float64 {
+		return CombTempo([][]float64{env}, envRate, minBPM, maxBPM)
+	}), WithBPMRange(80, 160)).Process([][]float64{x}, sampleRate)
+	if math.Abs(bpm-120) > 2 {
+		t.Errorf("comb bpm = %f", bpm)
+	}
+}
@@ -0,0 +1,114 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package beat
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+CombHalfLife is the time in seconds in which the response of the comb filters
+of CombSalience to an impulse decays to half its energy.
+*/
+const CombHalfLife = 1.5
+
+/*
+CombSalience returns the tempo salience of the band envelopes envs at the
+tempos minBPM, minBPM+step, ... <= maxBPM by a bank of resonating comb
+filters:
+E. D. Scheirer. Tempo and beat analysis of acoustic musical signals.
+J. Acoust. Soc. Am. 103(1), 1998.
+
+The envelopes, e.g. the rectified and downsampled DWT coefficients of several
+levels brought to the common rate envRate, are differentiated and half-wave
+rectified. The comb filter of a tempo with period T samples is
+
+	y[n] = a y[n-T] + (1-a) x[n]
+
+where a is set by CombHalfLife. The salience of a tempo is the energy of the
+outputs of its comb filter summed over the bands. The tempo candidates are the
+peaks of the salience, e.g. peaks.Get(salience, sep).
+The function panics if the BPM range is empty or step <= 0.
+*/
+func CombSalience(envs [][]float64, envRate, minBPM, maxBPM, step float64) (bpms, salience []float64) {
+	if minBPM <= 0 || maxBPM < minBPM || step <= 0 {
+		panic(fmt.Sprintf("Invalid BPM range %f to %f step %f", minBPM, maxBPM, step))
+	}
+	onsets := make([][]float64, len(envs))
+	for i, env := range envs {
+		onsets[i] = rectifiedDiff(env)
+	}
+	for bpm := minBPM; bpm <= maxBPM+step/2; bpm += step {
+		bpms = append(bpms, bpm)
+		T := 60 * envRate / bpm
+		a := math.Pow(0.5, T/(CombHalfLife*envRate))
+		energy := 0.0
+		for _, x := range onsets {
+			energy += combEnergy(x, T, a)
+		}
+		salience = append(salience, energy)
+	}
+	return
+}
+
+/*
+CombTempo returns the tempo in BPM in the range minBPM to maxBPM, in steps of
+0.5 BPM, with the largest comb filter salience of the band envelopes envs. See
+CombSalience.
+*/
+func CombTempo(envs [][]float64, envRate, minBPM, maxBPM float64) float64 {
+	bpms, salience := CombSalience(envs, envRate, minBPM, maxBPM, 0.5)
+	best := 0
+	for i, s := range salience {
+		if s > salience[best] {
+			best = i
+		}
+	}
+	return bpms[best]
+}
+
+// rectifiedDiff returns the half-wave rectified first difference of x
+func rectifiedDiff(x []float64) []float64 {
+	d := make([]float64, len(x))
+	for i := 1; i < len(x); i++ {
+		d[i] = math.Max(x[i]-x[i-1], 0)
+	}
+	return d
+}
+
+/*
+combEnergy returns the energy of the output of the comb filter with feedback
+gain a and delay T samples applied to x. The fractional delay is linearly
+interpolated.
+*/
+func combEnergy(x []float64, T, a float64) float64 {
+	y := make([]float64, len(x))
+	d := int(T)
+	frac := T - float64(d)
+	energy := 0.0
+	for n, v := range x {
+		delayed := 0.0
+		if n-d >= 0 {
+			delayed = (1 - frac) * y[n-d]
+		}
+		if n-d-1 >= 0 {
+			delayed += frac * y[n-d-1]
+		}
+		y[n] = a*delayed + (1-a)*v
+		energy += y[n] * y[n]
+	}
+	return energy
+}
@@ -0,0 +1,219 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package beat
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/dwt"
+	"github.com/goccmack/godsp/peaks"
+)
+
+/*
+EnvelopeFunc returns the onset envelope of the mono signal x at sampleRate and
+the number of envelope samples per second.
+*/
+type EnvelopeFunc func(x []float64, sampleRate int) (env []float64, envRate float64)
+
+// OnsetFunc returns the indices in env of the onsets of the onset envelope env
+type OnsetFunc func(env []float64, envRate float64) []int
+
+/*
+TempoFunc returns the tempo in BPM in the range minBPM to maxBPM of the onset
+envelope env and its onsets.
+*/
+type TempoFunc func(env []float64, onsets []int, envRate, minBPM, maxBPM float64) float64
+
+// TrackFunc returns the indices in env of the beats of the onset envelope env with tempo bpm
+type TrackFunc func(env []float64, envRate, bpm float64) []int
+
+/*
+BeatDetector finds the beats and tempo of audio by a pipeline of stages:
+
+	channels -> mono -> onset envelope -> onsets -> tempo -> beats
+
+The default stages are DWTEnvelope(DefaultEnvelopeLevels, DefaultEnvelopeRate),
+AdaptiveOnsets, IOITempo and Track with DefaultTightness. Each stage can be
+replaced by an Option.
+*/
+type BeatDetector struct {
+	minBPM, maxBPM float64
+	envelope       EnvelopeFunc
+	onsets         OnsetFunc
+	tempo          TempoFunc
+	track          TrackFunc
+}
+
+// Option configures a BeatDetector
+type Option func(*BeatDetector)
+
+const (
+	// DefaultMinBPM is the default lowest tempo of a BeatDetector
+	DefaultMinBPM = 60
+	// DefaultMaxBPM is the default highest tempo of a BeatDetector
+	DefaultMaxBPM = 200
+	// DefaultEnvelopeLevels is the number of DWT levels of the default envelope of a BeatDetector
+	DefaultEnvelopeLevels = 5
+	// DefaultEnvelopeRate is the number of samples per second of the default envelope of a BeatDetector
+	DefaultEnvelopeRate = 100
+)
+
+// NewBeatDetector returns a BeatDetector with the default stages modified by opts
+func NewBeatDetector(opts ...Option) *BeatDetector {
+	d := &BeatDetector{
+		minBPM:   DefaultMinBPM,
+		maxBPM:   DefaultMaxBPM,
+		envelope: DWTEnvelope(DefaultEnvelopeLevels, DefaultEnvelopeRate),
+		onsets:   AdaptiveOnsets,
+		tempo:    IOITempo,
+		track: func(env []float64, envRate, bpm float64) []int {
+			return Track(env, envRate, bpm, DefaultTightness)
+		},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+/*
+WithBPMRange sets the range of tempos of a BeatDetector. The default range is
+DefaultMinBPM to DefaultMaxBPM.
+The function panics if the range is empty.
+*/
+func WithBPMRange(minBPM, maxBPM float64) Option {
+	if minBPM <= 0 || maxBPM < minBPM {
+		panic(fmt.Sprintf("Invalid BPM range %f to %f", minBPM, maxBPM))
+	}
+	return func(d *BeatDetector) {
+		d.minBPM, d.maxBPM = minBPM, maxBPM
+	}
+}
+
+// WithEnvelope replaces the onset envelope stage of a BeatDetector
+func WithEnvelope(f EnvelopeFunc) Option {
+	return func(d *BeatDetector) {
+		d.envelope = f
+	}
+}
+
+// WithOnsets replaces the onset picking stage of a BeatDetector
+func WithOnsets(f OnsetFunc) Option {
+	return func(d *BeatDetector) {
+		d.onsets = f
+	}
+}
+
+// WithTempo replaces the tempo estimation stage of a BeatDetector
+func WithTempo(f TempoFunc) Option {
+	return func(d *BeatDetector) {
+		d.tempo = f
+	}
+}
+
+// WithTracker replaces the beat tracking stage of a BeatDetector
+func WithTracker(f TrackFunc) Option {
+	return func(d *BeatDetector) {
+		d.track = f
+	}
+}
+
+/*
+Process returns the times in seconds of the beats of the audio channels at
+sampleRate and its tempo in BPM. The channels are averaged to mono.
+*/
+func (d *BeatDetector) Process(channels [][]float64, sampleRate int) (beats []float64, bpm float64) {
+	env, envRate := d.envelope(godsp.ToMono(channels, nil), sampleRate)
+	onsets := d.onsets(env, envRate)
+	bpm = d.tempo(env, onsets, envRate, d.minBPM, d.maxBPM)
+	idx := d.track(env, envRate, bpm)
+	beats = make([]float64, len(idx))
+	for i, t := range idx {
+		beats[i] = float64(t) / envRate
+	}
+	return
+}
+
+/*
+ProcessWavFile returns the times in seconds of the beats of the wav file
+wavName and its tempo in BPM. See Process.
+*/
+func (d *BeatDetector) ProcessWavFile(wavName string) (beats []float64, bpm float64) {
+	channels, sampleRate, _ := godsp.ReadWavFile(wavName)
+	return d.Process(channels, sampleRate)
+}
+
+/*
+DWTEnvelope returns an EnvelopeFunc that computes the onset envelope of x at
+envRate samples per second from the DWT of x with Daubechies 4 wavelets to
+levels. The rectified detail coefficients of each level are max-pooled over
+the frames of the envelope and normalised by their mean, and the half-wave
+rectified first difference of the sum of the levels is the onset envelope.
+The function panics if levels < 1 or envRate <= 0.
+*/
+func DWTEnvelope(levels int, envRate float64) EnvelopeFunc {
+	if levels < 1 || envRate <= 0 {
+		panic(fmt.Sprintf("Invalid levels %d or envRate %f", levels, envRate))
+	}
+	return func(x []float64, sampleRate int) ([]float64, float64) {
+		hop := math.Max(1, math.Round(float64(sampleRate)/envRate))
+		n := int(float64(len(x)) / hop)
+		env := make([]float64, n)
+		if n == 0 {
+			return env, float64(sampleRate) / hop
+		}
+		t := dwt.Daubechies4(x, levels, dwt.WithPadding(dwt.SymmetricPadding))
+		band := make([]float64, n)
+		for _, cf := range t.GetCoefficients() {
+			for i := range band {
+				band[i] = 0
+				for j := i * len(cf) / n; j < (i+1)*len(cf)/n; j++ {
+					band[i] = math.Max(band[i], math.Abs(cf[j]))
+				}
+			}
+			if avg := godsp.Average(band); avg > 0 {
+				for i, b := range band {
+					env[i] += b / avg
+				}
+			}
+		}
+		return rectifiedDiff(env), float64(sampleRate) / hop
+	}
+}
+
+/*
+AdaptiveOnsets returns the peaks of env, separated by at least 50 ms, that
+exceed the median plus 2 median absolute deviations of the surrounding 2
+seconds of env. See peaks.GetAdaptive.
+*/
+func AdaptiveOnsets(env []float64, envRate float64) []int {
+	sep := int(math.Max(1, math.Round(0.05*envRate)))
+	window := int(math.Max(1, math.Round(2*envRate)))
+	return peaks.GetAdaptive(env, sep, window, peaks.LocalMedian, 2)
+}
+
+/*
+IOITempo returns the tempo of the strongest cluster of the inter-onset
+intervals of onsets. See ClusterIOIs. If the onsets have no intervals in the
+range minBPM to maxBPM the tempo of env is estimated by Tempo.
+*/
+func IOITempo(env []float64, onsets []int, envRate, minBPM, maxBPM float64) float64 {
+	if cs := ClusterIOIs(onsets, envRate, minBPM, maxBPM); len(cs) > 0 {
+		return cs[0].BPM
+	}
+	return Tempo(env, envRate, minBPM, maxBPM)
+}
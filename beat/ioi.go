@@ -0,0 +1,106 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package beat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/goccmack/godsp/dbscan"
+)
+
+// IOIBinWidth is the width in seconds of the bins of the IOI histogram of ClusterIOIs
+const IOIBinWidth = 0.01
+
+/*
+IOIClusterGap is the largest gap in bins of IOIBinWidth between the bins of a
+cluster of ClusterIOIs.
+*/
+const IOIClusterGap = 2
+
+// TempoCandidate is a tempo induced from the inter-onset intervals by ClusterIOIs
+type TempoCandidate struct {
+	// BPM is the tempo of the mean interval of the cluster
+	BPM float64
+	// Weight is the number of inter-onset intervals in the cluster
+	Weight int
+}
+
+/*
+IOIHistogram returns the histogram of the inter-onset intervals of onsets,
+the indices of the onsets in samples: h[lag] is the number of pairs of onsets,
+not only of consecutive onsets, that are lag samples apart, 0 < lag <= maxLag.
+The function panics if maxLag < 1.
+*/
+func IOIHistogram(onsets []int, maxLag int) []int {
+	if maxLag < 1 {
+		panic(fmt.Sprintf("Invalid maxLag %d", maxLag))
+	}
+	sorted := append([]int{}, onsets...)
+	sort.Ints(sorted)
+	h := make([]int, maxLag+1)
+	for i, t := range sorted {
+		for _, u := range sorted[i+1:] {
+			lag := u - t
+			if lag > maxLag {
+				break
+			}
+			if lag > 0 {
+				h[lag]++
+			}
+		}
+	}
+	return h
+}
+
+/*
+ClusterIOIs returns the tempo candidates in the range minBPM to maxBPM of the
+onsets, the indices of the onsets in samples at sampleRate. The inter-onset
+intervals of all pairs of onsets are collected in a histogram of bins of
+IOIBinWidth seconds, which is clustered by dbscan.Agglomerative with single
+linkage and cutoff IOIClusterGap. Each cluster is a candidate with the tempo of
+its centroid. The candidates are returned in decreasing order of Weight.
+The function panics if the BPM range is empty.
+*/
+func ClusterIOIs(onsets []int, sampleRate, minBPM, maxBPM float64) []TempoCandidate {
+	if minBPM <= 0 || maxBPM < minBPM {
+		panic(fmt.Sprintf("Invalid BPM range %f to %f", minBPM, maxBPM))
+	}
+	ticks := make([]int, len(onsets))
+	for i, t := range onsets {
+		ticks[i] = int(math.Round(float64(t) / sampleRate / IOIBinWidth))
+	}
+	minLag := int(math.Ceil(60 / maxBPM / IOIBinWidth))
+	maxLag := int(math.Floor(60 / minBPM / IOIBinWidth))
+	if maxLag < 1 {
+		maxLag = 1
+	}
+	h := IOIHistogram(ticks, maxLag)
+	for lag := 0; lag < minLag && lag < len(h); lag++ {
+		h[lag] = 0
+	}
+	candidates := []TempoCandidate{}
+	for _, c := range dbscan.Agglomerative(h, IOIClusterGap, dbscan.Single) {
+		candidates = append(candidates, TempoCandidate{
+			BPM:    60 / (c.Centroid * IOIBinWidth),
+			Weight: c.Weight,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Weight > candidates[j].Weight
+	})
+	return candidates
+}
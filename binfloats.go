@@ -0,0 +1,135 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+/*
+The binary file format of WriteBinaryFloats. All values are little endian:
+
+	magic      [8]byte "GODSPF64"
+	version    uint32
+	sampleRate float64  0 if the vector is not a sampled signal
+	n          uint64
+	x          [n]float64
+*/
+const (
+	binFloatsMagic   = "GODSPF64"
+	binFloatsVersion = 1
+	binFloatsHdrLen  = 8 + 4 + 8 + 8
+)
+
+/*
+WriteBinaryFloats writes x with its sampleRate to the file fname in a compact
+binary format that can be read by ReadBinaryFloats. sampleRate is 0 if x is not
+a sampled signal. The function panics if the file cannot be written.
+*/
+func WriteBinaryFloats(x []float64, sampleRate float64, fname string) {
+	if err := WriteBinaryFloatsErr(x, sampleRate, fname); err != nil {
+		panic(err)
+	}
+}
+
+// WriteBinaryFloatsErr is WriteBinaryFloats returning the write error.
+func WriteBinaryFloatsErr(x []float64, sampleRate float64, fname string) error {
+	buf := bytes.NewBuffer(make([]byte, 0, binFloatsHdrLen+8*len(x)))
+	WriteBinaryFloatsTo(buf, x, sampleRate)
+	return myioutil.WriteFile(fname, buf.Bytes())
+}
+
+/*
+WriteBinaryFloatsTo writes x with its sampleRate to w in the format of
+WriteBinaryFloats and returns the write error.
+*/
+func WriteBinaryFloatsTo(w io.Writer, x []float64, sampleRate float64) error {
+	hdr := make([]byte, binFloatsHdrLen)
+	copy(hdr, binFloatsMagic)
+	binary.LittleEndian.PutUint32(hdr[8:], binFloatsVersion)
+	binary.LittleEndian.PutUint64(hdr[12:], math.Float64bits(sampleRate))
+	binary.LittleEndian.PutUint64(hdr[20:], uint64(len(x)))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, x)
+}
+
+/*
+ReadBinaryFloats returns the vector and sample rate written to the file fname
+by WriteBinaryFloats. The function panics if the file cannot be read or is not
+a valid file.
+*/
+func ReadBinaryFloats(fname string) (x []float64, sampleRate float64) {
+	x, sampleRate, err := ReadBinaryFloatsErr(fname)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+ReadBinaryFloatsErr returns ReadBinaryFloats(fname) or the error reading the
+file or an error wrapping ErrArgument if the file is not valid.
+*/
+func ReadBinaryFloatsErr(fname string) (x []float64, sampleRate float64, err error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, 0, err
+	}
+	if x, sampleRate, err = decodeBinaryFloats(data); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", fname, err)
+	}
+	return x, sampleRate, nil
+}
+
+/*
+ReadBinaryFloatsFrom returns the vector and sample rate written to r in the
+format of WriteBinaryFloats, or the error reading r or an error wrapping
+ErrArgument if r does not contain a valid file.
+*/
+func ReadBinaryFloatsFrom(r io.Reader) (x []float64, sampleRate float64, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return decodeBinaryFloats(data)
+}
+
+func decodeBinaryFloats(data []byte) (x []float64, sampleRate float64, err error) {
+	if len(data) < binFloatsHdrLen || string(data[:8]) != binFloatsMagic {
+		return nil, 0, fmt.Errorf("%w: not a binary floats file", ErrArgument)
+	}
+	if version := binary.LittleEndian.Uint32(data[8:]); version != binFloatsVersion {
+		return nil, 0, fmt.Errorf("%w: unsupported binary floats version %d", ErrArgument, version)
+	}
+	sampleRate = math.Float64frombits(binary.LittleEndian.Uint64(data[12:]))
+	n := binary.LittleEndian.Uint64(data[20:])
+	if n > uint64(len(data)-binFloatsHdrLen)/8 {
+		return nil, 0, fmt.Errorf("%w: length %d exceeds file", ErrLength, n)
+	}
+	x = make([]float64, n)
+	for i := range x {
+		x[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[binFloatsHdrLen+8*i:]))
+	}
+	return x, sampleRate, nil
+}
@@ -0,0 +1,91 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+)
+
+/*
+Demultiplex returns the numChannels channels interleaved in samples. It is the
+inverse of Multiplex. A trailing incomplete frame is dropped.
+The function panics if numChannels < 1.
+*/
+func Demultiplex(samples []float64, numChannels int) [][]float64 {
+	if numChannels < 1 {
+		panic(fmt.Errorf("%w: numChannels = %d", ErrArgument, numChannels))
+	}
+	channels := make([][]float64, numChannels)
+	chanLen := len(samples) / numChannels
+	for i := range channels {
+		channels[i] = make([]float64, chanLen)
+	}
+	for i, j := 0, 0; j < chanLen; j++ {
+		for _, ch := range channels {
+			ch[j] = samples[i]
+			i++
+		}
+	}
+	return channels
+}
+
+/*
+ToMono returns the weighted sum of channels: y[i] = sum_c weights[c]*channels[c][i].
+If weights is nil the channels are averaged. Channels shorter than the longest
+channel are padded with zeros.
+The function panics if weights is not nil and len(weights) != len(channels).
+*/
+func ToMono(channels [][]float64, weights []float64) []float64 {
+	if weights == nil {
+		weights = constant(len(channels), 1/float64(len(channels)))
+	}
+	if len(weights) != len(channels) {
+		panic(fmt.Errorf("%w: %d weights for %d channels", ErrLength, len(weights), len(channels)))
+	}
+	y := make([]float64, maxLen(channels))
+	for c, ch := range channels {
+		for i, f := range ch {
+			y[i] += weights[c] * f
+		}
+	}
+	return y
+}
+
+/*
+ChannelSelect returns the channels with indices idx in the order of idx. The
+selected channels are not copied.
+The function panics if an index is out of range.
+*/
+func ChannelSelect(channels [][]float64, idx ...int) [][]float64 {
+	sel := make([][]float64, len(idx))
+	for i, c := range idx {
+		if c < 0 || c >= len(channels) {
+			panic(fmt.Errorf("%w: channel %d of %d", ErrArgument, c, len(channels)))
+		}
+		sel[i] = channels[c]
+	}
+	return sel
+}
+
+// maxLen returns the length of the longest vector in xs
+func maxLen(xs [][]float64) int {
+	n := 0
+	for _, x := range xs {
+		if len(x) > n {
+			n = len(x)
+		}
+	}
+	return n
+}
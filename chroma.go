@@ -0,0 +1,137 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"math"
+)
+
+// chromaMinFreq is the lowest frequency in Hz of the bins that contribute to Chroma
+const chromaMinFreq = 55
+
+/*
+Chroma returns the chromagram of x: the pitch class profile of each frame.
+Frame i starts at sample i*hop and has length frameLen. The power of each
+frequency bin of the Hann windowed frame from 55 Hz to sampleRate/2 is added
+to the pitch class of the nearest equal tempered semitone, tuned to A = 440 Hz.
+Element 0 of a frame is pitch class C, 1 is C#, ..., 11 is B. Each frame is
+scaled to a maximum of 1; a silent frame is zero.
+The function panics if frameLen < 2 or hop < 1.
+*/
+func Chroma(x []float64, sampleRate float64, frameLen, hop int) [][]float64 {
+	return ChromaTuned(x, sampleRate, frameLen, hop, 0)
+}
+
+/*
+ChromaErr returns Chroma(x, sampleRate, frameLen, hop) or an error wrapping
+ErrArgument if frameLen < 2 or hop < 1.
+*/
+func ChromaErr(x []float64, sampleRate float64, frameLen, hop int) ([][]float64, error) {
+	return ChromaTunedErr(x, sampleRate, frameLen, hop, 0)
+}
+
+/*
+ChromaTuned returns the chromagram of x like Chroma with the semitones tuned
+tuning semitones above A = 440 Hz. The tuning of a recording can be estimated
+by EstimateTuning.
+The function panics if frameLen < 2 or hop < 1.
+*/
+func ChromaTuned(x []float64, sampleRate float64, frameLen, hop int, tuning float64) [][]float64 {
+	C, err := ChromaTunedErr(x, sampleRate, frameLen, hop, tuning)
+	if err != nil {
+		panic(err)
+	}
+	return C
+}
+
+/*
+ChromaTunedErr returns ChromaTuned(x, sampleRate, frameLen, hop, tuning) or an
+error wrapping ErrArgument if frameLen < 2 or hop < 1.
+*/
+func ChromaTunedErr(x []float64, sampleRate float64, frameLen, hop int, tuning float64) ([][]float64, error) {
+	if err := checkFrames(frameLen, hop, 2); err != nil {
+		return nil, err
+	}
+	// The pitch class of each bin, -1 for bins below chromaMinFreq
+	class := make([]int, frameLen/2+1)
+	for k := range class {
+		class[k] = -1
+		if f := float64(k) * sampleRate / float64(frameLen); f >= chromaMinFreq {
+			p := int(math.Round(semitones(f) - tuning))
+			class[k] = ((p+9)%12 + 12) % 12
+		}
+	}
+	S := PowerSpectrogram(x, frameLen, hop, hann)
+	C := make([][]float64, len(S))
+	for i, frame := range S {
+		C[i] = make([]float64, 12)
+		for k, p := range frame {
+			if class[k] >= 0 {
+				C[i][class[k]] += p
+			}
+		}
+		if max := Max(C[i]); max > 0 {
+			DivSTo(C[i], C[i], max)
+		}
+	}
+	return C, nil
+}
+
+/*
+EstimateTuning returns the deviation of the tuning of x from A = 440 Hz in
+semitones in [-0.5, 0.5). The frequencies of the spectral peaks of the Hann
+windowed frames of x are interpolated and the deviations of the peaks from the
+nearest equal tempered semitone are collected in a histogram weighted by the
+peak magnitudes. The centre of the largest bin of the histogram is returned.
+The function panics if frameLen < 2 or hop < 1.
+*/
+func EstimateTuning(x []float64, sampleRate float64, frameLen, hop int) float64 {
+	const numBins = 100
+	hist := make([]float64, numBins)
+	for _, frame := range Spectrogram(x, frameLen, hop, hann) {
+		max := Max(frame)
+		for k := 1; k < len(frame)-1; k++ {
+			if frame[k] <= 0.1*max || frame[k] <= frame[k-1] || frame[k] < frame[k+1] {
+				continue
+			}
+			// Parabolic interpolation of the peak
+			a, b, c := frame[k-1], frame[k], frame[k+1]
+			offset := 0.5 * (a - c) / (a - 2*b + c)
+			f := (float64(k) + offset) * sampleRate / float64(frameLen)
+			if f < chromaMinFreq {
+				continue
+			}
+			s := semitones(f)
+			d := s - math.Round(s)
+			hist[int((d+0.5)*numBins)%numBins] += b
+		}
+	}
+	_, i := FindMax(hist)
+	return (float64(i)+0.5)/numBins - 0.5
+}
+
+// semitones returns the number of semitones of f Hz above A = 440 Hz
+func semitones(f float64) float64 {
+	return 12 * math.Log2(f/440)
+}
+
+// hann returns the periodic Hann window of length N
+func hann(N int) []float64 {
+	w := make([]float64, N)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(N))
+	}
+	return w
+}
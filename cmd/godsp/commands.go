@@ -0,0 +1,276 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/beat"
+	"github.com/goccmack/godsp/dwt"
+	"github.com/goccmack/godsp/filter"
+	"github.com/goccmack/godsp/peaks"
+	"github.com/goccmack/godsp/plot"
+	"github.com/goccmack/godsp/ppeaks"
+	"github.com/goccmack/godsp/windows"
+)
+
+type audioInfo struct {
+	File          string          `json:"file"`
+	Channels      int             `json:"channels"`
+	SampleRate    int             `json:"sampleRate"`
+	BitsPerSample int             `json:"bitsPerSample"`
+	Samples       int             `json:"samples"`
+	Duration      float64         `json:"duration"`
+	Metadata      *godsp.Metadata `json:"metadata,omitempty"`
+}
+
+func runInfo(args []string, stdout io.Writer) error {
+	in := newInput("info")
+	fname, err := in.parse(args)
+	if err != nil {
+		return err
+	}
+	channels, sampleRate, bits, err := godsp.ReadAudioFileErr(fname)
+	if err != nil {
+		return err
+	}
+	info := &audioInfo{
+		File:          fname,
+		Channels:      len(channels),
+		SampleRate:    sampleRate,
+		BitsPerSample: bits,
+	}
+	if len(channels) > 0 {
+		info.Samples = len(channels[0])
+		info.Duration = float64(info.Samples) / float64(sampleRate)
+	}
+	if strings.ToLower(filepath.Ext(fname)) == ".wav" {
+		if info.Metadata, err = godsp.ReadWavMetadata(fname); err != nil {
+			return err
+		}
+	}
+	return in.output(stdout, func(w io.Writer) error { return writeJSON(w, info) })
+}
+
+func runDWT(args []string, stdout io.Writer) error {
+	in := newInput("dwt")
+	level := in.Int("level", 5, "number of levels of the transform")
+	wavelet := in.String("wavelet", "d4", "wavelet: haar, d4, dbN, symN or coifN")
+	summary := in.Bool("summary", false, "write the summary of the levels as JSON")
+	fname, err := in.parse(args)
+	if err != nil {
+		return err
+	}
+	w, err := parseWavelet(*wavelet)
+	if err != nil {
+		return err
+	}
+	x, _, err := in.readSignal(fname)
+	if err != nil {
+		return err
+	}
+	t := dwt.NewTransform(x, w, *level, dwt.WithPadding(dwt.SymmetricPadding))
+	if *summary {
+		return in.output(stdout, func(w io.Writer) error { return writeJSON(w, t.Summary()) })
+	}
+	// One column per level, downsampled to the length of the deepest level
+	cfs := t.GetDownSampledCoefficients()
+	header := make([]string, len(cfs))
+	for l := range cfs {
+		header[l] = fmt.Sprintf("level%d", l+1)
+	}
+	return in.output(stdout, func(w io.Writer) error {
+		return godsp.WriteFloatMatrixCSVTo(w, header, transpose(cfs), godsp.CSVOptions{})
+	})
+}
+
+func parseWavelet(name string) (dwt.Wavelet, error) {
+	switch name {
+	case "haar":
+		return dwt.HaarWavelet, nil
+	case "d4":
+		return dwt.D4, nil
+	}
+	for prefix, f := range map[string]func(int) *dwt.Filter{
+		"db": dwt.Daubechies, "sym": dwt.Symlet, "coif": dwt.Coiflet,
+	} {
+		if strings.HasPrefix(name, prefix) {
+			if n, err := strconv.Atoi(name[len(prefix):]); err == nil {
+				return f(n), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown wavelet %q", name)
+}
+
+type peak struct {
+	Index int     `json:"index"`
+	Time  float64 `json:"time"`
+	Value float64 `json:"value"`
+}
+
+func runPeaks(args []string, stdout io.Writer) error {
+	in := newInput("peaks")
+	sep := in.Int("sep", 1, "minimum separation of the peaks in samples")
+	persistence := in.Float64("persistence", 0, "select the peaks by persistence above this fraction of the maximum persistence instead of by separation")
+	abs := in.Bool("abs", false, "find the peaks of the absolute value of the signal")
+	format := in.String("format", "csv", "output format: csv or json")
+	fname, err := in.parse(args)
+	if err != nil {
+		return err
+	}
+	x, sampleRate, err := in.readSignal(fname)
+	if err != nil {
+		return err
+	}
+	if *abs {
+		x = godsp.Abs(x)
+	}
+	var idx []int
+	if *persistence > 0 {
+		idx = ppeaks.GetPeaks(x).GetIndices(*persistence)
+	} else {
+		idx = peaks.Get(x, *sep)
+	}
+	pks := make([]peak, len(idx))
+	for i, j := range idx {
+		pks[i] = peak{Index: j, Time: float64(j) / float64(sampleRate), Value: x[j]}
+	}
+	return in.output(stdout, func(w io.Writer) error {
+		switch *format {
+		case "json":
+			return writeJSON(w, pks)
+		case "csv":
+			X := make([][]float64, len(pks))
+			for i, p := range pks {
+				X[i] = []float64{float64(p.Index), p.Time, p.Value}
+			}
+			return godsp.WriteFloatMatrixCSVTo(w, []string{"index", "time", "value"}, X, godsp.CSVOptions{})
+		}
+		return fmt.Errorf("unknown format %q", *format)
+	})
+}
+
+type beats struct {
+	BPM   float64   `json:"bpm"`
+	Beats []float64 `json:"beats"`
+}
+
+func runBeats(args []string, stdout io.Writer) error {
+	in := newInput("beats")
+	minBPM := in.Float64("min", beat.DefaultMinBPM, "lowest tempo in BPM")
+	maxBPM := in.Float64("max", beat.DefaultMaxBPM, "highest tempo in BPM")
+	format := in.String("format", "json", "output format: csv of the beat times or json")
+	fname, err := in.parse(args)
+	if err != nil {
+		return err
+	}
+	channels, sampleRate, err := in.readChannels(fname)
+	if err != nil {
+		return err
+	}
+	d := beat.NewBeatDetector(beat.WithBPMRange(*minBPM, *maxBPM))
+	b := &beats{}
+	b.Beats, b.BPM = d.Process(channels, sampleRate)
+	return in.output(stdout, func(w io.Writer) error {
+		switch *format {
+		case "json":
+			return writeJSON(w, b)
+		case "csv":
+			return godsp.WriteSeriesTo(w, map[string][]float64{"beat": b.Beats}, 0)
+		}
+		return fmt.Errorf("unknown format %q", *format)
+	})
+}
+
+func runSpectrogram(args []string, stdout io.Writer) error {
+	in := newInput("spectrogram")
+	frameLen := in.Int("frame", 1024, "frame length in samples")
+	hop := in.Int("hop", 256, "hop between frames in samples")
+	png := in.Bool("png", false, "write a PNG image instead of CSV; implied by -o with extension .png")
+	width := in.Int("width", 800, "width of the PNG image")
+	height := in.Int("height", 400, "height of the PNG image")
+	fname, err := in.parse(args)
+	if err != nil {
+		return err
+	}
+	x, _, err := in.readSignal(fname)
+	if err != nil {
+		return err
+	}
+	S, err := spectrogram(x, *frameLen, *hop)
+	if err != nil {
+		return err
+	}
+	if *png || strings.ToLower(filepath.Ext(*in.out)) == ".png" {
+		img := plot.Spectrogram(S, *width, *height)
+		return in.output(stdout, func(w io.Writer) error { return plot.WritePNG(w, img) })
+	}
+	return in.output(stdout, func(w io.Writer) error {
+		return godsp.WriteFloatMatrixCSVTo(w, nil, S, godsp.CSVOptions{})
+	})
+}
+
+// spectrogram returns the magnitude spectrogram of x with a Hann window
+func spectrogram(x []float64, frameLen, hop int) ([][]float64, error) {
+	if _, err := godsp.STFTErr(nil, frameLen, hop, nil); err != nil {
+		return nil, err
+	}
+	return godsp.Spectrogram(x, frameLen, hop, windows.Hann), nil
+}
+
+func runResample(args []string, stdout io.Writer) error {
+	in := newInput("resample")
+	target := in.Int("to", 0, "target sample rate in Hz")
+	fname, err := in.parse(args)
+	if err != nil {
+		return err
+	}
+	if *target < 1 {
+		return fmt.Errorf("resample: invalid target rate %d", *target)
+	}
+	channels, sampleRate, err := in.readChannels(fname)
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(channels))
+	for c := range channels {
+		channels[c] = filter.Resample(channels[c], sampleRate, *target)
+		header[c] = fmt.Sprintf("channel%d", c+1)
+	}
+	return in.output(stdout, func(w io.Writer) error {
+		return godsp.WriteFloatMatrixCSVTo(w, header, transpose(channels), godsp.CSVOptions{})
+	})
+}
+
+// transpose returns the rows of the columns cols, which have equal lengths
+func transpose(cols [][]float64) [][]float64 {
+	if len(cols) == 0 {
+		return nil
+	}
+	X := make([][]float64, len(cols[0]))
+	for i := range X {
+		X[i] = make([]float64, len(cols))
+		for j, col := range cols {
+			X[i][j] = col[i]
+		}
+	}
+	return X
+}
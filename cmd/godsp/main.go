@@ -0,0 +1,231 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Command godsp exposes the analyses of package godsp on the command line.
+
+Usage:
+
+	godsp <command> [flags] <input>
+
+The commands are:
+
+	info         print the format of an audio file as JSON
+	dwt          DWT coefficients as CSV or their summary as JSON
+	peaks        peaks of a signal as CSV or JSON
+	beats        beats and tempo of an audio file as CSV or JSON
+	spectrogram  magnitude spectrogram as CSV or PNG
+	resample     resample the channels of an audio file to CSV
+
+The input is an audio file (wav or AIFF) or a CSV file with extension .csv.
+The signal of a CSV file is the column selected by the flag -col or the first
+column that is not named index or time, as written by godsp.WriteSeries. The
+channels of an audio file are averaged by the commands that analyse a single
+signal. Output is written to standard output unless a file is given by the
+flag -o.
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccmack/godsp"
+)
+
+type command struct {
+	name, summary string
+	run           func(args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{"info", "print the format of an audio file as JSON", runInfo},
+	{"dwt", "DWT coefficients as CSV or their summary as JSON", runDWT},
+	{"peaks", "peaks of a signal as CSV or JSON", runPeaks},
+	{"beats", "beats and tempo of an audio file as CSV or JSON", runBeats},
+	{"spectrogram", "magnitude spectrogram as CSV or PNG", runSpectrogram},
+	{"resample", "resample the channels of an audio file to CSV", runResample},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "godsp:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command of args and writes its output to stdout
+func run(args []string, stdout io.Writer) (err error) {
+	if len(args) == 0 {
+		return fmt.Errorf("no command\n%s", usage())
+	}
+	// The functions of godsp panic on invalid input
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %v", args[0], r)
+		}
+	}()
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout)
+		}
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage())
+}
+
+func usage() string {
+	w := new(strings.Builder)
+	fmt.Fprintln(w, "usage: godsp <command> [flags] <input>")
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	return w.String()
+}
+
+/*
+input contains the flags shared by the commands and parses the arguments of a
+command.
+*/
+type input struct {
+	*flag.FlagSet
+	out  *string
+	rate *int
+	col  *string
+}
+
+func newInput(name string) *input {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &input{
+		FlagSet: fs,
+		out:     fs.String("o", "", "output file (default standard output)"),
+		rate:    fs.Int("rate", 1, "sample rate of CSV input in Hz"),
+		col:     fs.String("col", "", "name or number, from 0, of the column of CSV input"),
+	}
+}
+
+// parse parses args and returns the input file name
+func (in *input) parse(args []string) (string, error) {
+	if err := in.Parse(args); err != nil {
+		return "", err
+	}
+	if in.NArg() != 1 {
+		return "", fmt.Errorf("%s: expected 1 input file, got %d", in.Name(), in.NArg())
+	}
+	return in.Arg(0), nil
+}
+
+/*
+readChannels returns the channels and sample rate of the audio or CSV file
+fname. A CSV file has a single channel, selected by the flag -col, and the
+sample rate of the flag -rate.
+*/
+func (in *input) readChannels(fname string) (channels [][]float64, sampleRate int, err error) {
+	if strings.ToLower(filepath.Ext(fname)) != ".csv" {
+		channels, sampleRate, _, err = godsp.ReadAudioFileErr(fname)
+		return
+	}
+	header, X, err := godsp.ReadFloatMatrixCSVErr(fname, godsp.CSVOptions{Header: hasHeader(fname)})
+	if err != nil {
+		return nil, 0, err
+	}
+	col, err := in.column(header)
+	if err != nil {
+		return nil, 0, err
+	}
+	x := make([]float64, len(X))
+	for i, row := range X {
+		if col >= len(row) {
+			return nil, 0, fmt.Errorf("%s: line %d has no column %d", fname, i+1, col)
+		}
+		x[i] = row[col]
+	}
+	return [][]float64{x}, *in.rate, nil
+}
+
+// column returns the index of the column of the flag -col in a CSV file with header
+func (in *input) column(header []string) (int, error) {
+	if *in.col != "" {
+		if col, err := strconv.Atoi(*in.col); err == nil {
+			if col < 0 {
+				return 0, fmt.Errorf("invalid column %d", col)
+			}
+			return col, nil
+		}
+		for i, name := range header {
+			if name == *in.col {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("no column %q", *in.col)
+	}
+	for i, name := range header {
+		if name != "index" && name != "time" {
+			return i, nil
+		}
+	}
+	return 0, nil
+}
+
+// readSignal returns the mono signal and sample rate of fname. See readChannels.
+func (in *input) readSignal(fname string) ([]float64, int, error) {
+	channels, sampleRate, err := in.readChannels(fname)
+	if err != nil {
+		return nil, 0, err
+	}
+	return godsp.ToMono(channels, nil), sampleRate, nil
+}
+
+// hasHeader returns true if the first field of the CSV file fname is not a number
+func hasHeader(fname string) bool {
+	f, err := os.Open(fname)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	field := strings.TrimSpace(strings.SplitN(line, ",", 2)[0])
+	_, err = strconv.ParseFloat(field, 64)
+	return field != "" && err != nil
+}
+
+// output calls write with the output file of the flag -o or stdout
+func (in *input) output(stdout io.Writer, write func(w io.Writer) error) error {
+	if *in.out == "" {
+		return write(stdout)
+	}
+	f, err := os.Create(*in.out)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccmack/godsp"
+)
+
+func TestRun(t *testing.T) {
+	x := make([]float64, 256)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * float64(i) / 64)
+	}
+	fname := filepath.Join(t.TempDir(), "x.csv")
+	godsp.WriteSeries(fname, map[string][]float64{"x": x}, 0)
+
+	out := new(bytes.Buffer)
+	if err := run([]string{"peaks", "-sep", "8", "-rate", "64", fname}, out); err != nil {
+		t.Fatal(err)
+	}
+	want := "index,time,value\n16,0.25,1\n80,1.25,1\n144,2.25,1\n208,3.25,1\n255,3.984375,-0.09801714032956124\n"
+	if out.String() != want {
+		t.Errorf("peaks:\n%s", out)
+	}
+
+	out.Reset()
+	if err := run([]string{"dwt", "-summary", "-level", "3", fname}, out); err != nil {
+		t.Fatal(err)
+	}
+	var summary struct{ N int }
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil || summary.N != len(x) {
+		t.Errorf("dwt summary %s, err %v", out, err)
+	}
+
+	png := filepath.Join(t.TempDir(), "s.png")
+	if err := run([]string{"spectrogram", "-frame", "32", "-hop", "16", "-o", png, fname}, out); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(png); err != nil || !bytes.HasPrefix(b, []byte("\x89PNG")) {
+		t.Errorf("spectrogram PNG: err %v", err)
+	}
+
+	if err := run([]string{"spectrogram", "-hop", "0", fname}, out); err == nil {
+		t.Error("spectrogram with hop 0: no error")
+	}
+	if err := run([]string{"peaks", "-col", "-1", fname}, out); err == nil || !strings.Contains(err.Error(), "invalid column -1") {
+		t.Errorf("peaks with column -1: err %v", err)
+	}
+	if err := run([]string{"peaks", "-col", "5", fname}, out); err == nil || !strings.Contains(err.Error(), "has no column 5") {
+		t.Errorf("peaks with column 5: err %v", err)
+	}
+	if err := run([]string{"transform", fname}, out); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("unknown command: err %v", err)
+	}
+}
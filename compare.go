@@ -0,0 +1,87 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"math"
+)
+
+/*
+EqualApprox reports whether len(x) == len(y) and |x[i]-y[i]| <= tol for all i.
+NaN is not approximately equal to any value.
+*/
+func EqualApprox[T Float](x, y []T, tol float64) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !(math.Abs(float64(x[i])-float64(y[i])) <= tol) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+MaxAbsError returns the maximum of |x[i]-y[i]|, or 0 if x is empty.
+The function panics if len(x) != len(y).
+*/
+func MaxAbsError[T Float](x, y []T) float64 {
+	if err := checkSameLen(x, y); err != nil {
+		panic(err)
+	}
+	max := 0.0
+	for i := range x {
+		max = math.Max(max, math.Abs(float64(x[i])-float64(y[i])))
+	}
+	return max
+}
+
+/*
+RMSE returns the root mean square of x-y, or 0 if x is empty.
+The function panics if len(x) != len(y).
+*/
+func RMSE[T Float](x, y []T) float64 {
+	if err := checkSameLen(x, y); err != nil {
+		panic(err)
+	}
+	if len(x) == 0 {
+		return 0
+	}
+	return math.Sqrt(squaredError(x, y) / float64(len(x)))
+}
+
+/*
+SNR returns the signal to noise ratio in dB of estimate, an approximation of
+signal: 10*log10(sum(signal^2)/sum((signal-estimate)^2)). It is +Inf if
+estimate is equal to signal.
+The function panics if len(signal) != len(estimate).
+*/
+func SNR[T Float](signal, estimate []T) float64 {
+	if err := checkSameLen(signal, estimate); err != nil {
+		panic(err)
+	}
+	return 10 * math.Log10(float64(Dot(signal, signal))/squaredError(signal, estimate))
+}
+
+// squaredError returns the sum of (x[i]-y[i])^2
+func squaredError[T Float](x, y []T) float64 {
+	sum := 0.0
+	for i := range x {
+		d := float64(x[i]) - float64(y[i])
+		sum += d * d
+	}
+	return sum
+}
@@ -0,0 +1,214 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+/*
+convDirectMaxLen is the length of the shorter vector up to which Conv computes
+the convolution directly.
+*/
+const convDirectMaxLen = 64
+
+/*
+Conv returns the linear convolution of x and h, which has length
+len(x)+len(h)-1:
+
+	y[n] = sum_k h[k] x[n-k]
+
+Short filters are convolved directly. Long filters are convolved by FFT
+overlap-add.
+*/
+func Conv(x, h []float64) []float64 {
+	if len(x) == 0 || len(h) == 0 {
+		return []float64{}
+	}
+	if len(h) > len(x) {
+		x, h = h, x
+	}
+	if len(h) <= convDirectMaxLen {
+		return convDirect(x, h)
+	}
+	return convOverlapAdd(x, h)
+}
+
+/*
+ConvCircular returns the circular convolution of x and h, which has length
+len(x):
+
+	y[n] = sum_k h[k] x[(n-k) mod len(x)]
+
+Elements of h beyond len(x) wrap around.
+*/
+func ConvCircular(x, h []float64) []float64 {
+	N := len(x)
+	if N == 0 {
+		return []float64{}
+	}
+	hw := make([]float64, N)
+	for k, f := range h {
+		hw[k%N] += f
+	}
+	X, H := FFTReal(x), FFTReal(hw)
+	for i := range X {
+		X[i] *= H[i]
+	}
+	return IFFTReal(X)
+}
+
+func convDirect(x, h []float64) []float64 {
+	y := make([]float64, len(x)+len(h)-1)
+	for i, xi := range x {
+		for k, hk := range h {
+			y[i+k] += xi * hk
+		}
+	}
+	return y
+}
+
+/*
+convOverlapAdd convolves x with h by transforming blocks of x with FFTs of
+length M >= 2 len(h) and adding the overlapping tails of the block
+convolutions.
+*/
+func convOverlapAdd(x, h []float64) []float64 {
+	M := NextPow2(2 * len(h))
+	L := M - len(h) + 1
+	H := make([]complex128, M)
+	for k, f := range h {
+		H[k] = complex(f, 0)
+	}
+	radix2(H, false)
+	y := make([]float64, len(x)+len(h)-1)
+	X := make([]complex128, M)
+	for start := 0; start < len(x); start += L {
+		end := start + L
+		if end > len(x) {
+			end = len(x)
+		}
+		for i := range X {
+			X[i] = 0
+		}
+		for i := start; i < end; i++ {
+			X[i-start] = complex(x[i], 0)
+		}
+		radix2(X, false)
+		for i := range X {
+			X[i] *= H[i]
+		}
+		radix2(X, true)
+		for i := 0; i < M && start+i < len(y); i++ {
+			y[start+i] += real(X[i]) / float64(M)
+		}
+	}
+	return y
+}
+
+/*
+Convolver convolves a stream of blocks of arbitrary length with an impulse
+response by FFT overlap-add. The output of each block is available without
+latency: the concatenated outputs of Process are the first samples of
+Conv(x, h) of the concatenated inputs x. A Convolver is not safe for concurrent
+use; use one Convolver per channel.
+*/
+type Convolver struct {
+	h    []float64
+	hf   []complex128 // FFT of h
+	xf   []complex128 // FFT work space
+	tail []float64
+}
+
+/*
+NewConvolver returns a Convolver with impulse response h.
+The function panics if h is empty.
+*/
+func NewConvolver(h []float64) *Convolver {
+	if len(h) == 0 {
+		panic("Empty impulse response")
+	}
+	M := NextPow2(2 * len(h))
+	H := make([]complex128, M)
+	for k, f := range h {
+		H[k] = complex(f, 0)
+	}
+	radix2(H, false)
+	return &Convolver{
+		h:    append([]float64{}, h...),
+		hf:   H,
+		xf:   make([]complex128, M),
+		tail: make([]float64, M),
+	}
+}
+
+/*
+Process returns the next len(x) samples of the convolution of the stream with
+the impulse response.
+*/
+func (c *Convolver) Process(x []float64) []float64 {
+	M := len(c.hf)
+	L := M - len(c.h) + 1
+	y := make([]float64, 0, len(x))
+	for start := 0; start < len(x); start += L {
+		end := start + L
+		if end > len(x) {
+			end = len(x)
+		}
+		n := end - start
+		if n <= convDirectMaxLen {
+			// Short blocks are convolved directly
+			for i, xi := range x[start:end] {
+				for k, hk := range c.h {
+					c.tail[i+k] += xi * hk
+				}
+			}
+		} else {
+			for i := range c.xf {
+				c.xf[i] = 0
+			}
+			for i, f := range x[start:end] {
+				c.xf[i] = complex(f, 0)
+			}
+			radix2(c.xf, false)
+			for i := range c.xf {
+				c.xf[i] *= c.hf[i]
+			}
+			radix2(c.xf, true)
+			for i := 0; i < n+len(c.h)-1; i++ {
+				c.tail[i] += real(c.xf[i]) / float64(M)
+			}
+		}
+		y = append(y, c.tail[:n]...)
+		copy(c.tail, c.tail[n:])
+		for i := M - n; i < M; i++ {
+			c.tail[i] = 0
+		}
+	}
+	return y
+}
+
+/*
+Flush returns the remaining len(h)-1 samples of the convolution of the stream
+and resets the Convolver.
+*/
+func (c *Convolver) Flush() []float64 {
+	y := append([]float64{}, c.tail[:len(c.h)-1]...)
+	c.Reset()
+	return y
+}
+
+// Reset clears the state of the Convolver
+func (c *Convolver) Reset() {
+	for i := range c.tail {
+		c.tail[i] = 0
+	}
+}
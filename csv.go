@@ -0,0 +1,281 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+/*
+CSVOptions configures ReadFloatMatrixCSV and WriteFloatMatrixCSV.
+The zero value reads and writes comma separated files without a header row.
+*/
+type CSVOptions struct {
+	// Delimiter separates the fields of a row. The zero value is ','.
+	Delimiter rune
+	// Header is true if the first row of the file contains column names.
+	// WriteFloatMatrixCSV writes a header row if header is not nil.
+	Header bool
+}
+
+func (o CSVOptions) delimiter() rune {
+	if o.Delimiter == 0 {
+		return ','
+	}
+	return o.Delimiter
+}
+
+/*
+ReadFloatMatrixCSV returns the column names and rows of the float matrix in the
+CSV file fname. header is nil if opts.Header is false. Fields are trimmed of
+leading space.
+The function panics if the file cannot be read, a field is not a number or the
+rows have different numbers of fields.
+*/
+func ReadFloatMatrixCSV(fname string, opts CSVOptions) (header []string, X [][]float64) {
+	header, X, err := ReadFloatMatrixCSVErr(fname, opts)
+	if err != nil {
+		panic(err)
+	}
+	return header, X
+}
+
+/*
+ReadFloatMatrixCSVErr returns ReadFloatMatrixCSV(fname, opts) or the error
+reading or parsing the file.
+*/
+func ReadFloatMatrixCSVErr(fname string, opts CSVOptions) (header []string, X [][]float64, err error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return ReadFloatMatrixCSVFrom(f, opts)
+}
+
+/*
+ReadFloatMatrixCSVFrom returns the column names and rows of the float matrix in
+CSV format read from r, as by ReadFloatMatrixCSV, or the error reading or
+parsing r.
+*/
+func ReadFloatMatrixCSVFrom(r io.Reader, opts CSVOptions) (header []string, X [][]float64, err error) {
+	cr := csv.NewReader(r)
+	cr.Comma = opts.delimiter()
+	cr.TrimLeadingSpace = true
+	if opts.Header {
+		if header, err = cr.Read(); err != nil {
+			if err == io.EOF {
+				return nil, nil, fmt.Errorf("%w: missing CSV header", ErrLength)
+			}
+			return nil, nil, err
+		}
+		cr.FieldsPerRecord = len(header)
+	}
+	X = [][]float64{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return header, X, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		row := make([]float64, len(rec))
+		for i, s := range rec {
+			if row[i], err = strconv.ParseFloat(s, 64); err != nil {
+				line, _ := cr.FieldPos(i)
+				return nil, nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		X = append(X, row)
+	}
+}
+
+/*
+WriteFloatMatrixCSV writes the rows of X to the CSV file fname, preceded by the
+column names in header if header is not nil. Numbers are written in the
+shortest form that reads back to the same float64.
+The function panics if the rows of X and header have different lengths or on
+write errors.
+*/
+func WriteFloatMatrixCSV(fname string, header []string, X [][]float64, opts CSVOptions) {
+	if err := WriteFloatMatrixCSVErr(fname, header, X, opts); err != nil {
+		panic(err)
+	}
+}
+
+/*
+WriteFloatMatrixCSVErr is WriteFloatMatrixCSV returning the write error or an
+error wrapping ErrLength if the rows of X and header have different lengths.
+*/
+func WriteFloatMatrixCSVErr(fname string, header []string, X [][]float64, opts CSVOptions) error {
+	buf := new(bytes.Buffer)
+	if err := WriteFloatMatrixCSVTo(buf, header, X, opts); err != nil {
+		return err
+	}
+	return myioutil.WriteFile(fname, buf.Bytes())
+}
+
+/*
+WriteFloatMatrixCSVTo writes header and the rows of X to w in the format of
+WriteFloatMatrixCSV and returns the write error or an error wrapping ErrLength
+if the rows of X and header have different lengths.
+*/
+func WriteFloatMatrixCSVTo(w io.Writer, header []string, X [][]float64, opts CSVOptions) error {
+	cols := len(header)
+	if header == nil && len(X) > 0 {
+		cols = len(X[0])
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.delimiter()
+	if header != nil {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	rec := make([]string, cols)
+	for i, row := range X {
+		if len(row) != cols {
+			return fmt.Errorf("%w: len(X[%d]) (%d) != %d columns", ErrLength, i, len(row), cols)
+		}
+		for j, f := range row {
+			rec[j] = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+/*
+WriteSeries writes the vectors in series to the CSV file fname as columns
+labeled by their names in alphabetical order. The first column is the time in
+seconds of each row if sampleRate > 0, or the index of the row otherwise.
+Series of different lengths are padded with empty fields. NaN elements are
+written as empty fields, so sparse series such as peak markers, which are NaN
+except at the peaks, are drawn as points on the other series.
+The function panics on write errors.
+*/
+func WriteSeries(fname string, series map[string][]float64, sampleRate float64) {
+	if err := WriteSeriesErr(fname, series, sampleRate); err != nil {
+		panic(err)
+	}
+}
+
+// WriteSeriesErr is WriteSeries returning the write error.
+func WriteSeriesErr(fname string, series map[string][]float64, sampleRate float64) error {
+	buf := new(bytes.Buffer)
+	if err := WriteSeriesTo(buf, series, sampleRate); err != nil {
+		return err
+	}
+	return myioutil.WriteFile(fname, buf.Bytes())
+}
+
+// WriteSeriesTo writes series to w in the format of WriteSeries and returns the write error.
+func WriteSeriesTo(w io.Writer, series map[string][]float64, sampleRate float64) error {
+	names := seriesNames(series)
+	header := append([]string{"index"}, names...)
+	if sampleRate > 0 {
+		header[0] = "time"
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	rows := 0
+	for _, x := range series {
+		if len(x) > rows {
+			rows = len(x)
+		}
+	}
+	rec := make([]string, len(header))
+	for i := 0; i < rows; i++ {
+		if sampleRate > 0 {
+			rec[0] = strconv.FormatFloat(float64(i)/sampleRate, 'g', -1, 64)
+		} else {
+			rec[0] = strconv.Itoa(i)
+		}
+		for j, name := range names {
+			rec[j+1] = ""
+			if x := series[name]; i < len(x) && !math.IsNaN(x[i]) {
+				rec[j+1] = strconv.FormatFloat(x[i], 'g', -1, 64)
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+/*
+WriteSeriesGnuplot writes series to the CSV file fname by WriteSeries and a
+gnuplot script fname.gp that plots every series against the first column:
+
+	$ gnuplot -p fname.gp
+
+Series with names ending in "peaks" are drawn as points and all others as
+lines. The function panics on write errors.
+*/
+func WriteSeriesGnuplot(fname string, series map[string][]float64, sampleRate float64) {
+	WriteSeries(fname, series, sampleRate)
+	names := seriesNames(series)
+	buf := new(bytes.Buffer)
+	fmt.Fprintln(buf, "set datafile separator ','")
+	if sampleRate > 0 {
+		fmt.Fprintln(buf, "set xlabel 'time (s)'")
+	} else {
+		fmt.Fprintln(buf, "set xlabel 'index'")
+	}
+	fmt.Fprintln(buf, "set key outside")
+	fmt.Fprint(buf, "plot")
+	for i, name := range names {
+		style := "lines"
+		if strings.HasSuffix(name, "peaks") {
+			style = "points pt 7"
+		}
+		if i > 0 {
+			fmt.Fprint(buf, ",")
+		}
+		fmt.Fprintf(buf, " \\\n\t'%s' using 1:%d with %s title '%s'", filepath.Base(fname), i+2, style, name)
+	}
+	fmt.Fprintln(buf)
+	if err := myioutil.WriteFile(fname+".gp", buf.Bytes()); err != nil {
+		panic(err)
+	}
+}
+
+func seriesNames(series map[string][]float64) []string {
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,170 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package cwt computes the Continuous Wavelet Transform of a signal:
+
+	W(a,b) = 1/sqrt(a) sum_n x[n] psi*((n-b)/a)
+
+where a is the scale and b the translation in samples.
+The signal is extended with zeros at its boundaries.
+*/
+package cwt
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Wavelet is a mother wavelet of the CWT
+type Wavelet interface {
+	// Psi returns the value of the wavelet at t
+	Psi(t float64) complex128
+
+	// Support returns the half width of the wavelet at scale 1 outside which
+	// Psi is negligible.
+	Support() float64
+
+	// CenterFrequency returns the center frequency of the wavelet at scale 1
+	// in cycles per sample.
+	CenterFrequency() float64
+}
+
+/*
+Morlet is the complex Morlet wavelet
+psi(t) = pi^(-1/4) exp(i W0 t) exp(-t^2/2).
+*/
+type Morlet struct {
+	W0 float64
+}
+
+/*
+MexicanHat is the Mexican hat (Ricker) wavelet
+psi(t) = 2/(sqrt(3) pi^(1/4)) (1 - t^2) exp(-t^2/2).
+*/
+type MexicanHat struct{}
+
+// NewMorlet returns the Morlet wavelet with the commonly used W0 = 6.
+func NewMorlet() *Morlet {
+	return &Morlet{W0: 6}
+}
+
+// Psi implements Wavelet
+func (m *Morlet) Psi(t float64) complex128 {
+	return cmplx.Exp(complex(-t*t/2, m.W0*t)) * complex(math.Pow(math.Pi, -0.25), 0)
+}
+
+// Support implements Wavelet
+func (m *Morlet) Support() float64 {
+	return 4
+}
+
+// CenterFrequency implements Wavelet
+func (m *Morlet) CenterFrequency() float64 {
+	return m.W0 / (2 * math.Pi)
+}
+
+// Psi implements Wavelet
+func (MexicanHat) Psi(t float64) complex128 {
+	c := 2 / (math.Sqrt(3) * math.Pow(math.Pi, 0.25))
+	return complex(c*(1-t*t)*math.Exp(-t*t/2), 0)
+}
+
+// Support implements Wavelet
+func (MexicanHat) Support() float64 {
+	return 5
+}
+
+// CenterFrequency implements Wavelet
+func (MexicanHat) CenterFrequency() float64 {
+	return math.Sqrt(2) / (2 * math.Pi)
+}
+
+/*
+Scales returns n scales spaced geometrically from min to max.
+The function panics if min <= 0, max < min or n < 2.
+*/
+func Scales(min, max float64, n int) []float64 {
+	if min <= 0 || max < min || n < 2 {
+		panic(fmt.Sprintf("Invalid scales min=%f, max=%f, n=%d", min, max, n))
+	}
+	scales := make([]float64, n)
+	r := math.Pow(max/min, 1/float64(n-1))
+	for i := range scales {
+		scales[i] = min * math.Pow(r, float64(i))
+	}
+	return scales
+}
+
+/*
+ScaleToFrequency returns the frequency in Hz corresponding to scale for
+wavelet w and a signal with sampleRate.
+*/
+func ScaleToFrequency(w Wavelet, scale, sampleRate float64) float64 {
+	return w.CenterFrequency() * sampleRate / scale
+}
+
+/*
+Transform returns the CWT of x with wavelet w. Row i of the result contains the
+coefficients of scales[i] for every sample of x.
+*/
+func Transform(x []float64, w Wavelet, scales []float64) [][]complex128 {
+	W := make([][]complex128, len(scales))
+	for i, a := range scales {
+		W[i] = transformScale(x, w, a)
+	}
+	return W
+}
+
+/*
+Scalogram returns the magnitudes of the CWT of x with wavelet w.
+Row i of the result contains the magnitudes at scales[i].
+*/
+func Scalogram(x []float64, w Wavelet, scales []float64) [][]float64 {
+	S := make([][]float64, len(scales))
+	for i, a := range scales {
+		W := transformScale(x, w, a)
+		S[i] = make([]float64, len(W))
+		for j, c := range W {
+			S[i][j] = cmplx.Abs(c)
+		}
+	}
+	return S
+}
+
+func transformScale(x []float64, w Wavelet, a float64) []complex128 {
+	half := int(math.Ceil(w.Support() * a))
+	// psi[half+k] = psi*(k/a)/sqrt(a)
+	psi := make([]complex128, 2*half+1)
+	for k := -half; k <= half; k++ {
+		psi[half+k] = cmplx.Conj(w.Psi(float64(k)/a)) / complex(math.Sqrt(a), 0)
+	}
+	W := make([]complex128, len(x))
+	for b := range x {
+		from, to := b-half, b+half
+		if from < 0 {
+			from = 0
+		}
+		if to > len(x)-1 {
+			to = len(x) - 1
+		}
+		var sum complex128
+		for n := from; n <= to; n++ {
+			sum += complex(x[n], 0) * psi[half+n-b]
+		}
+		W[b] = sum
+	}
+	return W
+}
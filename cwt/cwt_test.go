@@ -0,0 +1,26 @@
+package cwt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScalogram(t *testing.T) {
+	sampleRate, freq := 1000.0, 50.0
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * freq * float64(i) / sampleRate)
+	}
+	w := NewMorlet()
+	scales := Scales(2, 100, 50)
+	S := Scalogram(x, w, scales)
+	best, max := 0, 0.0
+	for i := range S {
+		if S[i][500] > max {
+			best, max = i, S[i][500]
+		}
+	}
+	if f := ScaleToFrequency(w, scales[best], sampleRate); math.Abs(f-freq) > 5 {
+		t.Errorf("peak frequency = %f, want %f", f, freq)
+	}
+}
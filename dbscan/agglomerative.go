@@ -0,0 +1,93 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dbscan
+
+import (
+	"math"
+)
+
+// Linkage selects the distance between clusters of Agglomerative
+type Linkage int
+
+const (
+	// Single linkage is the distance between the closest bins of two clusters
+	Single Linkage = iota
+	// Complete linkage is the distance between the furthest bins of two clusters
+	Complete
+	/*
+		Average linkage is the average distance between the counts of two
+		clusters: every bin contributes h[bin] times.
+	*/
+	Average
+)
+
+/*
+Agglomerative clusters the non-empty bins of a histogram `h` by hierarchical
+agglomerative clustering. Starting with a cluster per non-empty bin, the two
+closest clusters by linkage are merged until the closest clusters are further
+than cutoff bins apart. Unlike Histogram, no tuning of density parameters is
+required and every non-empty bin is in a cluster. The clusters are returned in
+increasing order of Min.
+*/
+func Agglomerative(h []int, cutoff float64, linkage Linkage) []*Cluster {
+	nodes := []*aggNode{}
+	for i, n := range h {
+		if n > 0 {
+			nodes = append(nodes, &aggNode{min: i, max: i, weight: n, sum: i * n})
+		}
+	}
+	// The clusters are disjoint intervals, so the closest clusters by any
+	// linkage are adjacent
+	for len(nodes) > 1 {
+		best, bestD := -1, math.Inf(1)
+		for i := 0; i < len(nodes)-1; i++ {
+			if d := linkageDistance(nodes[i], nodes[i+1], linkage); d < bestD {
+				best, bestD = i, d
+			}
+		}
+		if bestD > cutoff {
+			break
+		}
+		a, b := nodes[best], nodes[best+1]
+		a.max, a.weight, a.sum = b.max, a.weight+b.weight, a.sum+b.sum
+		nodes = append(nodes[:best+1], nodes[best+2:]...)
+	}
+	clusters := make([]*Cluster, len(nodes))
+	for i, nd := range nodes {
+		clusters[i] = &Cluster{Min: nd.min, Max: nd.max}
+		clusters[i].setStats(h)
+	}
+	return clusters
+}
+
+/*
+aggNode is a cluster of Agglomerative with the weight and the sum of the bins
+weighted by their counts, which are updated on every merge.
+*/
+type aggNode struct {
+	min, max, weight, sum int
+}
+
+// linkageDistance returns the distance between the clusters a and b, where a precedes b
+func linkageDistance(a, b *aggNode, linkage Linkage) float64 {
+	switch linkage {
+	case Single:
+		return float64(b.min - a.max)
+	case Complete:
+		return float64(b.max - a.min)
+	}
+	// The average distance is the difference of the centroids, since every bin of b follows every bin of a
+	return float64(b.sum)/float64(b.weight) - float64(a.sum)/float64(a.weight)
+}
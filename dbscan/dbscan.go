@@ -20,26 +20,116 @@ package dbscan
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"sort"
 
-	"github.com/goccmack/goutil/ioutil"
+	myioutil "github.com/goccmack/goutil/ioutil"
 )
 
 const (
-	noise     = -1
 	undefined = 0
 )
 
+/*
+Cluster is the range of bins [Min,Max] of a cluster of a histogram with the
+statistics of its non-empty bins. A cluster of HistogramCircular that wraps
+around the end of the histogram has Min > Max and contains the bins
+[Min,len(h)) and [0,Max].
+It is encoded to JSON as {"min": Min, "max": Max, "count": Count, ...}.
+*/
 type Cluster struct {
-	Min, Max int
+	Min int `json:"min"`
+	Max int `json:"max"`
+	// Count is the number of non-empty bins in the cluster
+	Count int `json:"count"`
+	// Weight is the sum of the counts of the bins in the cluster
+	Weight int `json:"weight"`
+	/*
+		Centroid is the mean of the bins weighted by their counts. The centroid
+		of a cluster that wraps around is taken modulo len(h).
+	*/
+	Centroid float64 `json:"centroid"`
+}
+
+// setStats sets the statistics of c from the bins of h in [c.Min,c.Max]
+func (c *Cluster) setStats(h []int) {
+	c.Count, c.Weight, c.Centroid = 0, 0, 0
+	max := c.Max
+	if max < c.Min {
+		max += len(h)
+	}
+	sum := 0
+	for i := c.Min; i <= max; i++ {
+		if n := h[i%len(h)]; n > 0 {
+			c.Count++
+			c.Weight += n
+			sum += i * n
+		}
+	}
+	if c.Weight > 0 {
+		c.Centroid = math.Mod(float64(sum)/float64(c.Weight), float64(len(h)))
+	}
 }
 
 /*
 Histogram clusters the bins of a histogram `h`.
 */
 func Histogram(h []int, eps, minPts int) []*Cluster {
+	return getClusters(h, histogram(h, eps, minPts, false), false)
+}
+
+/*
+HistogramCircular clusters the bins of a histogram `h` of a periodic quantity,
+such as a phase or an angle, like Histogram, but the first and last bins of h
+are neighbours. A cluster that wraps around the end of h has Min > Max.
+*/
+func HistogramCircular(h []int, eps, minPts int) []*Cluster {
+	return getClusters(h, histogram(h, eps, minPts, true), true)
+}
+
+/*
+HistogramLabels clusters the bins of a histogram `h` like Histogram and
+also returns the label of every bin: the index of its cluster in clusters or
+Noise. Empty bins are labelled Noise.
+*/
+func HistogramLabels(h []int, eps, minPts int) (labels []int, clusters []*Cluster) {
+	return histogramLabels(h, eps, minPts, false)
+}
+
+/*
+HistogramLabelsCircular clusters the bins of a histogram `h` like
+HistogramCircular and also returns the label of every bin like
+HistogramLabels.
+*/
+func HistogramLabelsCircular(h []int, eps, minPts int) (labels []int, clusters []*Cluster) {
+	return histogramLabels(h, eps, minPts, true)
+}
+
+func histogramLabels(h []int, eps, minPts int, circular bool) (labels []int, clusters []*Cluster) {
+	labels = histogram(h, eps, minPts, circular)
+	clusters = getClusters(h, labels, circular)
+	ids := make(map[int]int, len(clusters))
+	for i, c := range clusters {
+		ids[labels[c.Min]] = i
+	}
+	for i, l := range labels {
+		if l > 0 {
+			labels[i] = ids[l]
+		} else {
+			labels[i] = Noise
+		}
+	}
+	return
+}
+
+// histogram returns the cluster 1, 2, ... of each bin of h, Noise or undefined for empty bins
+func histogram(h []int, eps, minPts int, circular bool) []int {
 	clusters := make([]int, len(h))
+	idx := newBinIndex(h, eps, circular)
 	C := 0 /* Cluster counter */
 	for p := range h {
 		if h[p] <= 0 {
@@ -48,33 +138,111 @@ func Histogram(h []int, eps, minPts int) []*Cluster {
 		if clusters[p] != undefined { /* Previously processed in inner loop */
 			continue
 		}
-		N, S := getNeighbours(h, p, eps) /* Find neighbors */
-		if len(N) < minPts {             /* Density check */
-			clusters[p] = noise /* Label as noise */
+		if idx.count(p) < minPts { /* Density check */
+			clusters[p] = Noise /* Label as noise */
 			continue
 		}
-		C = C + 1             /* next cluster label */
-		clusters[p] = C       /* Label initial point */
-		for _, q := range S { /* Process every seed point */
-			if clusters[q] == noise { /* Change noise to border point */
+		C = C + 1       /* next cluster label */
+		clusters[p] = C /* Label initial point */
+		S := idx.neighbours(p, clusters, nil)
+		for k := 0; k < len(S); k++ { /* Process every seed point */
+			q := S[k]
+			if clusters[q] == Noise { /* Change noise to border point */
 				clusters[q] = C
 			}
 			if clusters[q] != undefined { /* Previously processed */
 				continue
 			}
-			clusters[q] = C                  /* Label neighbor */
-			N, _ := getNeighbours(h, q, eps) /* Find neighbors */
-			if len(N) >= minPts {            /* Density check */
-				for _, n := range N { /* Add new neighbors to seed set */
-					S = append(S, n)
-				}
+			clusters[q] = C             /* Label neighbor */
+			if idx.count(q) >= minPts { /* Density check */
+				S = idx.neighbours(q, clusters, S) /* Add new neighbors to seed set */
 			}
 		}
 	}
-	return getClusters(clusters)
+	return clusters
+}
+
+/*
+binIndex counts the non-empty bins of the neighbourhood [point-eps, point+eps)
+of a bin in O(1) by the prefix counts nz, where nz[i] is the number of
+non-empty bins in h[:i]. If circular is true the neighbourhood wraps around the
+ends of h.
+*/
+type binIndex struct {
+	h        []int
+	nz       []int
+	eps      int
+	circular bool
+}
+
+func newBinIndex(h []int, eps int, circular bool) *binIndex {
+	nz := make([]int, len(h)+1)
+	for i, n := range h {
+		nz[i+1] = nz[i]
+		if n > 0 {
+			nz[i+1]++
+		}
+	}
+	return &binIndex{h: h, nz: nz, eps: eps, circular: circular}
+}
+
+/*
+window returns the neighbourhood [from,to) of point. If idx is circular from
+may be negative and to may exceed len(h): the bins of the neighbourhood are
+taken modulo len(h).
+*/
+func (idx *binIndex) window(point int) (from, to int) {
+	from, to = point-idx.eps, point+idx.eps
+	if idx.circular {
+		if to-from >= len(idx.h) {
+			from, to = 0, len(idx.h)
+		}
+		if to < from {
+			to = from
+		}
+		return
+	}
+	if from < 0 {
+		from = 0
+	}
+	if to > len(idx.h) {
+		to = len(idx.h)
+	}
+	if to < from {
+		to = from
+	}
+	return
 }
 
-func getClusters(cs []int) (clusters []*Cluster) {
+// count returns the number of non-empty bins in the neighbourhood of point, including point
+func (idx *binIndex) count(point int) int {
+	from, to := idx.window(point)
+	n := len(idx.h)
+	switch {
+	case from < 0:
+		return idx.nz[n] - idx.nz[from+n] + idx.nz[to]
+	case to > n:
+		return idx.nz[n] - idx.nz[from] + idx.nz[to-n]
+	}
+	return idx.nz[to] - idx.nz[from]
+}
+
+/*
+neighbours appends the non-empty bins of the neighbourhood of point, excluding
+point, that are not yet in a cluster to S.
+*/
+func (idx *binIndex) neighbours(point int, clusters []int, S []int) []int {
+	from, to := idx.window(point)
+	for j := from; j < to; j++ {
+		i := (j + len(idx.h)) % len(idx.h)
+		if idx.h[i] > 0 && i != point && (clusters[i] == undefined || clusters[i] == Noise) {
+			S = append(S, i)
+		}
+	}
+	return S
+}
+
+func getClusters(h []int, cs []int, circular bool) (clusters []*Cluster) {
 	cmap := make(map[int]*Cluster)
 	for i, c := range cs {
 		if c > 0 {
@@ -93,7 +261,11 @@ func getClusters(cs []int) (clusters []*Cluster) {
 			}
 		}
 	}
-	for _, c := range cmap {
+	for l, c := range cmap {
+		if circular {
+			wrapCluster(c, cs, l)
+		}
+		c.setStats(h)
 		clusters = append(clusters, c)
 	}
 	sort.Slice(clusters,
@@ -102,27 +274,24 @@ func getClusters(cs []int) (clusters []*Cluster) {
 }
 
 /*
-getNeighbours returns the set of neighbours of `point`, which is an index in `h`.
-`neighbours` exclude `point`.
+wrapCluster sets the range of the cluster c with label l of a circular
+histogram to the shortest range around the histogram that contains its bins:
+the complement of the largest gap between its bins.
 */
-func getNeighbours(h []int, point, eps int) (neighbours, nbMinPoint []int) {
-	from := point - eps
-	if from < 0 {
-		from = 0
-	}
-	to := point + eps
-	if to > len(h) {
-		to = len(h)
-	}
-	for i := from; i < to; i++ {
-		if h[i] > 0 {
-			neighbours = append(neighbours, i)
-			if i != point {
-				nbMinPoint = append(nbMinPoint, i)
+func wrapCluster(c *Cluster, labels []int, l int) {
+	n := len(labels)
+	// The gap around the end of the histogram
+	gap, min, max := c.Min+n-c.Max, c.Min, c.Max
+	prev := c.Min
+	for i := c.Min + 1; i <= c.Max; i++ {
+		if labels[i] == l {
+			if i-prev > gap {
+				gap, min, max = i-prev, i, prev
 			}
+			prev = i
 		}
 	}
-	return
+	c.Min, c.Max = min, max
 }
 
 /*
@@ -130,8 +299,49 @@ WriteClusters writes the set of clusters `cs` to file `fname`.
 */
 func WriteClusters(cs []*Cluster, fname string) {
 	buf := new(bytes.Buffer)
+	WriteClustersTo(buf, cs)
+	myioutil.WriteFile(fname, buf.Bytes())
+}
+
+/*
+WriteClustersTo writes the set of clusters `cs` to w in the format of
+WriteClusters and returns the write error.
+*/
+func WriteClustersTo(w io.Writer, cs []*Cluster) error {
 	for i, c := range cs {
-		fmt.Fprintf(buf, "%d, %d %d\n", i, c.Min, c.Max)
+		if _, err := fmt.Fprintf(w, "%d, %d %d\n", i, c.Min, c.Max); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+WriteClustersJSON writes the set of clusters `cs` to file `fname` as a JSON
+array. The function panics if the file cannot be written.
+*/
+func WriteClustersJSON(cs []*Cluster, fname string) {
+	b, err := json.Marshal(cs)
+	if err != nil {
+		panic(err)
+	}
+	if err := myioutil.WriteFile(fname, b); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ReadClustersJSON returns the clusters written to file `fname` by
+WriteClustersJSON. The function panics if the file cannot be read or decoded.
+*/
+func ReadClustersJSON(fname string) []*Cluster {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		panic(err)
+	}
+	var cs []*Cluster
+	if err := json.Unmarshal(b, &cs); err != nil {
+		panic(err)
 	}
-	ioutil.WriteFile(fname, buf.Bytes())
+	return cs
 }
@@ -0,0 +1,241 @@
+package dbscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/internal/benchdata"
+)
+
+func TestDBSCAN(t *testing.T) {
+	points := [][]float64{
+		{0, 0}, {10, 10}, {0, 1}, {1, 0}, {10, 11}, {11, 10}, {5, 5}, {1, 1},
+	}
+	labels := DBSCAN(points, 1.5, 3, nil)
+	want := []int{0, 1, 0, 0, 1, 1, Noise, 0}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Fatalf("labels = %v, want %v", labels, want)
+		}
+	}
+	// Diagonal neighbours are more than 1.5 apart in the Manhattan metric
+	labels = DBSCAN(points[:4], 1.5, 4, godsp.Manhattan)
+	for i, l := range labels {
+		if l != Noise {
+			t.Errorf("labels[%d] = %d, want Noise", i, l)
+		}
+	}
+}
+
+func TestKDTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([][]float64, 2000)
+	for i := range points {
+		points[i] = []float64{r.Float64() * 100, r.Float64() * 100, r.Float64() * 10}
+	}
+	// A non-nil distance function is evaluated for all pairs of points
+	want, got := DBSCAN(points, 4, 5, godsp.Euclidean), DBSCAN(points, 4, 5, nil)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("labels[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	// Bins 0..6 are density-connected through the chain of core bins
+	h := []int{1, 0, 2, 0, 3, 0, 1, 0, 0, 0, 0, 5, 5, 0, 0, 0, 0, 0, 0, 0, 7}
+	cs := Histogram(h, 3, 2)
+	if len(cs) != 2 || cs[0].Min != 0 || cs[0].Max != 6 || cs[1].Min != 11 || cs[1].Max != 12 {
+		for _, c := range cs {
+			t.Errorf("cluster %+v", *c)
+		}
+	}
+	labels, _ := HistogramLabels(h, 3, 2)
+	for i, l := range labels {
+		want := Noise
+		switch {
+		case i <= 6 && h[i] > 0:
+			want = 0
+		case i == 11 || i == 12:
+			want = 1
+		}
+		if l != want {
+			t.Errorf("labels[%d] = %d, want %d", i, l, want)
+		}
+	}
+}
+
+func TestOPTICS(t *testing.T) {
+	// A dense and a sparse cluster and 2 outliers
+	points := [][]float64{}
+	for i := 0; i < 30; i++ {
+		points = append(points, []float64{float64(i) * 0.01})
+	}
+	for i := 0; i < 30; i++ {
+		points = append(points, []float64{10 + float64(i)*0.5})
+	}
+	points = append(points, []float64{50}, []float64{-20})
+	o := OPTICS(points, 100, 5, nil)
+	if len(o.Order) != len(points) || !math.IsInf(o.Reachability[0], 1) {
+		t.Fatalf("order = %v, reachability = %v", o.Order, o.Reachability)
+	}
+	want := DBSCAN(points, 1, 5, nil)
+	for i, l := range o.ExtractDBSCAN(1) {
+		if o.CoreDistance[i] <= 1 && l != want[i] {
+			t.Fatalf("ExtractDBSCAN: labels[%d] = %d, want %d", i, l, want[i])
+		}
+	}
+	labels := o.ExtractXi(0.1, 0)
+	for i, l := range labels {
+		switch {
+		case i < 30 && l != labels[0], i >= 30 && i < 60 && l != labels[30]:
+			t.Fatalf("labels = %v", labels)
+		case i >= 60 && l != Noise:
+			t.Errorf("outlier %d has label %d", i, l)
+		}
+	}
+	if labels[0] == labels[30] || labels[0] == Noise || labels[30] == Noise {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestAgglomerative(t *testing.T) {
+	h := []int{1, 1, 0, 1, 0, 0, 0, 0, 5, 0, 1}
+	for _, tc := range []struct {
+		linkage Linkage
+		cutoff  float64
+		want    [][2]int
+	}{
+		{Single, 2, [][2]int{{0, 3}, {8, 10}}},
+		{Complete, 3, [][2]int{{0, 3}, {8, 10}}},
+		{Complete, 2, [][2]int{{0, 1}, {3, 3}, {8, 10}}},
+		{Average, 2, [][2]int{{0, 1}, {3, 3}, {8, 10}}},
+		{Average, 2.5, [][2]int{{0, 3}, {8, 10}}},
+		{Single, 0.5, [][2]int{{0, 0}, {1, 1}, {3, 3}, {8, 8}, {10, 10}}},
+	} {
+		cs := Agglomerative(h, tc.cutoff, tc.linkage)
+		ok := len(cs) == len(tc.want)
+		for i := 0; ok && i < len(cs); i++ {
+			ok = cs[i].Min == tc.want[i][0] && cs[i].Max == tc.want[i][1]
+		}
+		if !ok {
+			t.Errorf("linkage %d cutoff %f: %v, want %v", tc.linkage, tc.cutoff, cs, tc.want)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := []int{1, 3, 1, 0, 0, 0, 0, 0, 2, 4, 2}
+	good := Agglomerative(h, 2, Single)
+	if c := good[1]; c.Count != 3 || c.Weight != 8 || c.Centroid != 9 {
+		t.Errorf("cluster = %+v", *c)
+	}
+	bad := []*Cluster{{Min: 0, Max: 1}, {Min: 2, Max: 9}, {Min: 10, Max: 10}}
+	if sg, sb := Silhouette(h, good), Silhouette(h, bad); sg < 0.8 || sg <= sb {
+		t.Errorf("Silhouette good %f, bad %f", sg, sb)
+	}
+	if dg, db := DaviesBouldin(h, good), DaviesBouldin(h, bad); dg > 0.2 || dg >= db {
+		t.Errorf("DaviesBouldin good %f, bad %f", dg, db)
+	}
+	// The clusters are not modified
+	if c := bad[1]; c.Count != 0 || c.Weight != 0 || c.Centroid != 0 {
+		t.Errorf("DaviesBouldin modified cluster %+v", *c)
+	}
+	if s := Silhouette(h, good[:1]); s != 0 {
+		t.Errorf("Silhouette of one cluster = %f", s)
+	}
+}
+
+func TestEstimateEps(t *testing.T) {
+	// 2 dense clusters and sparse noise
+	h := make([]int, 200)
+	for i := 20; i < 40; i++ {
+		h[i] = 3
+	}
+	for i := 100; i < 130; i += 2 {
+		h[i] = 2
+	}
+	for _, i := range []int{60, 75, 160, 185} {
+		h[i] = 1
+	}
+	eps := EstimateEps(h, 4)
+	cs := Histogram(h, eps, 4)
+	if len(cs) != 2 || cs[0].Min != 20 || cs[0].Max != 39 || cs[1].Min != 100 || cs[1].Max != 128 {
+		t.Errorf("eps = %d", eps)
+		for _, c := range cs {
+			t.Errorf("cluster %+v", *c)
+		}
+	}
+	if eps := EstimateEps([]int{1, 0, 1}, 3); eps != 0 {
+		t.Errorf("EstimateEps of too few bins = %d", eps)
+	}
+}
+
+func TestHistogramCircular(t *testing.T) {
+	h := make([]int, 36)
+	h[34], h[35], h[0], h[1] = 2, 4, 4, 2
+	h[17], h[18] = 3, 3
+	h[9] = 1
+	if cs := Histogram(h, 2, 2); len(cs) != 3 {
+		t.Errorf("Histogram: %d clusters", len(cs))
+	}
+	labels, cs := HistogramLabelsCircular(h, 2, 2)
+	if len(cs) != 2 || cs[0].Min != 17 || cs[0].Max != 18 || cs[1].Min != 34 || cs[1].Max != 1 {
+		for _, c := range cs {
+			t.Errorf("cluster %+v", *c)
+		}
+		t.FailNow()
+	}
+	if c := cs[1]; c.Count != 4 || c.Weight != 12 || c.Centroid != 35.5 {
+		t.Errorf("cluster = %+v", *c)
+	}
+	if labels[0] != 1 || labels[35] != 1 || labels[17] != 0 || labels[9] != Noise {
+		t.Errorf("labels = %v", labels)
+	}
+	if cs := HistogramCircular(h, 20, 2); len(cs) != 1 {
+		t.Errorf("HistogramCircular with large eps: %d clusters", len(cs))
+	}
+}
+
+func TestDBSCANContext(t *testing.T) {
+	points := [][]float64{{0}, {0.1}, {0.2}, {5}, {5.1}, {5.2}, {10}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DBSCANContext(ctx, points, 0.5, 2, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("DBSCANContext: err = %v", err)
+	}
+	labels, err := DBSCANContext(context.Background(), points, 0.5, 2, nil)
+	if err != nil || fmt.Sprint(labels) != fmt.Sprint(DBSCAN(points, 0.5, 2, nil)) {
+		t.Errorf("DBSCANContext = %v, %v", labels, err)
+	}
+}
+
+func BenchmarkHistogram(b *testing.B) {
+	for _, nbins := range []int{1000, 100000} {
+		h := benchdata.Histogram(nbins, 10)
+		b.Run(fmt.Sprintf("bins=%d", nbins), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Histogram(h, 3, 20)
+			}
+		})
+	}
+}
+
+func BenchmarkDBSCAN(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		points := benchdata.Points(n, 2, 10)
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				DBSCAN(points, 1, 5, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dbscan
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+EstimateEps estimates the parameter eps of Histogram for `h` and minPts by the
+knee of the k-distance graph. The k-distance of a non-empty bin is the
+smallest eps for which its neighbourhood contains minPts non-empty bins. The
+k-distances sorted in increasing order rise slowly over the bins of clusters
+and steeply over noise bins. EstimateEps returns the k-distance at the knee:
+the point of the graph furthest below the line between its end points.
+
+EstimateEps returns 0 if h has fewer than minPts non-empty bins. The function
+panics if minPts < 1.
+*/
+func EstimateEps(h []int, minPts int) int {
+	if minPts < 1 {
+		panic(fmt.Sprintf("Invalid minPts %d", minPts))
+	}
+	bins := []int{}
+	for i, n := range h {
+		if n > 0 {
+			bins = append(bins, i)
+		}
+	}
+	if len(bins) < minPts {
+		return 0
+	}
+	dist := make([]int, len(bins))
+	for i := range bins {
+		dist[i] = kDistance(bins, i, minPts)
+	}
+	sort.Ints(dist)
+	return dist[knee(dist)]
+}
+
+/*
+kDistance returns the smallest eps for which the neighbourhood
+[p-eps, p+eps) of p = bins[i] contains k of the sorted bins. bins must contain
+at least k bins.
+*/
+func kDistance(bins []int, i, k int) int {
+	p := bins[i]
+	// p is in its neighbourhood for eps >= 1
+	d := 1
+	l, r := i-1, i+1
+	for n := 1; n < k; n++ {
+		// Bin b < p is in the neighbourhood for eps >= p-b, b > p for eps >= b-p+1
+		if r >= len(bins) || (l >= 0 && p-bins[l] <= bins[r]-p+1) {
+			d = p - bins[l]
+			l--
+		} else {
+			d = bins[r] - p + 1
+			r++
+		}
+	}
+	return d
+}
+
+// knee returns the index of the point of the increasing dist furthest below the chord of dist
+func knee(dist []int) int {
+	n := len(dist) - 1
+	span := float64(dist[n] - dist[0])
+	if n == 0 || span == 0 {
+		return n
+	}
+	best, bestD := n, 0.0
+	for i, d := range dist {
+		x := float64(i) / float64(n)
+		y := float64(d-dist[0]) / span
+		if x-y > bestD {
+			best, bestD = i, x-y
+		}
+	}
+	return best
+}
@@ -0,0 +1,80 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dbscan
+
+import (
+	"sort"
+)
+
+/*
+kdTree is a balanced kd-tree of points for Euclidean radius queries. The tree is
+implicit in idx: the root of idx[lo:hi] is idx[(lo+hi)/2], which splits the
+points of the subtree on dimension depth % dim.
+*/
+type kdTree struct {
+	points [][]float64
+	idx    []int
+}
+
+func newKDTree(points [][]float64) *kdTree {
+	t := &kdTree{points: points, idx: make([]int, len(points))}
+	for i := range t.idx {
+		t.idx[i] = i
+	}
+	if len(points) > 0 {
+		t.build(0, len(points), 0)
+	}
+	return t
+}
+
+func (t *kdTree) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	d := depth % len(t.points[0])
+	sub := t.idx[lo:hi]
+	sort.Slice(sub, func(i, j int) bool { return t.points[sub[i]][d] < t.points[sub[j]][d] })
+	mid := (lo + hi) / 2
+	t.build(lo, mid, depth+1)
+	t.build(mid+1, hi, depth+1)
+}
+
+// radius appends the indices of the points within Euclidean distance eps of q to N
+func (t *kdTree) radius(q []float64, eps float64, N []int) []int {
+	return t.search(0, len(t.idx), 0, q, eps, eps*eps, N)
+}
+
+func (t *kdTree) search(lo, hi, depth int, q []float64, eps, eps2 float64, N []int) []int {
+	if lo >= hi {
+		return N
+	}
+	mid := (lo + hi) / 2
+	p := t.points[t.idx[mid]]
+	d2 := 0.0
+	for i, f := range p {
+		d2 += (f - q[i]) * (f - q[i])
+	}
+	if d2 <= eps2 {
+		N = append(N, t.idx[mid])
+	}
+	d := depth % len(p)
+	if q[d]-eps <= p[d] {
+		N = t.search(lo, mid, depth+1, q, eps, eps2, N)
+	}
+	if q[d]+eps >= p[d] {
+		N = t.search(mid+1, hi, depth+1, q, eps, eps2, N)
+	}
+	return N
+}
@@ -0,0 +1,124 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dbscan
+
+import (
+	"math"
+)
+
+/*
+Silhouette returns the mean silhouette coefficient of the clusters `cs` of the
+histogram `h`. Every count of a bin is an observation at the position of the
+bin and the distance between observations is the distance between their bins.
+Bins outside the clusters are ignored. The coefficient lies in [-1,1], larger
+values indicate denser, better separated clusters. Silhouette returns 0 if
+there are fewer than 2 clusters. The distance between bins is not circular and
+clusters that wrap around the end of h (Min > Max) are not supported.
+
+Silhouette can be used to select eps and minPts of Histogram by sweeping the
+parameters and keeping the clustering with the highest score.
+*/
+func Silhouette(h []int, cs []*Cluster) float64 {
+	if len(cs) < 2 {
+		return 0
+	}
+	sum, n := 0.0, 0
+	for ci, c := range cs {
+		for i := c.Min; i <= c.Max; i++ {
+			if h[i] == 0 {
+				continue
+			}
+			n += h[i]
+			w := 0
+			a := 0.0
+			for j := c.Min; j <= c.Max; j++ {
+				w += h[j]
+				a += float64(h[j]) * math.Abs(float64(i-j))
+			}
+			// A singleton observation has a silhouette of 0
+			if w == 1 {
+				continue
+			}
+			a /= float64(w - 1)
+			b := math.Inf(1)
+			for cj, c1 := range cs {
+				if cj != ci {
+					b = math.Min(b, meanDistance(h, c1, i))
+				}
+			}
+			if s := math.Max(a, b); s > 0 {
+				sum += float64(h[i]) * (b - a) / s
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+/*
+DaviesBouldin returns the Davies-Bouldin index of the clusters `cs` of the
+histogram `h`: the average over the clusters of the largest ratio
+(S_i + S_j)/M_ij, where S_i is the mean distance of the counts of cluster i
+from its centroid and M_ij is the distance between the centroids of clusters i
+and j. Lower values indicate better clusterings. DaviesBouldin returns 0 if
+there are fewer than 2 clusters.
+Like Silhouette, DaviesBouldin does not support clusters that wrap around.
+*/
+func DaviesBouldin(h []int, cs []*Cluster) float64 {
+	if len(cs) < 2 {
+		return 0
+	}
+	// The statistics are computed on copies to leave cs unchanged
+	S, centroids := make([]float64, len(cs)), make([]float64, len(cs))
+	for i, c := range cs {
+		stats := *c
+		stats.setStats(h)
+		centroids[i] = stats.Centroid
+		if stats.Weight > 0 {
+			S[i] = meanDistance(h, c, stats.Centroid)
+		}
+	}
+	sum := 0.0
+	for i := range cs {
+		maxR := 0.0
+		for j := range cs {
+			if j == i {
+				continue
+			}
+			if m := math.Abs(centroids[i] - centroids[j]); m > 0 {
+				maxR = math.Max(maxR, (S[i]+S[j])/m)
+			} else {
+				maxR = math.Inf(1)
+			}
+		}
+		sum += maxR
+	}
+	return sum / float64(len(cs))
+}
+
+// meanDistance returns the mean distance of the counts of c in h from x
+func meanDistance[T int | float64](h []int, c *Cluster, x T) float64 {
+	sum, w := 0.0, 0
+	for j := c.Min; j <= c.Max; j++ {
+		w += h[j]
+		sum += float64(h[j]) * math.Abs(float64(x)-float64(j))
+	}
+	if w == 0 {
+		return math.Inf(1)
+	}
+	return sum / float64(w)
+}
@@ -0,0 +1,329 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dbscan
+
+import (
+	"math"
+	"sort"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+Ordering is the result of OPTICS: the cluster ordering of the points with their
+reachability distances, from which clusters of varying density are extracted.
+
+See: M. Ankerst et al. OPTICS: Ordering Points To Identify the Clustering
+Structure. ACM SIGMOD 1999.
+*/
+type Ordering struct {
+	// Order contains the indices of the points in cluster order
+	Order []int
+	/*
+		Reachability is the reachability plot: Reachability[i] is the
+		reachability distance of point Order[i] from the points before it. It is
+		+Inf for the first point of each group of points within maxEps.
+	*/
+	Reachability []float64
+	/*
+		CoreDistance[p] is the distance of point p to its minPts'th nearest
+		neighbour, including p, or +Inf if that is further than maxEps.
+	*/
+	CoreDistance []float64
+	// predecessor[p] is the point from which p was reached or -1
+	predecessor []int
+	minPts      int
+}
+
+/*
+OPTICS returns the cluster ordering of points, which have equal dimensions. The
+neighbours of a point are the points within distance maxEps of it. A smaller
+maxEps is faster, and clusters can be extracted for any eps <= maxEps. dist is
+used as by DBSCAN: if it is nil the distance is Euclidean and neighbours are
+found by a kd-tree.
+*/
+func OPTICS(points [][]float64, maxEps float64, minPts int, dist godsp.DistanceFunc) *Ordering {
+	n := len(points)
+	o := &Ordering{
+		Order:        make([]int, 0, n),
+		Reachability: make([]float64, 0, n),
+		CoreDistance: make([]float64, n),
+		predecessor:  make([]int, n),
+		minPts:       minPts,
+	}
+	distance := dist
+	var neighbours func(p int) []int
+	if dist == nil {
+		distance = godsp.Euclidean
+		tree := newKDTree(points)
+		neighbours = func(p int) []int { return tree.radius(points[p], maxEps, []int{}) }
+	} else {
+		neighbours = func(p int) []int {
+			N := []int{}
+			for q := range points {
+				if dist(points[p], points[q]) <= maxEps {
+					N = append(N, q)
+				}
+			}
+			return N
+		}
+	}
+
+	reach := make([]float64, n)
+	processed := make([]bool, n)
+	for p := range reach {
+		reach[p], o.predecessor[p] = math.Inf(1), -1
+	}
+	for len(o.Order) < n {
+		// The next point is the unprocessed point with the smallest reachability
+		p := -1
+		for q := range points {
+			if !processed[q] && (p == -1 || reach[q] < reach[p]) {
+				p = q
+			}
+		}
+		processed[p] = true
+		o.Order = append(o.Order, p)
+		o.Reachability = append(o.Reachability, reach[p])
+
+		N := neighbours(p)
+		d := make([]float64, len(N))
+		for i, q := range N {
+			d[i] = distance(points[p], points[q])
+		}
+		o.CoreDistance[p] = math.Inf(1)
+		if minPts >= 1 && len(N) >= minPts {
+			sorted := append([]float64{}, d...)
+			sort.Float64s(sorted)
+			o.CoreDistance[p] = sorted[minPts-1]
+		}
+		if math.IsInf(o.CoreDistance[p], 1) {
+			continue
+		}
+		for i, q := range N {
+			if processed[q] {
+				continue
+			}
+			if r := math.Max(o.CoreDistance[p], d[i]); r < reach[q] {
+				reach[q], o.predecessor[q] = r, p
+			}
+		}
+	}
+	return o
+}
+
+/*
+ExtractDBSCAN returns the labels of the points of the clusters that DBSCAN
+finds with eps <= maxEps. The core points are labelled as by DBSCAN. Border
+points, which are not core points, may be labelled with either of two clusters
+that they are close to, or Noise if they precede the core points of their
+cluster in the cluster order. Clusters are numbered in the cluster order.
+*/
+func (o *Ordering) ExtractDBSCAN(eps float64) []int {
+	labels := make([]int, len(o.Order))
+	C := -1
+	for i, p := range o.Order {
+		core := o.CoreDistance[p] <= eps
+		if o.Reachability[i] > eps {
+			if core {
+				C++
+				labels[p] = C
+			} else {
+				labels[p] = Noise
+			}
+		} else {
+			labels[p] = C
+		}
+	}
+	return labels
+}
+
+/*
+ExtractXi returns the labels of the points of the clusters found by the ξ
+method in the reachability plot. A cluster starts at a region where the
+reachability falls steeply, by a factor of 1-xi or more between consecutive
+points, and ends at a steep rise. Clusters may be nested in the reachability
+plot; each point is labelled with its innermost cluster. Clusters with fewer
+than minClusterSize points are ignored; minClusterSize defaults to minPts if it
+is < 2. Points in no cluster are labelled Noise.
+The function panics if xi is not in (0,1).
+*/
+func (o *Ordering) ExtractXi(xi float64, minClusterSize int) []int {
+	if xi <= 0 || xi >= 1 {
+		panic("xi must be in (0,1)")
+	}
+	if minClusterSize < 2 {
+		minClusterSize = o.minPts
+	}
+	labels := make([]int, len(o.Order))
+	for i := range labels {
+		labels[i] = Noise
+	}
+	label := 0
+	for _, c := range o.xiClusters(xi, minClusterSize) {
+		free := true
+		for i := c[0]; i <= c[1]; i++ {
+			free = free && labels[o.Order[i]] == Noise
+		}
+		if !free {
+			continue
+		}
+		for i := c[0]; i <= c[1]; i++ {
+			labels[o.Order[i]] = label
+		}
+		label++
+	}
+	return labels
+}
+
+// steepDownArea is a steep down area of the reachability plot
+type steepDownArea struct {
+	start, end int
+	// mib is the maximum reachability between the end of the area and the current index
+	mib float64
+}
+
+/*
+xiClusters returns the ranges [start,end] of positions in the cluster order of
+the ξ clusters, inner clusters before the clusters that contain them. It
+follows the ξ extraction of Ankerst et al. with the corrections of
+scikit-learn.
+*/
+func (o *Ordering) xiClusters(xi float64, minClusterSize int) [][2]int {
+	n := len(o.Reachability)
+	r := append(append([]float64{}, o.Reachability...), math.Inf(1))
+	xiC := 1 - xi
+	steepUp, steepDown := make([]bool, n), make([]bool, n)
+	up, down := make([]bool, n), make([]bool, n)
+	for i := 0; i < n; i++ {
+		ratio := r[i] / r[i+1]
+		if math.IsNaN(ratio) {
+			continue
+		}
+		steepUp[i], steepDown[i] = ratio <= xiC, ratio >= 1/xiC
+		up[i], down[i] = ratio < 1, ratio > 1
+	}
+
+	var sdas []*steepDownArea
+	filterSDAs := func(mib float64) {
+		if math.IsInf(mib, 1) {
+			sdas = nil
+			return
+		}
+		kept := sdas[:0]
+		for _, sda := range sdas {
+			if mib <= r[sda.start]*xiC {
+				sda.mib = math.Max(sda.mib, mib)
+				kept = append(kept, sda)
+			}
+		}
+		sdas = kept
+	}
+
+	clusters := [][2]int{}
+	index, mib := 0, 0.0
+	for steep := 0; steep < n; steep++ {
+		if !(steepUp[steep] || steepDown[steep]) || steep < index {
+			continue
+		}
+		for _, f := range r[index : steep+1] {
+			mib = math.Max(mib, f)
+		}
+		filterSDAs(mib)
+		if steepDown[steep] {
+			end := extendRegion(steepDown, up, steep, o.minPts)
+			sdas = append(sdas, &steepDownArea{start: steep, end: end})
+			index = end + 1
+			mib = r[index]
+			continue
+		}
+		upStart := steep
+		upEnd := extendRegion(steepUp, down, upStart, o.minPts)
+		index = upEnd + 1
+		mib = r[index]
+		var upClusters [][2]int
+		for _, D := range sdas {
+			cStart, cEnd := D.start, upEnd
+			if r[cEnd+1]*xiC < D.mib {
+				continue
+			}
+			dMax := r[D.start]
+			if dMax*xiC >= r[cEnd+1] {
+				for r[cStart+1] > r[cEnd+1] && cStart < D.end {
+					cStart++
+				}
+			} else if r[cEnd+1]*xiC >= dMax {
+				for cEnd > upStart && r[cEnd-1] > dMax {
+					cEnd--
+				}
+			}
+			var ok bool
+			if cStart, cEnd, ok = o.correctPredecessor(r, cStart, cEnd); !ok {
+				continue
+			}
+			if cEnd-cStart+1 < minClusterSize || cStart > D.end || cEnd < upStart {
+				continue
+			}
+			upClusters = append(upClusters, [2]int{cStart, cEnd})
+		}
+		for i := len(upClusters) - 1; i >= 0; i-- {
+			clusters = append(clusters, upClusters[i])
+		}
+	}
+	return clusters
+}
+
+/*
+extendRegion returns the end of the steep region that starts at start. The
+region may contain up to minPts consecutive points that are neither steep nor
+in the direction of the region, given by xward.
+*/
+func extendRegion(steep, xward []bool, start, minPts int) int {
+	nonXward, end := 0, start
+	for i := start; i < len(steep); i++ {
+		if steep[i] {
+			nonXward, end = 0, i
+		} else if !xward[i] {
+			nonXward++
+			if nonXward > minPts {
+				break
+			}
+		} else {
+			return end
+		}
+	}
+	return end
+}
+
+/*
+correctPredecessor shrinks the end of the cluster [s,e] until its last point was
+reached from a point in the cluster, as required by the definition of a
+cluster.
+*/
+func (o *Ordering) correctPredecessor(r []float64, s, e int) (int, int, bool) {
+	for s < e {
+		if r[s] > r[e] {
+			return s, e, true
+		}
+		pe := o.predecessor[o.Order[e]]
+		for i := s; i < e; i++ {
+			if o.Order[i] == pe {
+				return s, e, true
+			}
+		}
+		e--
+	}
+	return 0, 0, false
+}
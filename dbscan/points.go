@@ -0,0 +1,108 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dbscan
+
+import (
+	"context"
+
+	"github.com/goccmack/godsp"
+)
+
+// Noise is the label of points that belong to no cluster
+const Noise = -1
+
+/*
+DBSCAN clusters points, which have equal dimensions, and returns the label of
+each point: the cluster 0, 1, ... or Noise. Clusters are numbered in the order
+of their first point. The neighbours of a point are the points within
+distance eps of it, including the point itself, and a point with at least
+minPts neighbours is a core point.
+
+If dist is nil the distance is Euclidean and the neighbours are found by a
+kd-tree in O(log(len(points))) for small eps. Other distance functions are
+evaluated between all pairs of points.
+*/
+func DBSCAN(points [][]float64, eps float64, minPts int, dist godsp.DistanceFunc) []int {
+	labels, _ := DBSCANContext(context.Background(), points, eps, minPts, dist)
+	return labels
+}
+
+/*
+DBSCANContext returns DBSCAN(points, eps, minPts, dist) or ctx.Err() if ctx is
+done before the points are labelled. ctx is checked before each neighbourhood
+query.
+*/
+func DBSCANContext(ctx context.Context, points [][]float64, eps float64, minPts int, dist godsp.DistanceFunc) ([]int, error) {
+	labels := make([]int, len(points))
+	for i := range labels {
+		labels[i] = undefined
+	}
+	var neighbours func(p int) []int
+	if dist == nil {
+		tree := newKDTree(points)
+		neighbours = func(p int) []int {
+			return tree.radius(points[p], eps, []int{})
+		}
+	} else {
+		neighbours = func(p int) []int {
+			N := []int{}
+			for q := range points {
+				if dist(points[p], points[q]) <= eps {
+					N = append(N, q)
+				}
+			}
+			return N
+		}
+	}
+	C := 0
+	for p := range points {
+		if labels[p] != undefined {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		N := neighbours(p)
+		if len(N) < minPts {
+			labels[p] = Noise
+			continue
+		}
+		C++
+		labels[p] = C
+		for k := 0; k < len(N); k++ {
+			q := N[k]
+			if labels[q] == Noise {
+				labels[q] = C
+			}
+			if labels[q] != undefined {
+				continue
+			}
+			labels[q] = C
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if Nq := neighbours(q); len(Nq) >= minPts {
+				N = append(N, Nq...)
+			}
+		}
+	}
+	// Clusters are labelled from 1 during the search
+	for i, l := range labels {
+		if l != Noise {
+			labels[i] = l - 1
+		}
+	}
+	return labels, nil
+}
@@ -0,0 +1,208 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+/*
+Decoder decodes an audio format. Decoders for compressed formats such as MP3
+and Ogg Vorbis can be provided by other packages and registered with
+RegisterDecoder, typically in their init function.
+*/
+type Decoder interface {
+	// Open returns the stream of samples encoded in r
+	Open(r io.Reader) (Stream, error)
+}
+
+/*
+Stream is a stream of decoded multi-channel samples in the channel format of
+ReadWavFile.
+*/
+type Stream interface {
+	// Format returns the sample rate in Hz, number of channels and bits per sample
+	Format() (sampleRate, numChannels, bitsPerSample int)
+	/*
+		ReadFrames returns the next frames of the stream: one slice of up to n
+		samples per channel. At the end of the stream it returns io.EOF.
+	*/
+	ReadFrames(n int) ([][]float64, error)
+}
+
+// DecoderFunc adapts a function that decodes a whole stream to a Decoder
+type DecoderFunc func(r io.Reader) (channels [][]float64, sampleRate, bitsPerSample int, err error)
+
+// Open decodes r and returns a Stream of the decoded channels
+func (f DecoderFunc) Open(r io.Reader) (Stream, error) {
+	channels, sampleRate, bitsPerSample, err := f(r)
+	if err != nil {
+		return nil, err
+	}
+	return &channelStream{channels, sampleRate, bitsPerSample}, nil
+}
+
+var decoders = struct {
+	sync.RWMutex
+	m map[string]Decoder
+}{m: map[string]Decoder{}}
+
+func init() {
+	wav := DecoderFunc(func(r io.Reader) ([][]float64, int, int, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return decodeWav[float64](b)
+	})
+	aiff := DecoderFunc(func(r io.Reader) ([][]float64, int, int, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return decodeAiff(b)
+	})
+	RegisterDecoder(".wav", wav)
+	for _, ext := range []string{".aif", ".aiff", ".aifc"} {
+		RegisterDecoder(ext, aiff)
+	}
+}
+
+/*
+RegisterDecoder registers the decoder of the files with extension ext, e.g.
+".mp3". Extensions are not case sensitive. A later registration of an
+extension replaces the earlier one.
+*/
+func RegisterDecoder(ext string, d Decoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+	decoders.m[strings.ToLower(ext)] = d
+}
+
+/*
+ReadAudioFile returns the demultiplexed channels of an audio file, its sample
+rate in Hz and bits per sample. The file is decoded by the Decoder registered
+for its extension. Decoders for wav and AIFF files are registered by default.
+The progress of reading the file is reported to the function of WithProgress.
+*/
+func ReadAudioFile(fname string, opts ...Option) (channels [][]float64, sampleRate, bitsPerSample int) {
+	channels, sampleRate, bitsPerSample, err := ReadAudioFileErr(fname, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+ReadAudioFileErr returns ReadAudioFile(fname) or the error reading or decoding
+the file. Files without a registered Decoder return an error wrapping
+ErrArgument.
+*/
+func ReadAudioFileErr(fname string, opts ...Option) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	d, err := lookupDecoder(filepath.Ext(fname))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+	r, err := progressFile(f, getOptions(opts))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return decodeAudio(d, r)
+}
+
+/*
+ReadAudioFrom returns the demultiplexed channels, sample rate and bits per
+sample of the audio read from r, which is decoded by the Decoder registered for
+the file extension ext, e.g. ".wav". Extensions without a registered Decoder
+return an error wrapping ErrArgument.
+*/
+func ReadAudioFrom(r io.Reader, ext string) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	d, err := lookupDecoder(ext)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return decodeAudio(d, r)
+}
+
+func lookupDecoder(ext string) (Decoder, error) {
+	ext = strings.ToLower(ext)
+	decoders.RLock()
+	d, ok := decoders.m[ext]
+	decoders.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: no decoder for %q files", ErrArgument, ext)
+	}
+	return d, nil
+}
+
+func decodeAudio(d Decoder, r io.Reader) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	s, err := d.Open(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	sampleRate, numChannels, bitsPerSample := s.Format()
+	channels = make([][]float64, numChannels)
+	for {
+		frames, err := s.ReadFrames(4096)
+		for i := range channels {
+			if i < len(frames) {
+				channels[i] = append(channels[i], frames[i]...)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	return channels, sampleRate, bitsPerSample, nil
+}
+
+// channelStream is a Stream of decoded channels
+type channelStream struct {
+	channels      [][]float64
+	sampleRate    int
+	bitsPerSample int
+}
+
+func (s *channelStream) Format() (sampleRate, numChannels, bitsPerSample int) {
+	return s.sampleRate, len(s.channels), s.bitsPerSample
+}
+
+func (s *channelStream) ReadFrames(n int) ([][]float64, error) {
+	if len(s.channels) == 0 || len(s.channels[0]) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(s.channels[0]) {
+		n = len(s.channels[0])
+	}
+	frames := make([][]float64, len(s.channels))
+	for i, ch := range s.channels {
+		frames[i], s.channels[i] = ch[:n], ch[n:]
+	}
+	return frames, nil
+}
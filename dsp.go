@@ -23,9 +23,14 @@ package godsp
 import (
 	"bufio"
 	"bytes"
+	"container/heap"
+	"context"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"math"
+	"math/cmplx"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -33,12 +38,20 @@ import (
 )
 
 // Abs returns |x|
-func Abs(x []float64) []float64 {
-	x1 := make([]float64, len(x))
+func Abs[T Float](x []T) []T {
+	return AbsTo(make([]T, len(x)), x)
+}
+
+/*
+AbsTo stores |x| in dst and returns dst. dst may be x.
+The function panics if len(dst) != len(x).
+*/
+func AbsTo[T Float](dst, x []T) []T {
+	checkDst(dst, x)
 	for i, f := range x {
-		x1[i] = math.Abs(f)
+		dst[i] = T(math.Abs(float64(f)))
 	}
-	return x1
+	return dst
 }
 
 // AbsInt returns |x|
@@ -54,70 +67,229 @@ func AbsInt(x []int) []int {
 	return x1
 }
 
-// AbsAll returns Abs(x) for every x in X
-func AbsAll(X [][]float64) [][]float64 {
-	x1 := make([][]float64, len(X))
-	for i, x := range X {
-		x1[i] = Abs(x)
+// AbsAll returns Abs(x) for every x in X. See WithWorkers.
+func AbsAll[T Float](X [][]T, opts ...Option) [][]T {
+	return mapAll(X, getOptions(opts), Abs[T])
+}
+
+/*
+Add returns x + y. The function panics if len(x) != len(y).
+*/
+func Add[T Float](x, y []T) []T {
+	return AddTo(make([]T, len(x)), x, y)
+}
+
+/*
+AddTo stores x + y in dst and returns dst. dst may be x or y.
+The function panics if the lengths of dst, x and y differ.
+*/
+func AddTo[T Float](dst, x, y []T) []T {
+	checkDst2(dst, x, y)
+	for i := range x {
+		dst[i] = x[i] + y[i]
 	}
-	return x1
+	return dst
+}
+
+/*
+AddS returns x + s where x is a vector and s a scalar.
+*/
+func AddS[T Float](x []T, s T) []T {
+	return AddSTo(make([]T, len(x)), x, s)
+}
+
+/*
+AddSTo stores x + s in dst and returns dst. dst may be x.
+The function panics if len(dst) != len(x).
+*/
+func AddSTo[T Float](dst, x []T, s T) []T {
+	checkDst(dst, x)
+	for i := range x {
+		dst[i] = x[i] + s
+	}
+	return dst
+}
+
+// Bias selects the estimator of Autocorr
+type Bias int
+
+const (
+	// Biased divides lag k by N
+	Biased Bias = iota
+	// Unbiased divides lag k by N-k
+	Unbiased
+)
+
+/*
+Autocorr returns the autocorrelation of x for the lags 0 to maxLag, computed by
+FFT:
+
+	r[k] = sum_n x[n] x[n+k] / N      for mode == Biased
+	r[k] = sum_n x[n] x[n+k] / (N-k)  for mode == Unbiased
+
+Lags >= len(x) are 0.
+*/
+func Autocorr(x []float64, maxLag int, mode Bias) []float64 {
+	N := len(x)
+	r := make([]float64, maxLag+1)
+	if N == 0 {
+		return r
+	}
+	M := NextPow2(2 * N)
+	X := make([]complex128, M)
+	for n, f := range x {
+		X[n] = complex(f, 0)
+	}
+	radix2(X, false)
+	for i, c := range X {
+		X[i] = complex(real(c)*real(c)+imag(c)*imag(c), 0)
+	}
+	radix2(X, true)
+	for k := 0; k <= maxLag && k < N; k++ {
+		r[k] = real(X[k]) / float64(M)
+		if mode == Unbiased {
+			r[k] /= float64(N - k)
+		} else {
+			r[k] /= float64(N)
+		}
+	}
+	return r
 }
 
 /*
 Average returns Sum(x)/len(x).
 */
-func Average(x []float64) float64 {
-	return Sum(x) / float64(len(x))
+func Average[T Float](x []T) T {
+	return Sum(x) / T(len(x))
+}
+
+// Trend selects the trend removed by Detrend
+type Trend int
+
+const (
+	// Constant is the average of x
+	Constant Trend = iota
+	// Linear is the least squares line through x
+	Linear
+)
+
+/*
+Detrend returns x with its trend removed. Unlike RemoveAvg the result is not
+clamped to 0.
+*/
+func Detrend[T Float](x []T, trend Trend) []T {
+	y := make([]T, len(x))
+	if len(x) == 0 {
+		return y
+	}
+	avg, slope := float64(Average(x)), 0.0
+	tAvg := float64(len(x)-1) / 2
+	if trend == Linear {
+		num, den := 0.0, 0.0
+		for i, f := range x {
+			num += (float64(i) - tAvg) * (float64(f) - avg)
+			den += (float64(i) - tAvg) * (float64(i) - tAvg)
+		}
+		if den > 0 {
+			slope = num / den
+		}
+	}
+	for i, f := range x {
+		y[i] = T(float64(f) - avg - slope*(float64(i)-tAvg))
+	}
+	return y
 }
 
 /*
 DivS returns x/s where x is a vector and s a scalar.
 */
-func DivS(x []float64, s float64) []float64 {
-	y := make([]float64, len(x))
+func DivS[T Float](x []T, s T) []T {
+	return DivSTo(make([]T, len(x)), x, s)
+}
+
+/*
+DivSTo stores x/s in dst and returns dst. dst may be x.
+The function panics if len(dst) != len(x).
+*/
+func DivSTo[T Float](dst, x []T, s T) []T {
+	checkDst(dst, x)
 	for i := range x {
-		y[i] = x[i] / s
+		dst[i] = x[i] / s
 	}
-	return y
+	return dst
+}
+
+// Dot returns the dot product of x and y. The function panics if len(x) != len(y).
+func Dot[T Float](x, y []T) T {
+	d, err := DotErr(x, y)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DotErr returns Dot(x, y) or an error wrapping ErrLength if len(x) != len(y).
+func DotErr[T Float](x, y []T) (T, error) {
+	if err := checkSameLen(x, y); err != nil {
+		return 0, err
+	}
+	sum := 0.0
+	for i := range x {
+		sum += float64(x[i]) * float64(y[i])
+	}
+	return T(sum), nil
 }
 
 /*
-DownSampleAll returns DownSample(x, len(x)/min(len(xs))) for all x in xs
+DownSampleAll returns DownSample(x, len(x)/min(len(xs))) for all x in xs.
+See WithWorkers.
 */
-func DownSampleAll(xs [][]float64) [][]float64 {
+func DownSampleAll[T Float](xs [][]T, opts ...Option) [][]T {
 	N := len(xs[0])
 	for _, x := range xs {
 		if len(x) < N {
 			N = len(x)
 		}
 	}
-	ys := make([][]float64, len(xs))
-	for i, x := range xs {
-		ys[i] = DownSample(x, len(x)/N)
-	}
-	return ys
+	return mapAll(xs, getOptions(opts), func(x []T) []T {
+		return DownSample(x, len(x)/N)
+	})
 }
 
 /*
 DownSample returns x downsampled by n
 Function panics if len(x) is not an integer multiple of n.
 */
-func DownSample(x []float64, n int) []float64 {
-	if len(x)%n != 0 {
-		panic(fmt.Sprintf("len(x) (%d) is not an integer multiple of n (%d)", len(x), n))
+func DownSample[T Float](x []T, n int) []T {
+	x1, err := DownSampleErr(x, n)
+	if err != nil {
+		panic(err)
 	}
+	return x1
+}
 
-	x1 := make([]float64, len(x)/n)
+/*
+DownSampleErr returns DownSample(x, n) or an error wrapping ErrArgument if
+n < 1 or ErrLength if len(x) is not an integer multiple of n.
+*/
+func DownSampleErr[T Float](x []T, n int) ([]T, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: n = %d", ErrArgument, n)
+	}
+	if len(x)%n != 0 {
+		return nil, fmt.Errorf("%w: len(x) (%d) is not an integer multiple of n (%d)", ErrLength, len(x), n)
+	}
+	x1 := make([]T, len(x)/n)
 	for i, j := 0, 0; j < len(x1); i, j = i+n, j+1 {
 		x1[j] = x[i]
 	}
-	return x1
+	return x1, nil
 }
 
 // FindMax returns the value and index of the first element of x equal to the maximum value in x.
-func FindMax(x []float64) (value float64, index int) {
+func FindMax[T Float](x []T) (value T, index int) {
 	value, index = x[0], 0
-	for i := 1; i < len(x)-1; i++ {
+	for i := 1; i < len(x); i++ {
 		if x[i] > value {
 			value, index = x[i], i
 		}
@@ -128,7 +300,7 @@ func FindMax(x []float64) (value float64, index int) {
 // FindMax* returns the value and index of the first element of x equal to the maximum value in x.
 func FindMaxI(x []int) (value int, index int) {
 	value, index = x[0], 0
-	for i := 1; i < len(x)-1; i++ {
+	for i := 1; i < len(x); i++ {
 		if x[i] > value {
 			value, index = x[i], i
 		}
@@ -136,10 +308,23 @@ func FindMaxI(x []int) (value int, index int) {
 	return
 }
 
+/*
+FindMaxRange returns the value and index in x of the first element of x[from:to]
+equal to the maximum value in x[from:to].
+The function panics if x[from:to] is empty or out of range.
+*/
+func FindMaxRange[T Float](x []T, from, to int) (value T, index int) {
+	if from < 0 || to > len(x) || from >= to {
+		panic(fmt.Errorf("%w: range [%d:%d] of x with length %d", ErrArgument, from, to, len(x)))
+	}
+	value, index = FindMax(x[from:to])
+	return value, from + index
+}
+
 // FindMin returns the value and index of the first element of x equal to the minimum value in x.
-func FindMin(x []float64) (value float64, index int) {
+func FindMin[T Float](x []T) (value T, index int) {
 	value, index = x[0], 0
-	for i := 1; i < len(x)-1; i++ {
+	for i := 1; i < len(x); i++ {
 		if x[i] < value {
 			value, index = x[i], i
 		}
@@ -147,15 +332,94 @@ func FindMin(x []float64) (value float64, index int) {
 	return
 }
 
+/*
+ArgSort returns the indices of the elements of x in increasing order of their
+values. Equal elements are in increasing order of their indices.
+*/
+func ArgSort[T Float](x []T) []int {
+	idx := Range(len(x))
+	sort.SliceStable(idx, func(i, j int) bool { return x[idx[i]] < x[idx[j]] })
+	return idx
+}
+
+/*
+FindTopK returns the indices of the k largest samples of x that are the maximum
+of the samples within minSep of them, in decreasing order of their values.
+Of equal samples within minSep the first is selected, so that the selected
+samples are at least minSep apart. Fewer than k indices are returned if x has
+fewer such samples. The time complexity is O(len(x) log k).
+*/
+func FindTopK[T Float](x []T, k, minSep int) []int {
+	if k <= 0 || len(x) == 0 {
+		return []int{}
+	}
+	if minSep < 1 {
+		minSep = 1
+	}
+	h := &topKHeap[T]{x: x}
+	// window holds the indices of the sliding window [i-minSep+1, i+minSep-1] in
+	// decreasing order of their values and increasing order of equal values
+	window := make([]int, 0, 2*minSep)
+	for r := 0; r < len(x)+minSep-1; r++ {
+		if r < len(x) {
+			for len(window) > 0 && x[window[len(window)-1]] < x[r] {
+				window = window[:len(window)-1]
+			}
+			window = append(window, r)
+		}
+		i := r - minSep + 1
+		if i < 0 {
+			continue
+		}
+		for window[0] < i-minSep+1 {
+			window = window[1:]
+		}
+		if window[0] != i {
+			continue
+		}
+		if h.Len() < k {
+			heap.Push(h, i)
+		} else if h.less(h.idx[0], i) {
+			h.idx[0] = i
+			heap.Fix(h, 0)
+		}
+	}
+	top := make([]int, h.Len())
+	for j := len(top) - 1; j >= 0; j-- {
+		top[j] = heap.Pop(h).(int)
+	}
+	return top
+}
+
+// topKHeap is a min-heap of indices of x ordered by the values of x
+type topKHeap[T Float] struct {
+	x   []T
+	idx []int
+}
+
+// less returns true if sample i ranks below sample j: smaller, or equal and later
+func (h *topKHeap[T]) less(i, j int) bool {
+	if h.x[i] != h.x[j] {
+		return h.x[i] < h.x[j]
+	}
+	return i > j
+}
+
+func (h *topKHeap[T]) Len() int           { return len(h.idx) }
+func (h *topKHeap[T]) Less(i, j int) bool { return h.less(h.idx[i], h.idx[j]) }
+func (h *topKHeap[T]) Swap(i, j int)      { h.idx[i], h.idx[j] = h.idx[j], h.idx[i] }
+func (h *topKHeap[T]) Push(i interface{}) { h.idx = append(h.idx, i.(int)) }
+func (h *topKHeap[T]) Pop() interface{} {
+	i := h.idx[len(h.idx)-1]
+	h.idx = h.idx[:len(h.idx)-1]
+	return i
+}
+
 /*
 Float32ToFloat64 returns a copy of x with type []float64
 */
 func Float32ToFloat64(x []float32) []float64 {
-	y := make([]float64, len(x))
-	for i, f := range x {
-		y[i] = float64(f)
-	}
-	return y
+	return Convert[float64](x)
 }
 
 func IsPowerOf2(x int) bool {
@@ -166,20 +430,40 @@ func IsPowerOf2(x int) bool {
 LoadFloats reads a text file containing one float per line.
 */
 func LoadFloats(fname string) []float64 {
-	data, err := ioutil.ReadFile(fname)
+	x, err := LoadFloatsErr(fname)
 	if err != nil {
 		panic(err)
 	}
-	rdr := bufio.NewReader(bytes.NewBuffer(data))
+	return x
+}
+
+/*
+LoadFloatsErr returns LoadFloats(fname) or the error reading or parsing the file.
+*/
+func LoadFloatsErr(fname string) ([]float64, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadFloatsFrom(f)
+}
+
+/*
+LoadFloatsFrom returns the floats read from r, which contains one float per
+line, or the error reading or parsing r.
+*/
+func LoadFloatsFrom(r io.Reader) ([]float64, error) {
+	scanner := bufio.NewScanner(r)
 	x := make([]float64, 0, 1024)
-	for s, err := rdr.ReadString('\n'); err == nil; s, err = rdr.ReadString('\n') {
-		f, err := strconv.ParseFloat(strings.TrimSuffix(s, "\n"), 64)
+	for scanner.Scan() {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(scanner.Text(), "\r"), 64)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		x = append(x, f)
 	}
-	return x
+	return x, scanner.Err()
 }
 
 // Log2 returns the integer log base 2 of n.
@@ -189,30 +473,29 @@ func Log2(n int) int {
 }
 
 /*
-LowpassFilterAll returns LowpassFilter(x) for all x in xs.
+LowpassFilterAll returns LowpassFilter(x) for all x in xs. See WithWorkers.
 */
-func LowpassFilterAll(xs [][]float64, alpha float64) [][]float64 {
-	ys := make([][]float64, len(xs))
-	for i, x := range xs {
-		ys[i] = LowpassFilter(x, alpha)
-	}
-	return ys
+func LowpassFilterAll[T Float](xs [][]T, alpha float64, opts ...Option) [][]T {
+	return mapAll(xs, getOptions(opts), func(x []T) []T {
+		return LowpassFilter(x, alpha)
+	})
 }
 
 /*
 LowpassFilter returns x filtered by alpha
 */
-func LowpassFilter(x []float64, alpha float64) []float64 {
-	y := make([]float64, len(x))
-	y[0] = alpha * x[0]
+func LowpassFilter[T Float](x []T, alpha float64) []T {
+	y := make([]T, len(x))
+	a := T(alpha)
+	y[0] = a * x[0]
 	for i := 1; i < len(x); i++ {
-		y[i] = y[i-1] + alpha*(x[i]-y[i-1])
+		y[i] = y[i-1] + a*(x[i]-y[i-1])
 	}
 	return y
 }
 
 // Max returns the maximum value of the elements of x
-func Max(x []float64) float64 {
+func Max[T Float](x []T) T {
 	max := x[0]
 	for _, f := range x {
 		if f > max {
@@ -233,63 +516,177 @@ func MaxInt(x []int) int {
 	return max
 }
 
+/*
+MinMaxScale returns x scaled linearly to the range [0,1]. If all elements of x
+are equal the result is 0.
+*/
+func MinMaxScale[T Float](x []T) []T {
+	y := make([]T, len(x))
+	if len(x) == 0 {
+		return y
+	}
+	min, _ := FindMin(x)
+	max, _ := FindMax(x)
+	rng := max - min
+	if rng == 0 {
+		return y
+	}
+	for i, f := range x {
+		y[i] = (f - min) / rng
+	}
+	return y
+}
+
+// Alignment selects the position of a sliding window relative to its output sample
+type Alignment int
+
+const (
+	// Centered windows are centred on the output sample
+	Centered Alignment = iota
+	// Causal windows end at the output sample
+	Causal
+)
+
 /*
 MovAvg returns the moving average for each x[i], given by sum(x[i-w:i+w])/(2w)
+for w <= i < len(x)-w, and 0 for the other elements. The average is computed
+in O(n) by a running sum.
+The function panics if w < 1.
 */
-func MovAvg(x []float64, w int) []float64 {
-	y := make([]float64, len(x))
-	for i := w; i < len(x)-w; i++ {
-		y[i] = Sum(x[i-w:i+w]) / float64(2*w)
+func MovAvg[T Float](x []T, w int) []T {
+	return MovAvgAligned(x, w, Centered)
+}
+
+/*
+MovAvgAligned returns the moving average of x over windows of 2w samples
+aligned to each x[i] by align:
+
+	Centered: y[i] = sum(x[i-w:i+w])/(2w)  for w <= i < len(x)-w
+	Causal:   y[i] = sum(x[i-2w+1:i+1])/(2w)  for 2w-1 <= i < len(x)
+
+The other elements of y are 0.
+The function panics if w < 1.
+*/
+func MovAvgAligned[T Float](x []T, w int, align Alignment) []T {
+	if w < 1 {
+		panic(fmt.Errorf("%w: w = %d", ErrArgument, w))
+	}
+	y := make([]T, len(x))
+	// y[i] is the average of x[i-offset : i-offset+2w]
+	offset, end := w, len(x)-w
+	if align == Causal {
+		offset, end = 2*w-1, len(x)
+	}
+	if end <= offset {
+		return y
+	}
+	sum := 0.0
+	for _, f := range x[:2*w] {
+		sum += float64(f)
+	}
+	for i := offset; i < end; i++ {
+		y[i] = T(sum / float64(2*w))
+		if start := i - offset; start+2*w < len(x) {
+			sum += float64(x[start+2*w]) - float64(x[start])
+		}
 	}
 	return y
 }
 
 /*
-Multiplex returns on vector with the element of vs interleaved
+Mul returns the element-wise product of x and y.
+The function panics if len(x) != len(y).
+*/
+func Mul[T Float](x, y []T) []T {
+	return MulTo(make([]T, len(x)), x, y)
+}
+
+/*
+MulTo stores the element-wise product of x and y in dst and returns dst. dst
+may be x or y. The function panics if the lengths of dst, x and y differ.
+*/
+func MulTo[T Float](dst, x, y []T) []T {
+	checkDst2(dst, x, y)
+	for i := range x {
+		dst[i] = x[i] * y[i]
+	}
+	return dst
+}
+
+/*
+MulS returns x*s where x is a vector and s a scalar.
+*/
+func MulS[T Float](x []T, s T) []T {
+	return MulSTo(make([]T, len(x)), x, s)
+}
+
+/*
+MulSTo stores x*s in dst and returns dst. dst may be x.
+The function panics if len(dst) != len(x).
+*/
+func MulSTo[T Float](dst, x []T, s T) []T {
+	checkDst(dst, x)
+	for i := range x {
+		dst[i] = x[i] * s
+	}
+	return dst
+}
+
+/*
+Multiplex returns on vector with the element of vs interleaved.
+Channels shorter than the longest channel are padded with zeros.
+See Demultiplex.
 */
 func Multiplex(channels [][]float64) []float64 {
 	numChans := len(channels)
-	chanLen := len(channels[0])
+	chanLen := maxLen(channels)
 	buf := make([]float64, numChans*chanLen)
-	for i := 0; i < chanLen; i++ {
-		k := i * numChans
-		for j := 0; j < numChans; j++ {
-			buf[k+j] = channels[j][i]
+	for j, ch := range channels {
+		for i, f := range ch {
+			buf[i*numChans+j] = f
 		}
 	}
 	return buf
 }
 
 // Normalise returns x/max(x)
-func Normalise(x []float64) []float64 {
-	x1 := make([]float64, len(x))
-	sum := Max(x)
-	for i, f := range x {
-		x1[i] = f / sum
-	}
-	return x1
+func Normalise[T Float](x []T) []T {
+	return NormaliseTo(make([]T, len(x)), x)
 }
 
-// Normalise returns x/max(x) for all x in xs
-func NormaliseAll(xs [][]float64) [][]float64 {
-	x1 := make([][]float64, len(xs))
-	for i, x := range xs {
-		x1[i] = Normalise(x)
-	}
-	return x1
+/*
+NormaliseTo stores x/max(x) in dst and returns dst. dst may be x.
+The function panics if len(dst) != len(x).
+*/
+func NormaliseTo[T Float](dst, x []T) []T {
+	return DivSTo(dst, x, Max(x))
+}
+
+// NormaliseAll returns x/max(x) for all x in xs. See WithWorkers.
+func NormaliseAll[T Float](xs [][]T, opts ...Option) [][]T {
+	return mapAll(xs, getOptions(opts), Normalise[T])
 }
 
 // Pow2 returns 2^x.
 // The function panics if x < 0
 func Pow2(x int) int {
+	pw, err := Pow2Err(x)
+	if err != nil {
+		panic(err)
+	}
+	return pw
+}
+
+// Pow2Err returns Pow2(x) or an error wrapping ErrArgument if x < 0.
+func Pow2Err(x int) (int, error) {
 	if x < 0 {
-		panic(fmt.Sprintf("X = %d", x))
+		return 0, fmt.Errorf("%w: X = %d", ErrArgument, x)
 	}
 	pw := 1
 	for i := 1; i <= x; i++ {
 		pw *= 2
 	}
-	return pw
+	return pw, nil
 }
 
 // Range returns an interger range 0:1:n-1
@@ -303,78 +700,143 @@ func Range(n int) []int {
 
 /*
 RemoveAvgAllZ removes the average of all vectors x in xs. The minimum value
-of any x[i] is 0.
+of any x[i] is 0. See WithWorkers.
 */
-func RemoveAvgAllZ(xs [][]float64) [][]float64 {
-	xs1 := make([][]float64, len(xs))
-	for i, x := range xs {
-		xs1[i] = RemoveAvg(x)
-	}
-	return xs1
+func RemoveAvgAllZ[T Float](xs [][]T, opts ...Option) [][]T {
+	return mapAll(xs, getOptions(opts), RemoveAvg[T])
+}
+
+// RemoveAvgZ returns x[i] = x[i]-sum(x)/len(x) or 0 if x[i]-sum(x)/len(x) < 0.
+// See Detrend for the unclamped version.
+func RemoveAvg[T Float](x []T) []T {
+	return RemoveAvgTo(make([]T, len(x)), x)
 }
 
-// RemoveAvgZ returns x[i] = x[i]-sum(x)/len(x) or 0 if x[i]-sum(x)/len(x) < 0
-func RemoveAvg(x []float64) []float64 {
-	x1 := make([]float64, len(x))
-	avg := Sum(x) / float64(len(x))
+/*
+RemoveAvgTo stores RemoveAvg(x) in dst and returns dst. dst may be x.
+The function panics if len(dst) != len(x).
+*/
+func RemoveAvgTo[T Float](dst, x []T) []T {
+	checkDst(dst, x)
+	avg := Average(x)
 	for i, f := range x {
-		x1[i] = f - avg
-		if x1[i] < 0 {
-			x1[i] = 0
+		dst[i] = f - avg
+		if dst[i] < 0 {
+			dst[i] = 0
 		}
 	}
-	return x1
+	return dst
+}
+
+// Scale multiplies x by s in place and returns x
+func Scale[T Float](x []T, s T) []T {
+	return MulSTo(x, x, s)
 }
 
-// Smooth smoothts x: x[i] = sum(x[i-wdw:i+wdw])/(2*wdw)
+/*
+Smooth smoothts x in place: x[i] = sum(x[i-wdw:i+wdw])/(2*wdw), where
+x[i-wdw:i] are the already smoothed values. x[:wdw] is set to 0. Smooth runs in
+O(n) by a running sum.
+*/
 func Smooth(x []float64, wdw int) {
-	for i := 0; i < wdw; i++ {
+	for i := 0; i < wdw && i < len(x); i++ {
 		x[i] = 0
 	}
+	if len(x)-wdw <= wdw {
+		return
+	}
+	sum := Sum(x[:2*wdw])
 	for i := wdw; i < len(x)-wdw; i++ {
-		x[i] = Sum(x[i-wdw:i+wdw]) / float64((2 * wdw))
+		old := x[i]
+		x[i] = sum / float64(2*wdw)
+		// Slide the window to x[i+1-wdw : i+1+wdw]
+		sum += x[i] - old - x[i-wdw] + x[i+wdw]
 	}
 }
 
+/*
+Standardize returns x with zero mean and unit variance. If x has zero variance
+the result is only zero mean. See Std.
+*/
+func Standardize[T Float](x []T) []T {
+	y := Detrend(x, Constant)
+	if std := Std(x); std > 0 {
+		DivSTo(y, y, std)
+	}
+	return y
+}
+
 /*
 Sub returns x - y. The function panics if len(x) != len(y).
 */
-func Sub(x, y []float64) []float64 {
-	if len(x) != len(y) {
-		panic("len(x) != len(y)")
+func Sub[T Float](x, y []T) []T {
+	x1, err := SubErr(x, y)
+	if err != nil {
+		panic(err)
 	}
-	x1 := make([]float64, len(x))
+	return x1
+}
+
+// SubErr returns Sub(x, y) or an error wrapping ErrLength if len(x) != len(y).
+func SubErr[T Float](x, y []T) ([]T, error) {
+	if err := checkSameLen(x, y); err != nil {
+		return nil, err
+	}
+	return SubTo(make([]T, len(x)), x, y), nil
+}
+
+/*
+SubTo stores x - y in dst and returns dst. dst may be x or y.
+The function panics if the lengths of dst, x and y differ.
+*/
+func SubTo[T Float](dst, x, y []T) []T {
+	checkDst2(dst, x, y)
 	for i := range x {
-		x1[i] = x[i] - y[i]
+		dst[i] = x[i] - y[i]
 	}
-	return x1
+	return dst
 }
 
 // Sum returns the sum of the elements of the vector x
-func Sum(x []float64) float64 {
+func Sum[T Float](x []T) T {
 	sum := 0.0
 	for _, f := range x {
-		sum += f
+		sum += float64(f)
 	}
-	return sum
+	return T(sum)
 }
 
 // SumVectors returns the sum of the vectors in X.
 // The function panics if all vectors don't have the same length
-func SumVectors(X [][]float64) []float64 {
+func SumVectors[T Float](X [][]T) []T {
+	sum, err := SumVectorsErr(X)
+	if err != nil {
+		panic(err)
+	}
+	return sum
+}
+
+/*
+SumVectorsErr returns SumVectors(X) or an error wrapping ErrLength if X is
+empty or all vectors don't have the same length.
+*/
+func SumVectorsErr[T Float](X [][]T) ([]T, error) {
+	if len(X) == 0 {
+		return nil, fmt.Errorf("%w: X is empty", ErrLength)
+	}
 	N := len(X[0])
 	for i, x := range X {
 		if len(x) != N {
-			panic(fmt.Sprintf("N=%d but len(X[%d]=%d", N, i, len(x)))
+			return nil, fmt.Errorf("%w: N=%d but len(X[%d]=%d", ErrLength, N, i, len(x))
 		}
 	}
-	sum := make([]float64, N)
+	sum := make([]T, N)
 	for i := 0; i < N; i++ {
 		for j := range X {
 			sum[i] += X[j][i]
 		}
 	}
-	return sum
+	return sum, nil
 }
 
 func ToFloat(x []int) []float64 {
@@ -391,15 +853,27 @@ The range of x is [-1.0,1.0].
 The function panics if bitsPerSample is not one of 8,16,32.
 */
 func ToInt(x []float64, bitsPerSample int) []int {
-	y := make([]int, len(x))
+	y, err := ToIntErr(x, bitsPerSample)
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
+
+/*
+ToIntErr returns ToInt(x, bitsPerSample) or an error wrapping ErrArgument if
+bitsPerSample is not one of 8,16,32.
+*/
+func ToIntErr(x []float64, bitsPerSample int) ([]int, error) {
 	if bitsPerSample != 8 && bitsPerSample != 16 && bitsPerSample != 32 {
-		panic(fmt.Sprintf("Invalid bitsPerSample %d", bitsPerSample))
+		return nil, fmt.Errorf("%w: Invalid bitsPerSample %d", ErrArgument, bitsPerSample)
 	}
+	y := make([]int, len(x))
 	max := float64(int(1)<<bitsPerSample - 1)
 	for i, f := range x {
 		y[i] = int(f * max)
 	}
-	return y
+	return y, nil
 }
 
 func ToIntS(x float64, bitsPerSample int) int {
@@ -464,63 +938,285 @@ func ivecContain(x []int, v int) bool {
 
 // WriteAllDataFile writes each xs[i] in xs to a test file `fname_i.txt`
 func WriteAllDataFile(xs [][]float64, fname string) {
+	if err := WriteAllDataFileErr(xs, fname); err != nil {
+		panic(err)
+	}
+}
+
+// WriteAllDataFileErr is WriteAllDataFile returning the first write error.
+func WriteAllDataFileErr(xs [][]float64, fname string) error {
 	for i, xs := range xs {
-		WriteDataFile(xs, fmt.Sprintf("%s_%d", fname, i))
+		if err := WriteDataFileErr(xs, fmt.Sprintf("%s_%d", fname, i)); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // WriteDataFile writes x to a text file `fname.txt`
 func WriteDataFile(x []float64, fname string) {
+	if err := WriteDataFileErr(x, fname); err != nil {
+		panic(err)
+	}
+}
+
+// WriteDataFileErr is WriteDataFile returning the write error.
+func WriteDataFileErr(x []float64, fname string) error {
 	buf := new(bytes.Buffer)
+	WriteDataTo(buf, x)
+	return myioutil.WriteFile(fname+".txt", buf.Bytes())
+}
+
+// WriteDataTo writes x to w in the format of WriteDataFile and returns the write error.
+func WriteDataTo(w io.Writer, x []float64) error {
+	bw := bufio.NewWriter(w)
 	for _, f := range x {
-		fmt.Fprintf(buf, "%f\n", f)
-	}
-	if err := myioutil.WriteFile(fname+".txt", buf.Bytes()); err != nil {
-		panic(err)
+		fmt.Fprintf(bw, "%f\n", f)
 	}
+	return bw.Flush()
 }
 
 // WriteIntDataFile writes x to a text file `fname.txt`
 func WriteIntDataFile(x []int, fname string) {
+	if err := WriteIntDataFileErr(x, fname); err != nil {
+		panic(err)
+	}
+}
+
+// WriteIntDataFileErr is WriteIntDataFile returning the write error.
+func WriteIntDataFileErr(x []int, fname string) error {
 	buf := new(bytes.Buffer)
+	WriteIntDataTo(buf, x)
+	return myioutil.WriteFile(fname+".txt", buf.Bytes())
+}
+
+// WriteIntDataTo writes x to w in the format of WriteIntDataFile and returns the write error.
+func WriteIntDataTo(w io.Writer, x []int) error {
+	bw := bufio.NewWriter(w)
 	for _, f := range x {
-		fmt.Fprintf(buf, "%d\n", f)
-	}
-	if err := myioutil.WriteFile(fname+".txt", buf.Bytes()); err != nil {
-		panic(err)
+		fmt.Fprintf(bw, "%d\n", f)
 	}
+	return bw.Flush()
 }
 
 /*
 WriteIntMatrixDataFile writes an integer matrix to a text file `fname.csv`
 */
 func WriteIntMatrixDataFile(x [][]int, fname string) {
+	if err := WriteIntMatrixDataFileErr(x, fname); err != nil {
+		panic(err)
+	}
+}
+
+// WriteIntMatrixDataFileErr is WriteIntMatrixDataFile returning the write error.
+func WriteIntMatrixDataFileErr(x [][]int, fname string) error {
 	buf := new(bytes.Buffer)
+	WriteIntMatrixDataTo(buf, x)
+	return myioutil.WriteFile(fname+".csv", buf.Bytes())
+}
+
+// WriteIntMatrixDataTo writes x to w in the format of WriteIntMatrixDataFile and returns the write error.
+func WriteIntMatrixDataTo(w io.Writer, x [][]int) error {
+	bw := bufio.NewWriter(w)
 	for _, row := range x {
 		for i, col := range row {
 			if i > 0 {
-				fmt.Fprint(buf, ",")
+				fmt.Fprint(bw, ",")
 			}
-			fmt.Fprintf(buf, "%d", col)
+			fmt.Fprintf(bw, "%d", col)
 		}
-		fmt.Fprintln(buf)
-	}
-	if err := myioutil.WriteFile(fname+".csv", buf.Bytes()); err != nil {
-		panic(err)
+		fmt.Fprintln(bw)
 	}
+	return bw.Flush()
 }
 
+/*
+xcorrFFTThreshold is the number of multiplications N*maxDelay above which Xcorr
+computes the cross correlation by FFT.
+*/
+const xcorrFFTThreshold = 1 << 20
+
 /*
 Xcorr returns the cross correlation of x with y for maxDelay.
+Large correlations are computed by XcorrFFT.
 */
 func Xcorr(x, y []float64, maxDelay int) (corr []float64) {
 	N := len(x)
-	corr = make([]float64, maxDelay)
+	if N*maxDelay > xcorrFFTThreshold {
+		return XcorrFFT(x, y, maxDelay)
+	}
+	corr, _ = xcorr(context.Background(), x, y, maxDelay)
+	return
+}
+
+/*
+XcorrContext returns Xcorr(x, y, maxDelay) or ctx.Err() if ctx is done before
+the cross correlation is complete. It returns an error wrapping ErrLength if
+len(y) < len(x) or ErrArgument if maxDelay < 0.
+*/
+func XcorrContext(ctx context.Context, x, y []float64, maxDelay int) ([]float64, error) {
+	if len(y) < len(x) {
+		return nil, fmt.Errorf("%w: len(y) (%d) < len(x) (%d)", ErrLength, len(y), len(x))
+	}
+	if maxDelay < 0 {
+		return nil, fmt.Errorf("%w: maxDelay = %d", ErrArgument, maxDelay)
+	}
+	if len(x)*maxDelay > xcorrFFTThreshold {
+		return xcorrFFT(ctx, x, y, maxDelay)
+	}
+	return xcorr(ctx, x, y, maxDelay)
+}
+
+// xcorr returns the cross correlation of x with y computed directly
+func xcorr(ctx context.Context, x, y []float64, maxDelay int) ([]float64, error) {
+	N := len(x)
+	corr := make([]float64, maxDelay)
 	for k := 0; k < maxDelay; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		for n := 0; n < N-k; n++ {
 			corr[k] += x[n] * y[n+k]
 		}
 		corr[k] /= float64(N)
 	}
-	return
+	return corr, nil
+}
+
+/*
+XcorrFFT returns the same cross correlation of x with y for maxDelay as Xcorr,
+computed in O(N log N) by FFT.
+The function panics if len(y) < len(x).
+*/
+func XcorrFFT(x, y []float64, maxDelay int) []float64 {
+	corr, err := XcorrFFTErr(x, y, maxDelay)
+	if err != nil {
+		panic(err)
+	}
+	return corr
+}
+
+/*
+XcorrFFTErr returns XcorrFFT(x, y, maxDelay) or an error wrapping ErrLength if
+len(y) < len(x) or ErrArgument if maxDelay < 0.
+*/
+func XcorrFFTErr(x, y []float64, maxDelay int) ([]float64, error) {
+	return xcorrFFT(context.Background(), x, y, maxDelay)
+}
+
+func xcorrFFT(ctx context.Context, x, y []float64, maxDelay int) ([]float64, error) {
+	N := len(x)
+	if len(y) < N {
+		return nil, fmt.Errorf("%w: len(y) (%d) < len(x) (%d)", ErrLength, len(y), N)
+	}
+	if maxDelay < 0 {
+		return nil, fmt.Errorf("%w: maxDelay = %d", ErrArgument, maxDelay)
+	}
+	M := NextPow2(N + maxDelay)
+	X, Y := make([]complex128, M), make([]complex128, M)
+	for n := 0; n < N; n++ {
+		X[n], Y[n] = complex(x[n], 0), complex(y[n], 0)
+	}
+	if err := radix2Context(ctx, X, false); err != nil {
+		return nil, err
+	}
+	if err := radix2Context(ctx, Y, false); err != nil {
+		return nil, err
+	}
+	for i := range X {
+		X[i] = cmplx.Conj(X[i]) * Y[i]
+	}
+	if err := radix2Context(ctx, X, true); err != nil {
+		return nil, err
+	}
+	corr := make([]float64, maxDelay)
+	for k := 0; k < maxDelay && k < N; k++ {
+		corr[k] = real(X[k]) / float64(M) / float64(N)
+	}
+	return corr, nil
+}
+
+/*
+XcorrNormalized returns the normalized cross correlation of x with y for the
+lags -maxDelay to maxDelay:
+
+	c[maxDelay+lag] = sum_n (x[n]-avg(x))(y[n+lag]-avg(y)) / sqrt(Ex*Ey)
+
+where Ex and Ey are the energies of x and y with their averages removed.
+Element maxDelay of the result is lag 0. A positive lag means that y is delayed
+with respect to x. The coefficients are in [-1,1]. If x or y has zero energy
+all coefficients are 0.
+*/
+func XcorrNormalized(x, y []float64, maxDelay int) []float64 {
+	x0, y0 := Sub(x, constant(len(x), Average(x))), Sub(y, constant(len(y), Average(y)))
+	corr := make([]float64, 2*maxDelay+1)
+	energy := math.Sqrt(Dot(x0, x0) * Dot(y0, y0))
+	if energy == 0 {
+		return corr
+	}
+	M := NextPow2(len(x) + len(y))
+	X, Y := make([]complex128, M), make([]complex128, M)
+	for n, f := range x0 {
+		X[n] = complex(f, 0)
+	}
+	for n, f := range y0 {
+		Y[n] = complex(f, 0)
+	}
+	radix2(X, false)
+	radix2(Y, false)
+	for i := range X {
+		X[i] = cmplx.Conj(X[i]) * Y[i]
+	}
+	radix2(X, true)
+	for lag := -maxDelay; lag <= maxDelay; lag++ {
+		if lag >= len(y) || -lag >= len(x) {
+			continue
+		}
+		k := lag
+		if k < 0 {
+			k += M
+		}
+		corr[maxDelay+lag] = real(X[k]) / float64(M) / energy
+	}
+	return corr
+}
+
+// checkSameLen returns an error wrapping ErrLength if len(x) != len(y)
+func checkSameLen[T Float](x, y []T) error {
+	if len(x) != len(y) {
+		return fmt.Errorf("%w: len(x) (%d) != len(y) (%d)", ErrLength, len(x), len(y))
+	}
+	return nil
+}
+
+// checkDst panics if len(dst) != len(x)
+func checkDst[T Float](dst, x []T) {
+	if len(dst) != len(x) {
+		panic(fmt.Errorf("%w: len(dst) (%d) != len(x) (%d)", ErrLength, len(dst), len(x)))
+	}
+}
+
+// checkDst2 panics if the lengths of dst, x and y differ
+func checkDst2[T Float](dst, x, y []T) {
+	if err := checkSameLen(x, y); err != nil {
+		panic(err)
+	}
+	checkDst(dst, x)
+}
+
+// checkFrames returns an error wrapping ErrArgument if frameLen < minLen or hop < 1
+func checkFrames(frameLen, hop, minLen int) error {
+	if frameLen < minLen || hop < 1 {
+		return fmt.Errorf("%w: Invalid frameLen %d or hop %d", ErrArgument, frameLen, hop)
+	}
+	return nil
+}
+
+// constant returns a vector of length n with all elements equal to c
+func constant(n int, c float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = c
+	}
+	return x
 }
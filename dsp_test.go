@@ -0,0 +1,1588 @@
+package godsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/goccmack/godsp/internal/benchdata"
+	"github.com/goccmack/godsp/internal/golden"
+)
+
+func dft(x []complex128) []complex128 {
+	N := len(x)
+	X := make([]complex128, N)
+	for k := range X {
+		for n := range x {
+			X[k] += x[n] * cmplx.Rect(1, -2*math.Pi*float64(k*n)/float64(N))
+		}
+	}
+	return X
+}
+
+func TestFFT(t *testing.T) {
+	for _, N := range []int{1, 2, 8, 64, 3, 100, 127} {
+		x := make([]complex128, N)
+		for i := range x {
+			x[i] = complex(math.Sin(float64(i)), math.Cos(float64(i*i)))
+		}
+		X, want := FFT(x), dft(x)
+		for k := range X {
+			if cmplx.Abs(X[k]-want[k]) > 1e-9 {
+				t.Fatalf("N=%d: X[%d] = %v, want %v", N, k, X[k], want[k])
+			}
+		}
+		x1 := IFFT(X)
+		for i := range x {
+			if cmplx.Abs(x1[i]-x[i]) > 1e-9 {
+				t.Fatalf("N=%d: x1[%d] = %v, want %v", N, i, x1[i], x[i])
+			}
+		}
+	}
+}
+
+func TestXcorrFFT(t *testing.T) {
+	N, maxDelay := 1000, 100
+	x, y := make([]float64, N), make([]float64, N)
+	for i := range x {
+		x[i] = math.Sin(float64(i) / 7)
+		y[i] = math.Sin(float64(i-20) / 7)
+	}
+	c, c1 := Xcorr(x, y, maxDelay), XcorrFFT(x, y, maxDelay)
+	for k := range c {
+		if math.Abs(c[k]-c1[k]) > 1e-12 {
+			t.Fatalf("c1[%d] = %f, want %f", k, c1[k], c[k])
+		}
+	}
+}
+
+func TestXcorrNormalized(t *testing.T) {
+	x := make([]float64, 500)
+	for i := range x {
+		x[i] = math.Sin(float64(i*i) / 300)
+	}
+	y := make([]float64, len(x))
+	copy(y[20:], x)
+	c := XcorrNormalized(x, y, 50)
+	if _, i := FindMax(c); i-50 != 20 {
+		t.Errorf("lag = %d, want 20", i-50)
+	}
+	for _, f := range c {
+		if f < -1 || f > 1 {
+			t.Fatalf("coefficient %f is not in [-1,1]", f)
+		}
+	}
+}
+
+func TestAutocorr(t *testing.T) {
+	x := make([]float64, 300)
+	for i := range x {
+		x[i] = math.Sin(float64(i) / 5)
+	}
+	r := Autocorr(x, 50, Unbiased)
+	for k := range r {
+		want := 0.0
+		for n := 0; n < len(x)-k; n++ {
+			want += x[n] * x[n+k]
+		}
+		want /= float64(len(x) - k)
+		if math.Abs(r[k]-want) > 1e-12 {
+			t.Fatalf("r[%d] = %f, want %f", k, r[k], want)
+		}
+	}
+}
+
+func TestConv(t *testing.T) {
+	x, h := make([]float64, 1000), make([]float64, 100)
+	for i := range x {
+		x[i] = math.Sin(float64(i) / 5)
+	}
+	for i := range h {
+		h[i] = math.Exp(-float64(i) / 10)
+	}
+	y, want := Conv(x, h), convDirect(x, h)
+	if len(y) != len(x)+len(h)-1 {
+		t.Fatalf("len(y) = %d, want %d", len(y), len(x)+len(h)-1)
+	}
+	for i := range y {
+		if math.Abs(y[i]-want[i]) > 1e-9 {
+			t.Fatalf("y[%d] = %f, want %f", i, y[i], want[i])
+		}
+	}
+	yc := ConvCircular(x[:10], []float64{0, 1})
+	for i := range yc {
+		if math.Abs(yc[i]-x[(i+9)%10]) > 1e-12 {
+			t.Fatalf("yc[%d] = %f, want %f", i, yc[i], x[(i+9)%10])
+		}
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = 0.5 * math.Cos(2*math.Pi*50*float64(i)/1000)
+	}
+	for i, e := range Envelope(x) {
+		if math.Abs(e-0.5) > 1e-9 {
+			t.Fatalf("env[%d] = %f, want 0.5", i, e)
+		}
+	}
+}
+
+func TestInterp(t *testing.T) {
+	x := make([]float64, 200)
+	for i := range x {
+		x[i] = math.Sin(float64(i) / 10)
+	}
+	for name, interp := range map[string]Interpolator{
+		"linear": InterpLinear, "cubic": InterpCubic, "sinc": InterpSinc,
+	} {
+		y := InterpTo(x, 399, interp)
+		for i := 40; i < len(y)-40; i++ {
+			want := math.Sin(float64(i) / 20)
+			if math.Abs(y[i]-want) > 2e-3 {
+				t.Fatalf("%s: y[%d] = %f, want %f", name, i, y[i], want)
+			}
+		}
+	}
+}
+
+func TestGoertzel(t *testing.T) {
+	x := make([]float64, 256)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * 8 * float64(i) / 256)
+	}
+	X := FFTReal(x)
+	want := real(X[8])*real(X[8]) + imag(X[8])*imag(X[8])
+	if p := Goertzel(x, 8, 256); math.Abs(p-want) > 1e-6 {
+		t.Errorf("p = %f, want %f", p, want)
+	}
+}
+
+func TestPitchYIN(t *testing.T) {
+	sampleRate := 8000.0
+	x := make([]float64, 4000)
+	for i := range x[:2000] {
+		ph := 2 * math.Pi * 220 * float64(i) / sampleRate
+		x[i] = math.Sin(ph) + 0.5*math.Sin(2*ph)
+	}
+	f0 := PitchYIN(x, sampleRate, 512, 256)
+	if len(f0) != 14 {
+		t.Fatalf("len(f0) = %d", len(f0))
+	}
+	for i, f := range f0 {
+		switch {
+		case (i+2)*256 <= 2000 && math.Abs(f-220) > 1:
+			t.Errorf("f0[%d] = %f, want 220", i, f)
+		case i*256 >= 2000 && f != 0:
+			t.Errorf("f0[%d] = %f, want 0", i, f)
+		}
+	}
+}
+
+func TestDTW(t *testing.T) {
+	seq := func(v ...float64) [][]float64 {
+		s := make([][]float64, len(v))
+		for i, f := range v {
+			s[i] = []float64{f}
+		}
+		return s
+	}
+	x, y := seq(0, 1, 2, 3, 2, 0), seq(0, 0, 1, 2, 3, 3, 2, 0)
+	cost, path := DTW(x, y, nil)
+	if cost != 0 {
+		t.Errorf("cost = %f", cost)
+	}
+	if path[0] != [2]int{0, 0} || path[len(path)-1] != [2]int{5, 7} {
+		t.Errorf("path = %v", path)
+	}
+	for _, p := range path {
+		if x[p[0]][0] != y[p[1]][0] {
+			t.Errorf("x[%d] not aligned with y[%d]", p[0], p[1])
+		}
+	}
+	if cost, _ := DTWBand(x, seq(3, 2, 1, 0, 0, 0), Manhattan, 0); cost != 3+1+1+3+2+0 {
+		t.Errorf("band cost = %f", cost)
+	}
+}
+
+func TestFrameStats(t *testing.T) {
+	x := []float64{1, -1, 1, -1, 2, 2, 2, 2}
+	if z := ZCR(x); math.Abs(z-4.0/7) > 1e-12 {
+		t.Errorf("ZCR = %f", z)
+	}
+	stats := FrameStats(x, 4, 2)
+	want := []FrameStat{
+		{Energy: 4, RMS: 1, ZCR: 1, Peak: 1},
+		{Energy: 10, RMS: math.Sqrt(2.5), ZCR: 2.0 / 3, Peak: 2},
+		{Energy: 16, RMS: 2, ZCR: 0, Peak: 2},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("len(stats) = %d", len(stats))
+	}
+	for i, s := range stats {
+		if s != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, s, want[i])
+		}
+	}
+}
+
+func TestErr(t *testing.T) {
+	if _, err := SubErr([]float64{1}, []float64{1, 2}); !errors.Is(err, ErrLength) {
+		t.Errorf("SubErr: %v", err)
+	}
+	if _, err := DownSampleErr(make([]float64, 5), 2); !errors.Is(err, ErrLength) {
+		t.Errorf("DownSampleErr: %v", err)
+	}
+	if _, err := Pow2Err(-1); !errors.Is(err, ErrArgument) {
+		t.Errorf("Pow2Err: %v", err)
+	}
+	if _, err := STFTErr(make([]float64, 8), 4, 0, nil); !errors.Is(err, ErrArgument) {
+		t.Errorf("STFTErr: %v", err)
+	}
+	if _, _, _, err := ReadWavFileErr("nonexistent.wav"); err == nil {
+		t.Error("ReadWavFileErr: no error")
+	}
+	if x, err := SubErr([]float64{3}, []float64{1}); err != nil || x[0] != 2 {
+		t.Errorf("SubErr: %v, %v", x, err)
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	x := []float32{1, -2, 3, -4}
+	if s := Sum(Abs(x)); s != 10 {
+		t.Errorf("Sum(Abs(x)) = %f", s)
+	}
+	if d := Dot(x, x); d != 30 {
+		t.Errorf("Dot(x, x) = %f", d)
+	}
+	if m, i := FindMax(Sub(x, DivS(x, 2))); m != 1.5 || i != 2 {
+		t.Errorf("FindMax = %f, %d", m, i)
+	}
+	y := Convert[float64](x)
+	if Sum(y) != -2 {
+		t.Errorf("Convert = %v", y)
+	}
+}
+
+func TestTo(t *testing.T) {
+	x := []float64{-1, 2, -3, 4}
+	AbsTo(x, x)
+	DivSTo(x, x, 2)
+	SubTo(x, x, []float64{0.5, 0.5, 0.5, 0.5})
+	if want := []float64{0, 0.5, 1, 1.5}; !equalFloats(x, want) {
+		t.Errorf("x = %v, want %v", x, want)
+	}
+	dst := make([]float64, 4)
+	if NormaliseTo(dst, x); !equalFloats(dst, []float64{0, 1.0 / 3, 2.0 / 3, 1}) {
+		t.Errorf("NormaliseTo = %v", dst)
+	}
+	if RemoveAvgTo(x, x); !equalFloats(x, []float64{0, 0, 0.25, 0.75}) {
+		t.Errorf("RemoveAvgTo = %v", x)
+	}
+	if n := testing.AllocsPerRun(10, func() { SubTo(dst, dst, x) }); n != 0 {
+		t.Errorf("SubTo allocates %f times", n)
+	}
+}
+
+func equalFloats(x, y []float64) bool {
+	return EqualApprox(x, y, 1e-12)
+}
+
+func TestArithmetic(t *testing.T) {
+	x, y := []float64{1, 2, 3}, []float64{4, 5, 6}
+	if z := Add(x, y); !equalFloats(z, []float64{5, 7, 9}) {
+		t.Errorf("Add = %v", z)
+	}
+	if z := Mul(x, y); !equalFloats(z, []float64{4, 10, 18}) {
+		t.Errorf("Mul = %v", z)
+	}
+	if z := AddS(x, 1); !equalFloats(z, []float64{2, 3, 4}) {
+		t.Errorf("AddS = %v", z)
+	}
+	if z := MulS(x, 2); !equalFloats(z, []float64{2, 4, 6}) {
+		t.Errorf("MulS = %v", z)
+	}
+	if Scale(x, 3); !equalFloats(x, []float64{3, 6, 9}) {
+		t.Errorf("Scale = %v", x)
+	}
+	if Dot(x, y) != 3*4+6*5+9*6 {
+		t.Errorf("Dot = %f", Dot(x, y))
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	xs := make([][]float64, 100)
+	for i := range xs {
+		xs[i] = []float64{float64(-i), float64(i), 1}
+	}
+	serial, parallel := AbsAll(xs), AbsAll(xs, WithWorkers(4))
+	for i := range xs {
+		if !equalFloats(serial[i], parallel[i]) || parallel[i][0] != float64(i) {
+			t.Fatalf("parallel[%d] = %v, serial[%d] = %v", i, parallel[i], i, serial[i])
+		}
+	}
+	sq := ParallelMap(Range(10), 3, func(i int) int { return i * i })
+	for i, s := range sq {
+		if s != i*i {
+			t.Errorf("sq[%d] = %d", i, s)
+		}
+	}
+}
+
+func TestMovAvg(t *testing.T) {
+	x := make([]float64, 50)
+	for i := range x {
+		x[i] = math.Sin(float64(i)) + float64(i%7)
+	}
+	w := 3
+	y, c := MovAvg(x, w), MovAvgAligned(x, w, Causal)
+	for i := range x {
+		want := 0.0
+		if i >= w && i < len(x)-w {
+			want = Sum(x[i-w:i+w]) / float64(2*w)
+		}
+		if math.Abs(y[i]-want) > 1e-12 {
+			t.Errorf("y[%d] = %f, want %f", i, y[i], want)
+		}
+		want = 0
+		if i >= 2*w-1 {
+			want = Sum(x[i-2*w+1:i+1]) / float64(2*w)
+		}
+		if math.Abs(c[i]-want) > 1e-12 {
+			t.Errorf("c[%d] = %f, want %f", i, c[i], want)
+		}
+	}
+	// Reference implementation of Smooth
+	s, ref := append([]float64{}, x...), append([]float64{}, x...)
+	for i := 0; i < w; i++ {
+		ref[i] = 0
+	}
+	for i := w; i < len(ref)-w; i++ {
+		ref[i] = Sum(ref[i-w:i+w]) / float64(2*w)
+	}
+	if Smooth(s, w); !equalFloats(s, ref) {
+		t.Errorf("Smooth = %v, want %v", s, ref)
+	}
+}
+
+func TestRobust(t *testing.T) {
+	x := []float64{1, 2, 3, 100, 5, 6, 7, 8}
+	if y := MedianFilter(x, 3); !equalFloats(y, []float64{1.5, 2, 3, 5, 6, 6, 7, 7.5}) {
+		t.Errorf("MedianFilter = %v", y)
+	}
+	if y := Hampel(x, 5, 3); !equalFloats(y, []float64{1, 2, 3, 5, 5, 6, 7, 8}) {
+		t.Errorf("Hampel = %v", y)
+	}
+}
+
+func TestSavitzkyGolay(t *testing.T) {
+	// A quadratic is reproduced exactly by a polynomial of order 2
+	x := make([]float64, 20)
+	for i := range x {
+		f := float64(i)
+		x[i] = 0.5*f*f - 3*f + 2
+	}
+	if y := SavitzkyGolay(x, 7, 2, 0); !EqualApprox(y, x, 1e-9) {
+		t.Errorf("smoothed = %v", y)
+	}
+	d := SavitzkyGolay(x, 7, 2, 1)
+	for i, f := range d {
+		if math.Abs(f-(float64(i)-3)) > 1e-9 {
+			t.Errorf("d[%d] = %f, want %f", i, f, float64(i)-3)
+		}
+	}
+	// Known coefficients of the 5 point quadratic smoother: (-3, 12, 17, 12, -3)/35
+	x = []float64{0, 0, 0, 0, 35, 0, 0, 0, 0}
+	if y := SavitzkyGolay(x, 5, 2, 0); !EqualApprox(y[2:7], []float64{-3, 12, 17, 12, -3}, 1e-9) {
+		t.Errorf("impulse response = %v", y)
+	}
+}
+
+func TestDetrend(t *testing.T) {
+	x := []float64{1, 3, 5, 7, 9}
+	if y := Detrend(x, Constant); !equalFloats(y, []float64{-4, -2, 0, 2, 4}) {
+		t.Errorf("Detrend(Constant) = %v", y)
+	}
+	if y := Detrend(x, Linear); !equalFloats(y, make([]float64, 5)) {
+		t.Errorf("Detrend(Linear) = %v", y)
+	}
+	if y := Standardize(x); math.Abs(Average(y)) > 1e-12 || math.Abs(Std(y)-1) > 1e-12 {
+		t.Errorf("Standardize = %v", y)
+	}
+	if y := MinMaxScale(x); !equalFloats(y, []float64{0, 0.25, 0.5, 0.75, 1}) {
+		t.Errorf("MinMaxScale = %v", y)
+	}
+}
+
+func TestStats(t *testing.T) {
+	x := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	if v, s := Variance(x), Std(x); v != 4 || s != 2 {
+		t.Errorf("Variance = %f, Std = %f", v, s)
+	}
+	if m := Median(x); m != 4.5 {
+		t.Errorf("Median = %f", m)
+	}
+	if p := Percentile(x, 25); p != 4 {
+		t.Errorf("Percentile(25) = %f", p)
+	}
+	if p := Percentile([]float64{1, 2, 3, 4, 5}, 90); math.Abs(p-4.6) > 1e-12 {
+		t.Errorf("Percentile(90) = %f", p)
+	}
+	if s := Skewness([]float64{1, 2, 3}); s != 0 {
+		t.Errorf("Skewness = %f", s)
+	}
+	if s := Skewness([]float64{0, 0, 0, 1}); s <= 0 {
+		t.Errorf("Skewness = %f", s)
+	}
+	if k := Kurtosis([]float64{-1, 1, -1, 1}); k != -2 {
+		t.Errorf("Kurtosis = %f", k)
+	}
+	if x[0] != 2 || x[7] != 9 {
+		t.Error("x modified")
+	}
+}
+
+func TestRunningStats(t *testing.T) {
+	x := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	var s RunningStats
+	s.PushAll(x)
+	if s.Count() != 8 || s.Mean() != 5 || math.Abs(s.Var()-4) > 1e-12 || s.Min() != 2 || s.Max() != 9 {
+		t.Errorf("stats = %d, %f, %f, %f, %f", s.Count(), s.Mean(), s.Var(), s.Min(), s.Max())
+	}
+	m := NewRunningMovAvg(3)
+	want := []float64{2, 3, 10.0 / 3, 4, 13.0 / 3, 14.0 / 3, 17.0 / 3, 7}
+	for i, f := range x {
+		if a := m.Push(f); math.Abs(a-want[i]) > 1e-12 {
+			t.Errorf("avg[%d] = %f, want %f", i, a, want[i])
+		}
+	}
+}
+
+func TestConvolver(t *testing.T) {
+	x, h := make([]float64, 5000), make([]float64, 300)
+	for i := range x {
+		x[i] = math.Sin(float64(i) * 0.1)
+	}
+	for i := range h {
+		h[i] = math.Exp(-float64(i) / 50)
+	}
+	want := Conv(x, h)
+	c := NewConvolver(h)
+	var y []float64
+	for start, n := 0, 1; start < len(x); start, n = start+n, n*2+7 {
+		end := start + n
+		if end > len(x) {
+			end = len(x)
+		}
+		y = append(y, c.Process(x[start:end])...)
+	}
+	y = append(y, c.Flush()...)
+	if !EqualApprox(y, want, 1e-9) {
+		t.Error("streamed convolution differs from Conv")
+	}
+}
+
+func TestMatchedFilter(t *testing.T) {
+	template := []float64{0, 1, 3, -2, 1, 0.5, -1}
+	x := make([]float64, 200)
+	for i := range x {
+		x[i] = 0.1 * math.Sin(float64(i)*1.7)
+	}
+	for _, pos := range []int{30, 120} {
+		for k, f := range template {
+			x[pos+k] += 2*f + 0.5
+		}
+	}
+	c := MatchedFilter(x, template)
+	if len(c) != len(x)-len(template)+1 {
+		t.Fatalf("len(c) = %d", len(c))
+	}
+	for n, f := range c {
+		if f > 1+1e-9 || f < -1-1e-9 {
+			t.Errorf("c[%d] = %f", n, f)
+		}
+		if (n == 30 || n == 120) != (f > 0.95) {
+			t.Errorf("c[%d] = %f", n, f)
+		}
+	}
+}
+
+func TestReadWavFile(t *testing.T) {
+	x := []float64{0, 0.5, -0.5, 1, -1, 0.25}
+	dir := t.TempDir()
+	for _, f := range []struct {
+		format, bits int
+		want         func(float64) float64
+	}{
+		{wavFormatPCM, 16, pcmWant(16)},
+		{wavFormatPCM, 24, pcmWant(24)},
+		{wavFormatPCM, 32, pcmWant(32)},
+		{wavFormatIEEEFloat, 32, func(f float64) float64 { return f }},
+		{wavFormatIEEEFloat, 64, func(f float64) float64 { return f }},
+	} {
+		fname := filepath.Join(dir, fmt.Sprintf("%d_%d.wav", f.format, f.bits))
+		writeWav(t, fname, f.format, f.bits, 8000, x, Sub(x, x))
+		channels, rate, bits := ReadWavFile(fname)
+		if len(channels) != 2 || rate != 8000 || bits != f.bits {
+			t.Fatalf("%d channels, rate %d, %d bits", len(channels), rate, bits)
+		}
+		for i, v := range x {
+			if got, want := channels[0][i], f.want(v); math.Abs(got-want) > 1e-4 {
+				t.Errorf("format %d, %d bits: x[%d] = %f, want %f", f.format, f.bits, i, got, want)
+			}
+		}
+		channels32, _, _ := ReadWavFile32(fname)
+		for c := range channels {
+			for i, v := range channels[c] {
+				if channels32[c][i] != float32(v) {
+					t.Fatalf("format %d, %d bits: channels32[%d][%d] = %f, want %f",
+						f.format, f.bits, c, i, channels32[c][i], v)
+				}
+			}
+		}
+	}
+}
+
+// pcmWant returns the value read by ReadWavFile for a sample f in [-1,1]
+func pcmWant(bits int) func(float64) float64 {
+	return func(f float64) float64 {
+		max := math.Ldexp(1, bits-1) - 1
+		return (math.Round(f*max) + max + 1) / (2*max + 1)
+	}
+}
+
+// writeWav writes the channels, with samples in [-1,1], to a wav file
+func writeWav(t *testing.T, fname string, format, bits, sampleRate int, channels ...[]float64) {
+	encoding := WavFloat
+	if format == wavFormatPCM {
+		// WriteWavFile maps PCM samples from [0,1]
+		encoding, channels = WavPCM, append([][]float64{}, channels...)
+		for c, ch := range channels {
+			channels[c] = make([]float64, len(ch))
+			for i, f := range ch {
+				channels[c][i] = pcmWant(bits)(f)
+			}
+		}
+	}
+	if err := WriteWavFileErr(fname, channels, sampleRate, bits, encoding); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteWavFile(t *testing.T) {
+	x := []float64{0, 0.25, 0.5, 0.75, 1, 0.1234567, 0.987654321}
+	y := make([]float64, len(x))
+	for i, f := range x {
+		y[i] = 1 - f
+	}
+	dir := t.TempDir()
+	for _, f := range []struct {
+		encoding WavEncoding
+		bits     int
+		tol      float64
+	}{
+		{WavPCM, 8, 1.0 / 255}, {WavPCM, 16, 1.0 / 65535}, {WavPCM, 24, math.Ldexp(1, -24)},
+		{WavPCM, 32, math.Ldexp(1, -32)}, {WavFloat, 32, 1e-7}, {WavFloat, 64, 0},
+	} {
+		fname := filepath.Join(dir, fmt.Sprintf("%d_%d.wav", f.encoding, f.bits))
+		WriteWavFile(fname, [][]float64{x, y}, 44100, f.bits, f.encoding)
+		channels, rate, bits := ReadWavFile(fname)
+		if len(channels) != 2 || rate != 44100 || bits != f.bits {
+			t.Fatalf("%d channels, rate %d, %d bits", len(channels), rate, bits)
+		}
+		for i, v := range x {
+			if math.Abs(channels[0][i]-v) > f.tol || math.Abs(channels[1][i]-y[i]) > f.tol {
+				t.Errorf("encoding %d, %d bits: x[%d] = %g, y[%d] = %g, want %g, %g",
+					f.encoding, f.bits, i, channels[0][i], i, channels[1][i], v, y[i])
+			}
+		}
+		// The samples that were read are written exactly
+		buf := new(bytes.Buffer)
+		if err := WriteWavTo(buf, channels, rate, bits, f.encoding); err != nil {
+			t.Fatal(err)
+		}
+		channels2, _, _, err := ReadWavFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for c := range channels {
+			for i, v := range channels[c] {
+				if channels2[c][i] != v {
+					t.Fatalf("encoding %d, %d bits: channels[%d][%d] = %g, want %g", f.encoding, f.bits, c, i, channels2[c][i], v)
+				}
+			}
+		}
+	}
+	// PCM samples outside [0,1] are clipped
+	buf := new(bytes.Buffer)
+	WriteWavTo(buf, [][]float64{{-0.5, 1.5, math.NaN()}}, 8000, 16, WavPCM)
+	if channels, _, _, _ := ReadWavFrom(buf); fmt.Sprint(channels[0]) != "[0 1 0]" {
+		t.Errorf("clipped samples = %v", channels[0])
+	}
+	for _, tc := range []struct {
+		channels [][]float64
+		rate     int
+		bits     int
+		encoding WavEncoding
+		err      error
+	}{
+		{[][]float64{x}, 8000, 12, WavPCM, ErrArgument},
+		{[][]float64{x}, 8000, 16, WavFloat, ErrArgument},
+		{[][]float64{x}, 0, 16, WavPCM, ErrArgument},
+		{nil, 8000, 16, WavPCM, ErrArgument},
+		{[][]float64{x, x[1:]}, 8000, 16, WavPCM, ErrLength},
+	} {
+		if err := WriteWavTo(new(bytes.Buffer), tc.channels, tc.rate, tc.bits, tc.encoding); !errors.Is(err, tc.err) {
+			t.Errorf("%d bits, encoding %d: err = %v, want %v", tc.bits, tc.encoding, err, tc.err)
+		}
+	}
+}
+
+func TestReadWavMetadata(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "meta.wav")
+	x := []float64{0.5, -0.5, 0.25}
+	writeWav(t, fname, wavFormatIEEEFloat, 32, 8000, x)
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := func(id string, body []byte) []byte {
+		c := append([]byte(id), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(c[4:], uint32(len(body)))
+		c = append(c, body...)
+		if len(body)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	info := append([]byte("INFO"), chunk("INAM", []byte("Title\x00"))...)
+	info = append(info, chunk("IART", []byte("Art"))...)
+	bext := make([]byte, 602)
+	copy(bext, "A description")
+	copy(bext[256:], "godsp")
+	binary.LittleEndian.PutUint64(bext[338:], 48000)
+	cue := make([]byte, 4+2*24)
+	binary.LittleEndian.PutUint32(cue, 2)
+	binary.LittleEndian.PutUint32(cue[4+20:], 100)
+	binary.LittleEndian.PutUint32(cue[4+24+20:], 2000)
+	extra := append(chunk("LIST", info), chunk("bext", bext)...)
+	extra = append(extra, chunk("cue ", cue)...)
+	b = append(append(append([]byte{}, b[:36]...), extra...), b[36:]...)
+	// A streaming recorder leaves the data size at its maximum
+	binary.LittleEndian.PutUint32(b[36+len(extra)+4:], math.MaxUint32)
+	if err := os.WriteFile(fname, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := ReadWavMetadata(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(meta.Chunks) != "[fmt  LIST bext cue  data]" {
+		t.Errorf("Chunks = %q", meta.Chunks)
+	}
+	if meta.Info["INAM"] != "Title" || meta.Info["IART"] != "Art" {
+		t.Errorf("Info = %v", meta.Info)
+	}
+	if meta.Bext == nil || meta.Bext.Description != "A description" ||
+		meta.Bext.Originator != "godsp" || meta.Bext.TimeReference != 48000 {
+		t.Errorf("Bext = %+v", meta.Bext)
+	}
+	if fmt.Sprint(meta.CuePoints) != "[100 2000]" {
+		t.Errorf("CuePoints = %v", meta.CuePoints)
+	}
+	channels, _, _, err := ReadWavFileErr(fname)
+	if err != nil || !EqualApprox(channels[0], x, 1e-7) {
+		t.Errorf("channels = %v, %v", channels, err)
+	}
+
+	if _, _, _, err := ReadWavFileErr(fname[:len(fname)-4] + "_missing.wav"); err == nil {
+		t.Error("no error for missing file")
+	}
+	if err := os.WriteFile(fname, b[:20], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := ReadWavFileErr(fname); !errors.Is(err, ErrLength) {
+		t.Errorf("truncated fmt chunk: %v", err)
+	}
+	// Sizes of 2^31 or more do not fit the int of 32 bit targets
+	huge := append([]byte{}, b...)
+	binary.LittleEndian.PutUint32(huge[36+4:], math.MaxUint32)
+	if _, err := ReadWavMetadataFrom(bytes.NewReader(huge)); !errors.Is(err, ErrLength) {
+		t.Errorf("huge LIST chunk: %v", err)
+	}
+	huge = append([]byte{}, b...)
+	binary.LittleEndian.PutUint32(huge[36+8+4+4:], 1<<31)
+	if meta, err := ReadWavMetadataFrom(bytes.NewReader(huge)); err != nil || len(meta.Info) != 0 {
+		t.Errorf("huge INFO tag: %v, %v", meta, err)
+	}
+}
+
+func TestReadAiffFile(t *testing.T) {
+	x := []float64{0, 0.5, -0.5, 1, -1, 0.25}
+	data := new(bytes.Buffer)
+	for _, f := range x {
+		binary.Write(data, binary.BigEndian, int16(math.Round(f*32767)))
+		binary.Write(data, binary.BigEndian, int16(0))
+	}
+	b := new(bytes.Buffer)
+	w := func(v interface{}) { binary.Write(b, binary.BigEndian, v) }
+	b.WriteString("FORM")
+	w(uint32(4 + 8 + 18 + 8 + 8 + data.Len()))
+	b.WriteString("AIFFCOMM")
+	w(uint32(18))
+	w(uint16(2))
+	w(uint32(len(x)))
+	w(uint16(16))
+	// 44100 as an 80 bit extended float
+	b.Write([]byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0})
+	b.WriteString("SSND")
+	w(uint32(8 + data.Len()))
+	w(uint64(0))
+	b.Write(data.Bytes())
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "test.aiff")
+	if err := os.WriteFile(fname, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	channels, rate, bits := ReadAiffFile(fname)
+	if len(channels) != 2 || rate != 44100 || bits != 16 {
+		t.Fatalf("%d channels, rate %d, %d bits", len(channels), rate, bits)
+	}
+	want := make([]float64, len(x))
+	for i, f := range x {
+		want[i] = pcmWant(16)(f)
+	}
+	if !EqualApprox(channels[0], want, 1e-9) {
+		t.Errorf("channels[0] = %v, want %v", channels[0], want)
+	}
+
+	fname = filepath.Join(dir, "test.raw")
+	if err := os.WriteFile(fname, data.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	channels, _, _ = ReadRawPCM(fname, 44100, 16, 2, true)
+	if !EqualApprox(channels[0], want, 1e-9) {
+		t.Errorf("raw channels[0] = %v, want %v", channels[0], want)
+	}
+
+	// Sizes and offsets of 2^31 or more do not fit the int of 32 bit targets
+	for _, pos := range []int{16, 46} {
+		huge := append([]byte{}, b.Bytes()...)
+		binary.BigEndian.PutUint32(huge[pos:], math.MaxUint32)
+		if _, _, _, err := ReadAiffFrom(bytes.NewReader(huge)); !errors.Is(err, ErrLength) {
+			t.Errorf("huge size at %d: err = %v", pos, err)
+		}
+	}
+}
+
+func TestReadAudioFile(t *testing.T) {
+	dir := t.TempDir()
+	x := []float64{0.5, -0.5, 0.25}
+	fname := filepath.Join(dir, "test.WAV")
+	writeWav(t, fname, wavFormatIEEEFloat, 32, 8000, x)
+	channels, rate, _ := ReadAudioFile(fname)
+	if len(channels) != 1 || rate != 8000 || !EqualApprox(channels[0], x, 1e-7) {
+		t.Errorf("channels = %v, rate %d", channels, rate)
+	}
+	// A decoder of text files with one sample per line
+	RegisterDecoder(".txtaudio", DecoderFunc(func(r io.Reader) ([][]float64, int, int, error) {
+		var ch []float64
+		for {
+			var f float64
+			if _, err := fmt.Fscan(r, &f); err == io.EOF {
+				return [][]float64{ch}, 100, 64, nil
+			} else if err != nil {
+				return nil, 0, 0, err
+			}
+			ch = append(ch, f)
+		}
+	}))
+	fname = filepath.Join(dir, "test.txtaudio")
+	if err := os.WriteFile(fname, []byte("1\n2\n3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	channels, rate, _ = ReadAudioFile(fname)
+	if rate != 100 || !equalFloats(channels[0], []float64{1, 2, 3}) {
+		t.Errorf("channels = %v, rate %d", channels, rate)
+	}
+	if _, _, _, err := ReadAudioFileErr("x.unknown"); !errors.Is(err, ErrArgument) {
+		t.Errorf("unknown extension: %v", err)
+	}
+}
+
+func TestChannels(t *testing.T) {
+	channels := [][]float64{{1, 2, 3}, {4, 5}}
+	x := Multiplex(channels)
+	if !equalFloats(x, []float64{1, 4, 2, 5, 3, 0}) {
+		t.Errorf("Multiplex = %v", x)
+	}
+	if d := Demultiplex(x, 2); !equalFloats(d[0], channels[0]) || !equalFloats(d[1], []float64{4, 5, 0}) {
+		t.Errorf("Demultiplex = %v", d)
+	}
+	if m := ToMono(channels, nil); !equalFloats(m, []float64{2.5, 3.5, 1.5}) {
+		t.Errorf("ToMono = %v", m)
+	}
+	if m := ToMono(channels, []float64{1, -1}); !equalFloats(m, []float64{-3, -3, 3}) {
+		t.Errorf("weighted ToMono = %v", m)
+	}
+	if s := ChannelSelect(channels, 1, 0); len(s) != 2 || s[0][0] != 4 || s[1][0] != 1 {
+		t.Errorf("ChannelSelect = %v", s)
+	}
+}
+
+func TestBinaryFloats(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "x.f64")
+	x := []float64{1.5, -2, math.Pi, 0}
+	WriteBinaryFloats(x, 44100, fname)
+	y, rate := ReadBinaryFloats(fname)
+	if rate != 44100 || !equalFloats(x, y) {
+		t.Errorf("y = %v, rate %f", y, rate)
+	}
+	b, _ := os.ReadFile(fname)
+	os.WriteFile(fname, b[:len(b)-1], 0644)
+	if _, _, err := ReadBinaryFloatsErr(fname); !errors.Is(err, ErrLength) {
+		t.Errorf("truncated file: %v", err)
+	}
+}
+
+func TestNpy(t *testing.T) {
+	dir := t.TempDir()
+	x := []float64{1, -2.5, math.Pi}
+	SaveNpy(filepath.Join(dir, "x.npy"), x)
+	b, _ := os.ReadFile(filepath.Join(dir, "x.npy"))
+	if hdr := string(b[10:80]); !strings.HasPrefix(hdr, "{'descr': '<f8', 'fortran_order': False, 'shape': (3,), }") || (len(b)-24)%64 != 0 {
+		t.Errorf("header = %q", hdr)
+	}
+	if y := LoadNpy(filepath.Join(dir, "x.npy")); !equalFloats(x, y) {
+		t.Errorf("LoadNpy = %v", y)
+	}
+	X := [][]float64{{1, 2, 3}, {4, 5, 6}}
+	SaveNpyMatrix(filepath.Join(dir, "X.npy"), X)
+	if Y := LoadNpyMatrix(filepath.Join(dir, "X.npy")); len(Y) != 2 || !equalFloats(Y[1], X[1]) {
+		t.Errorf("LoadNpyMatrix = %v", Y)
+	}
+	SaveNpz(filepath.Join(dir, "a.npz"), map[string][][]float64{"X": X, "x": {x}})
+	arrays := LoadNpz(filepath.Join(dir, "a.npz"))
+	if len(arrays) != 2 || !equalFloats(arrays["X"][0], X[0]) || !equalFloats(arrays["x"][0], x) {
+		t.Errorf("LoadNpz = %v", arrays)
+	}
+	// A big endian float32 Fortran order array as written by NumPy
+	hdr := "{'descr': '>f4', 'fortran_order': True, 'shape': (2, 3), }"
+	hdr += strings.Repeat(" ", 128-10-len(hdr)-1) + "\n"
+	npy := append([]byte(npyMagic+"\x01\x00"), byte(len(hdr)), 0)
+	npy = append(npy, hdr...)
+	for _, f := range []float32{1, 4, 2, 5, 3, 6} {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], math.Float32bits(f))
+		npy = append(npy, b[:]...)
+	}
+	if Y, err := decodeNpyMatrix(npy); err != nil || !equalFloats(Y[0], X[0]) || !equalFloats(Y[1], X[1]) {
+		t.Errorf("Fortran order = %v, %v", Y, err)
+	}
+	// Hostile header lengths and shapes are errors
+	v2 := append([]byte(npyMagic+"\x02\x00"), 0xff, 0xff, 0xff, 0xff)
+	if _, _, err := decodeNpy(append(v2, hdr...)); !errors.Is(err, ErrLength) {
+		t.Errorf("huge header: err = %v", err)
+	}
+	for _, shape := range []string{"(2147483647, 2147483647, 2147483647)", "(7, 1)"} {
+		h := strings.Replace(hdr, "(2, 3)", shape, 1)
+		b := append(append([]byte(npyMagic+"\x01\x00"), byte(len(h)), 0), h...)
+		if _, _, err := decodeNpy(append(b, npy[len(npy)-24:]...)); !errors.Is(err, ErrLength) {
+			t.Errorf("shape %s: err = %v", shape, err)
+		}
+	}
+}
+
+func TestFloatMatrixCSV(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "m.csv")
+	X := [][]float64{{1, 0.1, -3e-9}, {math.Pi, 5, 6}}
+	WriteFloatMatrixCSV(fname, []string{"a", "b", "c"}, X, CSVOptions{Delimiter: ';'})
+	b, _ := os.ReadFile(fname)
+	if !strings.HasPrefix(string(b), "a;b;c\n1;0.1;-3e-09\n") {
+		t.Errorf("file = %q", b)
+	}
+	header, Y := ReadFloatMatrixCSV(fname, CSVOptions{Delimiter: ';', Header: true})
+	if len(header) != 3 || header[2] != "c" || len(Y) != 2 || !EqualApprox(X[0], Y[0], 0) || !EqualApprox(X[1], Y[1], 0) {
+		t.Errorf("ReadFloatMatrixCSV = %v, %v", header, Y)
+	}
+	if err := WriteFloatMatrixCSVErr(fname, []string{"a"}, X, CSVOptions{}); !errors.Is(err, ErrLength) {
+		t.Errorf("short header err = %v", err)
+	}
+	os.WriteFile(fname, []byte("1, 2\n3, x\n"), 0644)
+	if _, _, err := ReadFloatMatrixCSVErr(fname, CSVOptions{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("parse err = %v", err)
+	}
+}
+
+func TestReaderWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteDataTo(buf, []float64{1.5, -2}); err != nil || buf.String() != "1.500000\n-2.000000\n" {
+		t.Errorf("WriteDataTo = %q, %v", buf, err)
+	}
+	// The last line need not end in a newline
+	x, err := LoadFloatsFrom(strings.NewReader("1.5\r\n-2\n3"))
+	if err != nil || !equalFloats(x, []float64{1.5, -2, 3}) {
+		t.Errorf("LoadFloatsFrom = %v, %v", x, err)
+	}
+	buf.Reset()
+	WriteIntMatrixDataTo(buf, [][]int{{1, 2}, {3, 4}})
+	if buf.String() != "1,2\n3,4\n" {
+		t.Errorf("WriteIntMatrixDataTo = %q", buf)
+	}
+
+	buf.Reset()
+	WriteBinaryFloatsTo(buf, []float64{1, 2}, 8000)
+	if x, rate, err := ReadBinaryFloatsFrom(buf); err != nil || rate != 8000 || !equalFloats(x, []float64{1, 2}) {
+		t.Errorf("ReadBinaryFloatsFrom = %v, %f, %v", x, rate, err)
+	}
+
+	buf.Reset()
+	SaveNpzTo(buf, map[string][][]float64{"a": {{1, 2}}})
+	if arrays, err := LoadNpzFrom(buf); err != nil || !equalFloats(arrays["a"][0], []float64{1, 2}) {
+		t.Errorf("LoadNpzFrom = %v, %v", arrays, err)
+	}
+
+	fname := filepath.Join(t.TempDir(), "test.wav")
+	writeWav(t, fname, wavFormatIEEEFloat, 32, 8000, []float64{0.5, -0.5})
+	b, _ := os.ReadFile(fname)
+	channels, rate, _, err := ReadAudioFrom(bytes.NewReader(b), ".wav")
+	if err != nil || rate != 8000 || !equalFloats(channels[0], []float64{0.5, -0.5}) {
+		t.Errorf("ReadAudioFrom = %v, %d, %v", channels, rate, err)
+	}
+	if _, _, _, err := ReadWavFrom(strings.NewReader("RIFF")); err == nil {
+		t.Error("ReadWavFrom: no error for truncated file")
+	}
+}
+
+func TestWriteSeries(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "series.csv")
+	nan := math.NaN()
+	WriteSeriesGnuplot(fname, map[string][]float64{
+		"env":   {0.5, 1, 0.25},
+		"peaks": {nan, 1},
+	}, 2)
+	b, _ := os.ReadFile(fname)
+	if want := "time,env,peaks\n0,0.5,\n0.5,1,1\n1,0.25,\n"; string(b) != want {
+		t.Errorf("csv = %q, want %q", b, want)
+	}
+	gp, _ := os.ReadFile(fname + ".gp")
+	if !strings.Contains(string(gp), "'series.csv' using 1:3 with points pt 7 title 'peaks'") {
+		t.Errorf("script = %s", gp)
+	}
+}
+
+func TestFindMax(t *testing.T) {
+	x := []float64{1, 3, 0, 3, 5}
+	if v, i := FindMax(x); v != 5 || i != 4 {
+		t.Errorf("FindMax = %f, %d", v, i)
+	}
+	if v, i := FindMin([]float64{1, 3, 0.5, -1}); v != -1 || i != 3 {
+		t.Errorf("FindMin = %f, %d", v, i)
+	}
+	if v, i := FindMaxI([]int{1, 2}); v != 2 || i != 1 {
+		t.Errorf("FindMaxI = %d, %d", v, i)
+	}
+	if v, i := FindMaxRange(x, 1, 4); v != 3 || i != 1 {
+		t.Errorf("FindMaxRange = %f, %d", v, i)
+	}
+	idx := ArgSort(x)
+	for i, want := range []int{2, 0, 1, 3, 4} {
+		if idx[i] != want {
+			t.Fatalf("ArgSort = %v", idx)
+		}
+	}
+}
+
+func TestFindTopK(t *testing.T) {
+	x := []float64{1, 9, 8, 0, 5, 5, 0, 7, 2, 9}
+	for _, tc := range []struct {
+		k, minSep int
+		want      []int
+	}{
+		{3, 0, []int{1, 9, 2}},
+		{3, 2, []int{1, 9, 7}},
+		{10, 3, []int{1, 9}},
+		{10, 2, []int{1, 9, 7, 4}},
+		{0, 1, []int{}},
+	} {
+		got := FindTopK(x, tc.k, tc.minSep)
+		if len(got) != len(tc.want) {
+			t.Errorf("k %d, minSep %d: %v, want %v", tc.k, tc.minSep, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("k %d, minSep %d: %v, want %v", tc.k, tc.minSep, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestHist(t *testing.T) {
+	x := []float64{0, 0.5, 1, 1.5, 2, 2, math.NaN(), 4}
+	counts, edges := Hist(x, 4)
+	if fmt.Sprint(counts) != "[2 2 2 1]" || fmt.Sprint(edges) != "[0 1 2 3 4]" {
+		t.Errorf("Hist: counts %v, edges %v", counts, edges)
+	}
+	counts, _ = HistRange(x, 1, 2, 2)
+	if fmt.Sprint(counts) != "[1 3]" {
+		t.Errorf("HistRange: counts %v", counts)
+	}
+	counts, edges = Hist([]float64{3, 3}, 2)
+	if fmt.Sprint(counts) != "[0 2]" || fmt.Sprint(edges) != "[2.5 3 3.5]" {
+		t.Errorf("Hist of equal values: counts %v, edges %v", counts, edges)
+	}
+	// Infinities are ignored
+	counts, edges = Hist([]float64{math.Inf(1), 0, math.Inf(-1), 4}, 4)
+	if fmt.Sprint(counts) != "[1 0 0 1]" || fmt.Sprint(edges) != "[0 1 2 3 4]" {
+		t.Errorf("Hist with infinities: counts %v, edges %v", counts, edges)
+	}
+	counts, edges = Hist([]float64{math.Inf(1), 1}, 2)
+	if fmt.Sprint(counts) != "[0 1]" || fmt.Sprint(edges) != "[0.5 1 1.5]" {
+		t.Errorf("Hist with an infinity: counts %v, edges %v", counts, edges)
+	}
+	if _, _, err := HistErr([]float64{math.Inf(1), math.NaN()}, 2); !errors.Is(err, ErrLength) {
+		t.Errorf("HistErr without finite values: err = %v", err)
+	}
+	sums, _ := HistWeighted(x, []float64{1, 2, 3, 4, 5, 6, 7, 8}, 0, 4, 2)
+	if fmt.Sprint(sums) != "[10 19]" {
+		t.Errorf("HistWeighted: sums %v", sums)
+	}
+	if _, _, err := HistRangeErr(x, 1, 1, 2); !errors.Is(err, ErrArgument) {
+		t.Errorf("HistRangeErr: err = %v", err)
+	}
+	if _, _, err := HistWeightedErr(x, x[:1], 0, 4, 2); !errors.Is(err, ErrLength) {
+		t.Errorf("HistWeightedErr: err = %v", err)
+	}
+}
+
+func TestISTFT(t *testing.T) {
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = math.Sin(0.1*float64(i)) + 0.5*math.Cos(1.3*float64(i)*float64(i%7))
+	}
+	for _, tc := range []struct {
+		frameLen, hop int
+		window        Window
+	}{{256, 64, hann}, {100, 30, hann}, {64, 64, nil}, {64, 16, nil}} {
+		frames := STFT(x, tc.frameLen, tc.hop, tc.window)
+		y := ISTFT(frames, tc.frameLen, tc.hop, tc.window)
+		if len(y) != (len(frames)-1)*tc.hop+tc.frameLen {
+			t.Fatalf("len(y) = %d", len(y))
+		}
+		// The first sample has a zero Hann window
+		for i := 1; i < len(y); i++ {
+			if math.Abs(y[i]-x[i]) > 1e-9 {
+				t.Fatalf("frameLen %d, hop %d: y[%d] = %f, want %f", tc.frameLen, tc.hop, i, y[i], x[i])
+			}
+		}
+	}
+	if _, err := ISTFTErr([][]complex128{make([]complex128, 3)}, 8, 2, nil); !errors.Is(err, ErrLength) {
+		t.Errorf("ISTFTErr: err = %v", err)
+	}
+	if sum, ok := COLA(hann, 256, 64); !ok || math.Abs(sum-2) > 1e-9 {
+		t.Errorf("COLA(hann, 256, 64) = %f, %t", sum, ok)
+	}
+	if _, ok := COLA(hann, 256, 100); ok {
+		t.Error("COLA(hann, 256, 100) is true")
+	}
+}
+
+func TestSTFT(t *testing.T) {
+	// A tail that does not fill a frame is dropped, as by NoFramePadding
+	for _, tc := range []struct{ n, frameLen, hop, numFrames int }{
+		{1000, 256, 64, 12}, {256, 256, 64, 1}, {255, 256, 64, 0}, {320, 256, 64, 2}, {1000, 100, 100, 10},
+	} {
+		frames := STFT(make([]float64, tc.n), tc.frameLen, tc.hop, nil)
+		if len(frames) != tc.numFrames {
+			t.Errorf("n %d, frameLen %d, hop %d: %d frames, want %d", tc.n, tc.frameLen, tc.hop, len(frames), tc.numFrames)
+		}
+		if it := Frames(make([]float64, tc.n), tc.frameLen, tc.hop); it.Len() != len(frames) {
+			t.Errorf("n %d: %d frames, Frames has %d", tc.n, len(frames), it.Len())
+		}
+		for _, frame := range frames {
+			if len(frame) != tc.frameLen/2+1 {
+				t.Fatalf("frameLen %d: %d bins", tc.frameLen, len(frame))
+			}
+		}
+	}
+	// A pure tone peaks at its bin in every frame
+	x := make([]float64, 2000)
+	for _, bin := range []float64{20, 20.3, 57.5} {
+		for i := range x {
+			x[i] = math.Sin(2 * math.Pi * bin * float64(i) / 256)
+		}
+		for _, window := range []Window{nil, hann} {
+			S := Spectrogram(x, 256, 64, window)
+			for i, frame := range S {
+				if _, k := FindMax(frame); k != int(bin) && k != int(math.Ceil(bin)) {
+					t.Fatalf("bin %g: frame %d peaks at %d", bin, i, k)
+				}
+			}
+		}
+	}
+	// The magnitude of a tone at a bin with a rectangular window is frameLen/2
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * 20 * float64(i) / 256)
+	}
+	if m := Spectrogram(x, 256, 64, nil)[3][20]; math.Abs(m-128) > 1e-9 {
+		t.Errorf("magnitude = %f, want 128", m)
+	}
+	S, P := Spectrogram(x, 256, 100, hann), PowerSpectrogram(x, 256, 100, hann)
+	if len(P) != len(S) {
+		t.Fatalf("%d power frames, want %d", len(P), len(S))
+	}
+	for i := range S {
+		for k, m := range S[i] {
+			if math.Abs(P[i][k]-m*m) > 1e-12*math.Max(1, m*m) {
+				t.Fatalf("P[%d][%d] = %g, want %g", i, k, P[i][k], m*m)
+			}
+		}
+	}
+}
+
+func TestFilterbank(t *testing.T) {
+	for _, f := range []float64{0, 100, 1000, 8000} {
+		if math.Abs(MelToHz(HzToMel(f))-f) > 1e-9 || math.Abs(BarkToHz(HzToBark(f))-f) > 1e-9 ||
+			math.Abs(ERBToHz(HzToERB(f))-f) > 1e-9 {
+			t.Errorf("scale conversion of %f Hz", f)
+		}
+	}
+	if m := HzToMel(1000); math.Abs(m-1000) > 0.1 {
+		t.Errorf("HzToMel(1000) = %f", m)
+	}
+	fs, N := 16000.0, 512
+	for name, fb := range map[string]*Filterbank{
+		"mel":  MelFilterbank(40, 0, 8000, fs, N),
+		"bark": BarkFilterbank(20, 50, 8000, fs, N),
+		"erb":  ERBFilterbank(30, 50, 8000, fs, N),
+	} {
+		fc := fb.CentreFrequencies()
+		if len(fc) != fb.NumBands() || !sort.Float64sAreSorted(fc) {
+			t.Errorf("%s: centre frequencies %v", name, fc)
+		}
+		// A tone at the centre of a band excites that band most
+		j := fb.NumBands() * 2 / 3
+		frame := make([]float64, N/2+1)
+		frame[int(math.Round(fc[j]*float64(N)/fs))] = 1
+		Y := fb.Apply([][]float64{frame})
+		if _, i := FindMax(Y[0]); i != j {
+			t.Errorf("%s: tone at %f Hz excites band %d, want %d", name, fc[j], i, j)
+		}
+		if w := fb.Weights(j); len(w) != N/2+1 || Max(w) > 1 || Max(w) < 0.5 {
+			t.Errorf("%s: weights of band %d: %v", name, j, w)
+		}
+	}
+	if _, err := MelFilterbankErr(10, 0, 9000, fs, N); !errors.Is(err, ErrArgument) {
+		t.Errorf("MelFilterbankErr: err = %v", err)
+	}
+	if _, err := MelFilterbank(10, 0, 8000, fs, N).ApplyErr([][]float64{{1}}); !errors.Is(err, ErrLength) {
+		t.Errorf("ApplyErr: err = %v", err)
+	}
+}
+
+func TestChroma(t *testing.T) {
+	fs := 16000.0
+	tone := func(x []float64, f float64) {
+		for i := range x {
+			x[i] += math.Sin(2 * math.Pi * f * float64(i) / fs)
+		}
+	}
+	// C major triad C4 E4 G4
+	x := make([]float64, 16000)
+	for _, f := range []float64{261.63, 329.63, 392.00} {
+		tone(x, f)
+	}
+	C := Chroma(x, fs, 4096, 2048)
+	for _, frame := range C {
+		for pc, v := range frame {
+			if major := pc == 0 || pc == 4 || pc == 7; major != (v > 0.5) {
+				t.Fatalf("chroma %v", frame)
+			}
+		}
+	}
+	// A4 a fifth of a semitone sharp
+	tuning := 0.2
+	x = make([]float64, 16000)
+	tone(x, 440*math.Pow(2, tuning/12))
+	if est := EstimateTuning(x, fs, 4096, 2048); math.Abs(est-tuning) > 0.03 {
+		t.Errorf("EstimateTuning = %f, want %f", est, tuning)
+	}
+	for _, frame := range ChromaTuned(x, fs, 4096, 2048, tuning) {
+		if _, pc := FindMax(frame); pc != 9 {
+			t.Errorf("chroma %v", frame)
+		}
+	}
+	if _, err := ChromaErr(x, fs, 1, 1); !errors.Is(err, ErrArgument) {
+		t.Errorf("ChromaErr: err = %v", err)
+	}
+}
+
+func TestSpectralDescriptors(t *testing.T) {
+	// 8 bins of a frame of length 14 at 1400 Hz: 100 Hz per bin
+	S := [][]float64{
+		{0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 1, 0, 1, 0, 0, 0},
+		{1, 1, 1, 1, 1, 1, 1, 1},
+	}
+	if c := SpectralCentroid(S, 1400); fmt.Sprint(c) != "[0 300 350]" {
+		t.Errorf("SpectralCentroid = %v", c)
+	}
+	if bw := SpectralBandwidth(S, 1400); bw[0] != 0 || math.Abs(bw[1]-100) > 1e-9 || math.Abs(bw[2]-100*math.Sqrt(5.25)) > 1e-9 {
+		t.Errorf("SpectralBandwidth = %v", bw)
+	}
+	if r := SpectralRolloff(S, 1400, 0.85); fmt.Sprint(r) != "[0 400 600]" {
+		t.Errorf("SpectralRolloff = %v", r)
+	}
+	if fl := SpectralFlatness(S); math.Abs(fl[0]-1) > 1e-9 || fl[1] > 1e-6 || math.Abs(fl[2]-1) > 1e-9 {
+		t.Errorf("SpectralFlatness = %v", fl)
+	}
+	if flux := SpectralFlux(S); fmt.Sprint(flux) != "[0 2 6]" {
+		t.Errorf("SpectralFlux = %v", flux)
+	}
+	if _, err := SpectralRolloffErr(S, 1400, 0); !errors.Is(err, ErrArgument) {
+		t.Errorf("SpectralRolloffErr: err = %v", err)
+	}
+	if _, err := SpectralFluxErr([][]float64{{1}, {1, 2}}); !errors.Is(err, ErrLength) {
+		t.Errorf("SpectralFluxErr: err = %v", err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	x := make([]complex128, 100)
+	for i := range x {
+		x[i] = complex(float64(i%7), 0)
+	}
+	X, err := FFTContext(context.Background(), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := IFFTContext(context.Background(), X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range x {
+		if cmplx.Abs(y[i]-x[i]) > 1e-9 {
+			t.Fatalf("y[%d] = %v, want %v", i, y[i], x[i])
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FFTContext(ctx, x); !errors.Is(err, context.Canceled) {
+		t.Errorf("FFTContext: err = %v", err)
+	}
+	r := Real(x)
+	if c, err := XcorrContext(context.Background(), r, r, 10); err != nil || fmt.Sprint(c) != fmt.Sprint(Xcorr(r, r, 10)) {
+		t.Errorf("XcorrContext = %v, %v", c, err)
+	}
+	if _, err := XcorrContext(ctx, r, r, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("XcorrContext: err = %v", err)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	x := make([]float64, 1000)
+	fname := filepath.Join(t.TempDir(), "x.wav")
+	writeWav(t, fname, wavFormatIEEEFloat, 32, 8000, x)
+	info, err := os.Stat(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, read := range map[string]func(...Option){
+		"ReadWavFile":   func(opts ...Option) { ReadWavFile(fname, opts...) },
+		"ReadAudioFile": func(opts ...Option) { ReadAudioFile(fname, opts...) },
+	} {
+		last := 0
+		read(WithProgress(func(done, total int) {
+			if done < last || total != int(info.Size()) {
+				t.Fatalf("%s: progress %d of %d", name, done, total)
+			}
+			last = done
+		}))
+		if last != int(info.Size()) {
+			t.Errorf("%s: progress %d of %d", name, last, info.Size())
+		}
+	}
+	xs := make([][]float64, 10)
+	for i := range xs {
+		xs[i] = []float64{1, 2, 3}
+	}
+	last := 0
+	NormaliseAll(xs, WithWorkers(3), WithProgress(func(done, total int) {
+		if done != last+1 || total != len(xs) {
+			t.Fatalf("NormaliseAll: progress %d of %d", done, total)
+		}
+		last = done
+	}))
+	if last != len(xs) {
+		t.Errorf("NormaliseAll: progress %d", last)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6, 7}
+	frames := func(it *FrameIterator) string {
+		s := []string{}
+		for it.Next() {
+			s = append(s, fmt.Sprint(it.Start(), it.Frame()))
+		}
+		if len(s) != it.Len() {
+			t.Errorf("Len() = %d, want %d", it.Len(), len(s))
+		}
+		return strings.Join(s, " ")
+	}
+	for _, tc := range []struct {
+		padding FramePadding
+		want    string
+	}{
+		{NoFramePadding, "0 [1 2 3 4] 3 [4 5 6 7]"},
+		{EndFramePadding, "0 [1 2 3 4] 3 [4 5 6 7] 6 [7 0 0 0]"},
+		{CenterFramePadding, "-2 [0 0 1 2] 1 [2 3 4 5] 4 [5 6 7 0]"},
+	} {
+		if got := frames(Frames(x, 4, 3, WithFramePadding(tc.padding))); got != tc.want {
+			t.Errorf("padding %d: %s, want %s", tc.padding, got, tc.want)
+		}
+	}
+	// Zero-copy frames share the samples of x
+	it := Frames(x, 4, 3, WithZeroCopy())
+	it.Next()
+	it.Frame()[0] = 10
+	if x[0] != 10 {
+		t.Error("zero-copy frame is a copy")
+	}
+	if n := Frames(x[:3], 4, 1).Len(); n != 0 {
+		t.Errorf("%d frames of a short signal", n)
+	}
+	if _, err := FramesErr(x, 0, 1); !errors.Is(err, ErrArgument) {
+		t.Errorf("FramesErr: err = %v", err)
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	r := NewRingBuffer(4)
+	r.Push(1, 2, 3)
+	if s := fmt.Sprint(r.Slice()); s != "[1 2 3]" {
+		t.Errorf("Slice() = %s", s)
+	}
+	r.Push(4, 5, 6)
+	if s := fmt.Sprint(r.Slice()); s != "[3 4 5 6]" || r.At(0) != 3 {
+		t.Errorf("Slice() = %s", s)
+	}
+	dst := make([]float64, 3)
+	if n := r.Read(dst); n != 3 || fmt.Sprint(dst) != "[3 4 5]" {
+		t.Errorf("Read() = %d %v", n, dst)
+	}
+	r.Push(7)
+	if n := r.Read(dst); n != 2 || fmt.Sprint(dst[:n]) != "[6 7]" || r.Len() != 0 {
+		t.Errorf("Read() = %d %v", n, dst[:n])
+	}
+}
+
+func TestDelayLine(t *testing.T) {
+	// Linear interpolation delays a ramp exactly
+	d := NewDelayLine(8, LinearInterpolation)
+	d.SetDelay(2.5)
+	for i, y := range d.ProcessBlock([]float64{1, 2, 3, 4, 5, 6}) {
+		if want := math.Max(float64(i+1)-2.5, 0); i >= 3 && math.Abs(y-want) > 1e-12 {
+			t.Errorf("y[%d] = %f, want %f", i, y, want)
+		}
+	}
+	// Allpass interpolation delays a low frequency sine with unit gain
+	for _, delay := range []float64{3, 3.25, 7.5} {
+		d = NewDelayLine(8, AllpassInterpolation)
+		d.SetDelay(delay)
+		w := 0.05
+		for i := 0; i < 200; i++ {
+			y := d.Process(math.Sin(w * float64(i)))
+			if want := math.Sin(w * (float64(i) - delay)); i > 100 && math.Abs(y-want) > 1e-3 {
+				t.Fatalf("delay %f: y[%d] = %f, want %f", delay, i, y, want)
+			}
+		}
+	}
+}
+
+func TestLowpassFilter32(t *testing.T) {
+	x := make([]float64, 100)
+	for i := range x {
+		x[i] = math.Sin(float64(i) / 5)
+	}
+	y, y32 := LowpassFilter(x, 0.2), LowpassFilter(Convert[float32](x), 0.2)
+	for i := range y {
+		if math.Abs(float64(y32[i])-y[i]) > 1e-6 {
+			t.Fatalf("y32[%d] = %f, want %f", i, y32[i], y[i])
+		}
+	}
+}
+
+func TestMapWavFile(t *testing.T) {
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = math.Sin(float64(i) / 10)
+	}
+	fname := filepath.Join(t.TempDir(), "map.wav")
+	writeWav(t, fname, wavFormatPCM, 16, 8000, x, Sub(x, x))
+	channels, _, _ := ReadWavFile(fname)
+	m, err := MapWavFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if rate, n, bits := m.Format(); rate != 8000 || n != 2 || bits != 16 {
+		t.Fatalf("Format() = %d %d %d", rate, n, bits)
+	}
+	for c, ch := range channels {
+		r := m.Channel(c)
+		if r.Len() != len(ch) {
+			t.Fatalf("channel %d: Len() = %d, want %d", c, r.Len(), len(ch))
+		}
+		for _, padding := range []FramePadding{NoFramePadding, EndFramePadding, CenterFramePadding} {
+			it, itr := Frames(ch, 64, 48, WithFramePadding(padding)), FramesFrom(r, 64, 48, WithFramePadding(padding))
+			for it.Next() {
+				if !itr.Next() || !equalFloats(itr.Frame(), it.Frame()) {
+					t.Fatalf("channel %d, padding %d: frame %d = %v, want %v",
+						c, padding, it.Index(), itr.Frame(), it.Frame())
+				}
+			}
+			if itr.Next() || itr.Err() != nil {
+				t.Errorf("channel %d, padding %d: Err() = %v", c, padding, itr.Err())
+			}
+		}
+	}
+	dst := make([]float64, 10)
+	if n, err := m.Channel(0).ReadSamplesAt(dst, len(x)-5); n != 5 || err != io.EOF {
+		t.Errorf("ReadSamplesAt() = %d, %v", n, err)
+	}
+}
+
+func BenchmarkFFT(b *testing.B) {
+	// 44100 is not a power of 2 and is transformed by Bluestein's algorithm
+	for _, n := range []int{1024, 1 << 16, 44100} {
+		x := make([]complex128, n)
+		for i, f := range benchdata.Signal(n) {
+			x[i] = complex(f, 0)
+		}
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FFT(x)
+			}
+		})
+	}
+}
+
+func BenchmarkXcorr(b *testing.B) {
+	for _, n := range []int{1 << 12, 1 << 16} {
+		x := benchdata.Signal(n)
+		y := Shift(x, 10)
+		for name, xcorr := range map[string]func(x, y []float64, maxDelay int) []float64{
+			"direct": Xcorr,
+			"FFT":    XcorrFFT,
+		} {
+			b.Run(fmt.Sprintf("%s/N=%d", name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					xcorr(x, y, 1024)
+				}
+			})
+		}
+	}
+}
+
+func TestGoldenFFT(t *testing.T) {
+	for _, c := range golden.Load(t, "testdata/fft.json").Cases {
+		x := make([]complex128, len(c.Input))
+		for i, f := range c.Input {
+			x[i] = complex(f, 0)
+		}
+		c.CheckComplex(t, FFT(x))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	x, y := []float64{1, 2, 3, 4}, []float64{1, 2.1, 3, 3.8}
+	if !EqualApprox(x, y, 0.25) || EqualApprox(x, y, 0.15) || EqualApprox(x, y[:3], 1) {
+		t.Error("EqualApprox")
+	}
+	if EqualApprox([]float64{math.NaN()}, []float64{math.NaN()}, 1) {
+		t.Error("EqualApprox(NaN)")
+	}
+	if e := MaxAbsError(x, y); math.Abs(e-0.2) > 1e-12 {
+		t.Errorf("MaxAbsError = %f", e)
+	}
+	if e := RMSE(x, y); math.Abs(e-math.Sqrt(0.05/4)) > 1e-12 {
+		t.Errorf("RMSE = %f", e)
+	}
+	if snr := SNR(x, y); math.Abs(snr-10*math.Log10(30/0.05)) > 1e-9 {
+		t.Errorf("SNR = %f", snr)
+	}
+	if snr := SNR(x, x); !math.IsInf(snr, 1) {
+		t.Errorf("SNR(x, x) = %f", snr)
+	}
+}
+
+func TestPadTo(t *testing.T) {
+	x := []float64{1, 2, 3}
+	for mode, want := range map[PadMode]string{
+		PadZero:      "[1 2 3 0 0 0 0 0]",
+		PadSymmetric: "[1 2 3 3 2 1 1 2]",
+		PadPeriodic:  "[1 2 3 1 2 3 1 2]",
+		PadReflect:   "[1 2 3 2 1 2 3 2]",
+		PadEdge:      "[1 2 3 3 3 3 3 3]",
+	} {
+		if y := fmt.Sprint(PadTo(x, 8, mode)); y != want {
+			t.Errorf("mode %d: PadTo = %s, want %s", mode, y, want)
+		}
+		if y := fmt.Sprint(PadToPow2(x, mode)); y != want[:8]+"]" {
+			t.Errorf("mode %d: PadToPow2 = %s", mode, y)
+		}
+	}
+	if y := PadToPow2([]float32{1, 2, 3, 4}, PadZero); len(y) != 4 {
+		t.Errorf("PadToPow2 = %v", y)
+	}
+	if _, err := PadToErr(x, 2, PadZero); !errors.Is(err, ErrLength) {
+		t.Errorf("PadToErr: err = %v", err)
+	}
+	if _, err := PadToErr([]float64{}, 2, PadEdge); !errors.Is(err, ErrLength) {
+		t.Errorf("PadToErr: err = %v", err)
+	}
+	if y := Truncate(x, 2); fmt.Sprint(y) != "[1 2]" || len(Truncate(x, 5)) != 3 {
+		t.Errorf("Truncate = %v", y)
+	}
+}
+
+func TestAlignByXcorr(t *testing.T) {
+	if y := Shift([]float64{1, 2, 3, 4}, 1); fmt.Sprint(y) != "[0 1 2 3]" {
+		t.Errorf("Shift(1) = %v", y)
+	}
+	if y := Shift([]float32{1, 2, 3, 4}, -2); fmt.Sprint(y) != "[3 4 0 0]" {
+		t.Errorf("Shift(-2) = %v", y)
+	}
+	x := benchdata.Signal(1000)
+	for _, lag := range []int{37, -20, 0} {
+		y := Shift(x, lag)
+		xa, ya, got := AlignByXcorr(x, y, 50)
+		if got != lag {
+			t.Errorf("lag = %d, want %d", got, lag)
+			continue
+		}
+		if len(xa) != len(x)-int(math.Abs(float64(lag))) || !EqualApprox(xa, ya, 0) {
+			t.Errorf("lag %d: aligned signals differ", lag)
+		}
+	}
+	if _, _, lag := AlignByXcorr(x, make([]float64, 1000), 50); lag != 0 {
+		t.Errorf("lag of zero signal = %d", lag)
+	}
+}
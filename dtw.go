@@ -0,0 +1,131 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+// DistanceFunc returns the distance between the feature vectors x and y
+type DistanceFunc func(x, y []float64) float64
+
+/*
+Euclidean returns the Euclidean distance between x and y.
+The function panics if len(x) != len(y).
+*/
+func Euclidean(x, y []float64) float64 {
+	d := Sub(x, y)
+	return math.Sqrt(Dot(d, d))
+}
+
+/*
+Manhattan returns the sum of the absolute differences between x and y.
+The function panics if len(x) != len(y).
+*/
+func Manhattan(x, y []float64) float64 {
+	return Sum(Abs(Sub(x, y)))
+}
+
+/*
+DTW returns the cost of the dynamic time warping alignment of the feature
+sequences x and y and the warping path of the alignment. Each element of the
+path is a pair of indices {i, j} aligning x[i] with y[j]. The path starts at
+{0, 0} and ends at {len(x)-1, len(y)-1}. The cost is the sum of dist over the
+path. If dist is nil Euclidean is used.
+The function panics if x or y is empty.
+*/
+func DTW(x, y [][]float64, dist DistanceFunc) (cost float64, path [][2]int) {
+	return DTWBand(x, y, dist, -1)
+}
+
+/*
+DTWBand returns the DTW alignment of x and y constrained to a Sakoe-Chiba band:
+x[i] can only be aligned with y[j] if |i-j| <= band. The band is widened to
+|len(x)-len(y)| if it is narrower. A negative band is unconstrained.
+See DTW.
+*/
+func DTWBand(x, y [][]float64, dist DistanceFunc, band int) (cost float64, path [][2]int) {
+	cost, path, err := DTWBandErr(x, y, dist, band)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+DTWErr returns DTW(x, y, dist) or an error wrapping ErrLength if x or y is
+empty.
+*/
+func DTWErr(x, y [][]float64, dist DistanceFunc) (cost float64, path [][2]int, err error) {
+	return DTWBandErr(x, y, dist, -1)
+}
+
+/*
+DTWBandErr returns DTWBand(x, y, dist, band) or an error wrapping ErrLength if
+x or y is empty.
+*/
+func DTWBandErr(x, y [][]float64, dist DistanceFunc, band int) (cost float64, path [][2]int, err error) {
+	n, m := len(x), len(y)
+	if n == 0 || m == 0 {
+		return 0, nil, fmt.Errorf("%w: Empty sequence: len(x)=%d, len(y)=%d", ErrLength, n, m)
+	}
+	if dist == nil {
+		dist = Euclidean
+	}
+	if band < 0 {
+		band = n + m
+	}
+	if d := n - m; d > band {
+		band = d
+	} else if -d > band {
+		band = -d
+	}
+	// D[i+1][j+1] is the cost of the best alignment of x[:i+1] and y[:j+1]
+	D := make([][]float64, n+1)
+	for i := range D {
+		D[i] = make([]float64, m+1)
+		for j := range D[i] {
+			D[i][j] = math.Inf(1)
+		}
+	}
+	D[0][0] = 0
+	for i := 1; i <= n; i++ {
+		from := i - band
+		if from < 1 {
+			from = 1
+		}
+		for j := from; j <= m && j <= i+band; j++ {
+			D[i][j] = dist(x[i-1], y[j-1]) +
+				math.Min(D[i-1][j-1], math.Min(D[i-1][j], D[i][j-1]))
+		}
+	}
+	// Backtrack
+	for i, j := n, m; i > 0 && j > 0; {
+		path = append(path, [2]int{i - 1, j - 1})
+		switch {
+		case D[i-1][j-1] <= D[i-1][j] && D[i-1][j-1] <= D[i][j-1]:
+			i, j = i-1, j-1
+		case D[i-1][j] <= D[i][j-1]:
+			i--
+		default:
+			j--
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return D[n][m], path, nil
+}
@@ -0,0 +1,996 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+/*
+Scaling filter coefficients h[0..2N-1] of the orthogonal wavelets, indexed by
+order N. The coefficients were computed by spectral factorisation as described
+in:
+
+	Ten Lectures on Wavelets. I. Daubechies. SIAM 1992. Sections 6.1, 8.1, 8.2
+
+and refined by Newton iteration until the orthonormality and vanishing moment
+conditions hold to machine precision.
+*/
+
+// daubechiesCoefficients has the extremal phase filters db1 to db20.
+var daubechiesCoefficients = [][]float64{
+	1: {
+		0.7071067811865475,
+		0.7071067811865475,
+	},
+	2: {
+		0.48296291314453416,
+		0.8365163037378079,
+		0.2241438680420134,
+		-0.1294095225512604,
+	},
+	3: {
+		0.3326705529500826,
+		0.8068915093110925,
+		0.4598775021184916,
+		-0.13501102001025456,
+		-0.08544127388202667,
+		0.03522629188570953,
+	},
+	4: {
+		0.23037781330889642,
+		0.7148465705529156,
+		0.630880767929859,
+		-0.027983769416859795,
+		-0.18703481171909306,
+		0.03084138183556076,
+		0.03288301166688518,
+		-0.010597401785069023,
+	},
+	5: {
+		0.1601023979741929,
+		0.6038292697971899,
+		0.7243085284377728,
+		0.13842814590132047,
+		-0.24229488706638191,
+		-0.03224486958463828,
+		0.07757149384004564,
+		-0.006241490212798269,
+		-0.012580751999081981,
+		0.0033357252854737656,
+	},
+	6: {
+		0.11154074335010945,
+		0.49462389039845306,
+		0.7511339080210953,
+		0.3152503517091977,
+		-0.22626469396543974,
+		-0.12976686756726205,
+		0.09750160558732307,
+		0.0275228655303058,
+		-0.03158203931748607,
+		0.0005538422011614883,
+		0.004777257510945523,
+		-0.0010773010853084822,
+	},
+	7: {
+		0.07785205408500886,
+		0.39653931948191656,
+		0.7291320908462349,
+		0.4697822874051941,
+		-0.14390600392856445,
+		-0.2240361849938752,
+		0.07130921926683026,
+		0.08061260915108279,
+		-0.038029936935014316,
+		-0.01657454163066666,
+		0.01255099855609969,
+		0.0004295779729213594,
+		-0.0018016407040474618,
+		0.0003537137999745138,
+	},
+	8: {
+		0.0544158422431038,
+		0.3128715909142991,
+		0.6756307362972891,
+		0.5853546836542076,
+		-0.015829105256347887,
+		-0.2840155429615473,
+		0.0004724845739124339,
+		0.12874742662047878,
+		-0.0173693010018072,
+		-0.044088253930794984,
+		0.013981027917398227,
+		0.008746094047405874,
+		-0.004870352993451601,
+		-0.00039174037337695307,
+		0.0006754494064505738,
+		-0.00011747678412477016,
+	},
+	9: {
+		0.03807794736387857,
+		0.24383467461259126,
+		0.6048231236901125,
+		0.6572880780512996,
+		0.13319738582500518,
+		-0.29327378327917475,
+		-0.09684078322297555,
+		0.14854074933810635,
+		0.03072568147933339,
+		-0.06763282906133025,
+		0.0002509471148314131,
+		0.022361662123679328,
+		-0.0047232047577514735,
+		-0.004281503682463471,
+		0.0018476468830562563,
+		0.00023038576352319126,
+		-0.0002519631889427106,
+		3.934732031627175e-05,
+	},
+	10: {
+		0.026670057900555367,
+		0.18817680007769036,
+		0.5272011889317234,
+		0.6884590394536035,
+		0.28117234366058136,
+		-0.24984642432731316,
+		-0.1959462743773802,
+		0.12736934033579195,
+		0.09305736460357497,
+		-0.07139414716639735,
+		-0.02945753682187701,
+		0.03321267405934164,
+		0.0036065535669562664,
+		-0.010733175483330727,
+		0.0013953517470529065,
+		0.0019924052951850748,
+		-0.0006858566949597028,
+		-0.00011646685512929884,
+		9.358867032007476e-05,
+		-1.3264202894521964e-05,
+	},
+	11: {
+		0.018694297761468585,
+		0.14406702115060974,
+		0.4498997643560169,
+		0.6856867749161951,
+		0.41196436894795185,
+		-0.1622752450274537,
+		-0.2742308468179687,
+		0.06604358819665829,
+		0.1498120124663921,
+		-0.04647995511667294,
+		-0.06643878569503303,
+		0.0313350902190429,
+		0.020840904360184292,
+		-0.015364820906201265,
+		-0.003340858873015219,
+		0.004928417656059088,
+		-0.0003085928588150907,
+		-0.0008930232506662302,
+		0.0002491525235528171,
+		5.443907469935078e-05,
+		-3.463498418697604e-05,
+		4.4942742772352076e-06,
+	},
+	12: {
+		0.013112257957226621,
+		0.10956627282116624,
+		0.3773551352141697,
+		0.6571987225792829,
+		0.5158864784278707,
+		-0.044763885653695004,
+		-0.31617845375279835,
+		-0.023779257256131888,
+		0.1824786059275896,
+		0.005359569674392432,
+		-0.09643212009652144,
+		0.010849130255802456,
+		0.041546277495098295,
+		-0.012218649069743629,
+		-0.012840825198308224,
+		0.006711499008796724,
+		0.0022486072409969846,
+		-0.0021795036186286806,
+		6.5451282125269626e-06,
+		0.0003886530628210277,
+		-8.850410920822355e-05,
+		-2.4241545757033167e-05,
+		1.2776952219380644e-05,
+		-1.5290717580685423e-06,
+	},
+	13: {
+		0.00920213353895908,
+		0.08286124387287928,
+		0.3119963221603763,
+		0.6110558511587357,
+		0.5888895704312773,
+		0.08698572617977869,
+		-0.31497290771136854,
+		-0.1245767307509081,
+		0.17947607942932303,
+		0.07294893365683212,
+		-0.1058076181879353,
+		-0.02648840647536335,
+		0.056139477100289076,
+		0.0023799722540558867,
+		-0.023831420710321162,
+		0.003923941448803417,
+		0.007255589401610598,
+		-0.0027619112346572907,
+		-0.0013156739118885578,
+		0.0009323261308658421,
+		4.925152512572272e-05,
+		-0.00016512898855597282,
+		3.067853757924464e-05,
+		1.0441930571367636e-05,
+		-4.700416479343637e-06,
+		5.220035098435343e-07,
+	},
+	14: {
+		0.0064611534600720446,
+		0.0623647588492682,
+		0.2548502677922144,
+		0.5543056179404033,
+		0.6311878491050519,
+		0.21867068775994303,
+		-0.27168855227823163,
+		-0.21803352999399347,
+		0.13839521386433393,
+		0.1399890165849896,
+		-0.0867484115679261,
+		-0.0715489555044104,
+		0.055237126259166006,
+		0.0269814083081062,
+		-0.030185351540420523,
+		-0.005615049530425357,
+		0.012789493266362828,
+		-0.0007462189892554097,
+		-0.003849638868033363,
+		0.0010616910856065182,
+		0.0007080211542377486,
+		-0.0003868319473133247,
+		-4.1777245770658846e-05,
+		6.875504252708174e-05,
+		-1.0337209184554145e-05,
+		-4.389704901799118e-06,
+		1.7249946753720565e-06,
+		-1.7871399683151023e-07,
+	},
+	15: {
+		0.004538537361614897,
+		0.04674339489307918,
+		0.206023863988055,
+		0.492631771709659,
+		0.6458131403574027,
+		0.3390025354520203,
+		-0.19320413961140076,
+		-0.2888825965655778,
+		0.06528295285081488,
+		0.19014671400619376,
+		-0.03966617655711648,
+		-0.1111209360364793,
+		0.03387714392412585,
+		0.05478055058398964,
+		-0.025767007328600183,
+		-0.020810050169448254,
+		0.015083918027830419,
+		0.005101000360341794,
+		-0.006487734560302022,
+		-0.00024175649075472102,
+		0.0019433239803826913,
+		-0.0003734823541386942,
+		-0.0003595652443641197,
+		0.00015589648992171757,
+		2.57926991554014e-05,
+		-2.8133296266285898e-05,
+		3.36298718179109e-06,
+		1.811270407950004e-06,
+		-6.316882325934308e-07,
+		6.133359913364455e-08,
+	},
+	16: {
+		0.003189220925348218,
+		0.034907714323688215,
+		0.16506428348895838,
+		0.4303127228463089,
+		0.6373563320840797,
+		0.44029025688596357,
+		-0.08975108940344871,
+		-0.327063310527857,
+		-0.027918208131808526,
+		0.21119069394702294,
+		0.027340263751405577,
+		-0.13238830556342304,
+		-0.006239722751347385,
+		0.07592423604357706,
+		-0.007588974369527095,
+		-0.03688839769098861,
+		0.010297659641142405,
+		0.01399376885932352,
+		-0.006990014563342062,
+		-0.003644279621282934,
+		0.00312802338110616,
+		0.00040789698080188924,
+		-0.0009410217493126403,
+		0.00011424152003822164,
+		0.00017478724521416932,
+		-6.103596621094308e-05,
+		-1.3945668987106845e-05,
+		1.133660866053643e-05,
+		-1.0435713422541286e-06,
+		-7.3636567849284e-07,
+		2.308784086697131e-07,
+		-2.1093396299536046e-08,
+	},
+	17: {
+		0.002241807001005704,
+		0.02598539370326193,
+		0.1312149033062976,
+		0.3703507241493567,
+		0.6109966156821827,
+		0.5183157640604205,
+		0.027314970411114952,
+		-0.32832074836251834,
+		-0.1265997522230053,
+		0.1973105895626782,
+		0.1011354891836106,
+		-0.12681569177702,
+		-0.057091419636660444,
+		0.08110598665439557,
+		0.022312336181477186,
+		-0.046922438390413115,
+		-0.003270955537503107,
+		0.022733676585116477,
+		-0.003042989980851552,
+		-0.008602921521039152,
+		0.0029679966915290806,
+		0.0023012052424377654,
+		-0.0014368453048902623,
+		-0.0003281325194756898,
+		0.0004394654278131974,
+		-2.5610109562755282e-05,
+		-8.204803203545006e-05,
+		2.318681380105866e-05,
+		6.990600986196541e-06,
+		-4.505942477823183e-06,
+		3.0165496103477907e-07,
+		2.9577009337097674e-07,
+		-8.423948447102081e-08,
+		7.267492969503852e-09,
+	},
+	18: {
+		0.0015763102184555523,
+		0.019288531724305655,
+		0.10358846582314084,
+		0.31467894133867497,
+		0.5718268077681801,
+		0.5718016548875701,
+		0.14722311196584215,
+		-0.29365404073874285,
+		-0.21648093400194043,
+		0.1495339755683413,
+		0.16708131276039836,
+		-0.09233188415354468,
+		-0.10675224665683186,
+		0.06488721621375758,
+		0.057051247735483236,
+		-0.0445261419036829,
+		-0.02373321039325115,
+		0.026670705926162386,
+		0.006262167952628722,
+		-0.013051480945857393,
+		0.00011863003455931261,
+		0.004943343604838421,
+		-0.0011187326671052622,
+		-0.001340596298037665,
+		0.0006284656829083939,
+		0.00021358156183166557,
+		-0.00019864855227094512,
+		-1.5359170529977696e-07,
+		3.74123787966502e-05,
+		-8.520602535690888e-06,
+		-3.332634477722842e-06,
+		1.7687129830997962e-06,
+		-7.691632688126014e-08,
+		-1.1760987666675002e-07,
+		3.068835862119735e-08,
+		-2.5079344541951286e-09,
+	},
+	19: {
+		0.0011086697635351028,
+		0.014281098454765113,
+		0.08127811328445278,
+		0.26438843178733645,
+		0.5244363775158721,
+		0.6017045491111968,
+		0.26089495254178163,
+		-0.22809139429819342,
+		-0.285838631697146,
+		0.07465226979981596,
+		0.21234974327090178,
+		-0.03351854197754655,
+		-0.14278569500758337,
+		0.02758435067728245,
+		0.08690675552509444,
+		-0.026501236277888193,
+		-0.04567422625117169,
+		0.021623767418961104,
+		0.019375549872117304,
+		-0.013988388678251213,
+		-0.005866922272994529,
+		0.00704074736448062,
+		0.0007689543568759822,
+		-0.002687551799004386,
+		0.0003418086537133372,
+		0.0007358025199310284,
+		-0.00026067613557476446,
+		-0.00012460079163272134,
+		8.711270462368602e-05,
+		5.105950482374835e-06,
+		-1.6640176288902296e-05,
+		3.0109643149573374e-06,
+		1.5319314761353158e-06,
+		-6.862755655478192e-07,
+		1.4470882978823832e-08,
+		4.636937774841248e-08,
+		-1.1164020668433989e-08,
+		8.666848837838421e-10,
+	},
+	20: {
+		0.0007799536151118565,
+		0.010549394642131752,
+		0.06342378054571329,
+		0.21994211378169573,
+		0.47269618561101756,
+		0.6104932389510586,
+		0.3615022982174791,
+		-0.1392120885755817,
+		-0.3267868002894076,
+		-0.016727087748752624,
+		0.22829105081831774,
+		0.03985024600300282,
+		-0.15545875070606124,
+		-0.0247168270011692,
+		0.1022917191311221,
+		0.005632246637405321,
+		-0.061722899552598035,
+		0.0058746819280847355,
+		0.032294299460932666,
+		-0.008789324967535114,
+		-0.013810526090291247,
+		0.006721627309236323,
+		0.004420542364834223,
+		-0.0035814942558662533,
+		-0.0008315621658238185,
+		0.0013925596160107479,
+		-5.3497599577807284e-05,
+		-0.00038510474746960416,
+		0.00010153288965935776,
+		6.774280804311123e-05,
+		-3.7105861753615364e-05,
+		-4.376143845053206e-06,
+		7.241248272478373e-06,
+		-1.0119940082238385e-06,
+		-6.84707958766246e-07,
+		2.633924223057551e-07,
+		2.0143222172578407e-10,
+		-1.8148432477977906e-08,
+		4.056127055041195e-09,
+		-2.998836489913616e-10,
+	},
+}
+
+// symletCoefficients has the least asymmetric filters sym2 to sym20.
+var symletCoefficients = [][]float64{
+	2: {
+		0.48296291314453416,
+		0.8365163037378079,
+		0.2241438680420134,
+		-0.1294095225512604,
+	},
+	3: {
+		0.3326705529500826,
+		0.8068915093110925,
+		0.4598775021184916,
+		-0.13501102001025456,
+		-0.08544127388202667,
+		0.03522629188570953,
+	},
+	4: {
+		0.03222310060405147,
+		-0.012603967262031307,
+		-0.09921954357663354,
+		0.29785779560530606,
+		0.8037387518051321,
+		0.49761866763277496,
+		-0.0296355276460025,
+		-0.07576571478950221,
+	},
+	5: {
+		0.027333068344998768,
+		0.029519490925706268,
+		-0.03913424930231386,
+		0.19939753397685556,
+		0.7234076904040407,
+		0.633978963456792,
+		0.016602105764510895,
+		-0.17532808990805623,
+		-0.021101834024689046,
+		0.019538882735249827,
+	},
+	6: {
+		0.015404109327044828,
+		0.003490712084222156,
+		-0.11799011114852002,
+		-0.04831174258569803,
+		0.49105594192797375,
+		0.787641141028651,
+		0.3379294217281658,
+		-0.0726375227863766,
+		-0.021060292512370835,
+		0.04472490177078137,
+		0.0017677118642540045,
+		-0.007800708325032381,
+	},
+	7: {
+		0.012015419283549178,
+		0.017213376300804495,
+		-0.06490800354718843,
+		-0.06413128980738579,
+		0.3602184609062601,
+		0.7819215932917282,
+		0.4836109156822678,
+		-0.05680447688966699,
+		-0.10101092086842031,
+		0.04474234946835235,
+		0.020464207577546023,
+		-0.018126605131338444,
+		-0.0032832978474668095,
+		0.0022918339540537696,
+	},
+	8: {
+		0.0018899503327676878,
+		-0.0003029205147241324,
+		-0.014952258337062188,
+		0.0038087520138944857,
+		0.04913717967373026,
+		-0.027219029917103458,
+		-0.051945838107881816,
+		0.3644418948361789,
+		0.777185751699628,
+		0.48135965125905344,
+		-0.06127335906781105,
+		-0.14329423835127264,
+		0.007607487324976602,
+		0.03169508781152598,
+		-0.0005421323318000095,
+		-0.003382415951005001,
+	},
+	9: {
+		0.0014009155259146575,
+		0.000619780888985508,
+		-0.013271967781817145,
+		-0.011528210207679196,
+		0.0302248788582752,
+		0.0005834627461249634,
+		-0.054568958430833404,
+		0.23876091460730525,
+		0.7178970827644126,
+		0.6173384491409342,
+		0.035272488035271006,
+		-0.1915508312972843,
+		-0.018233770779395492,
+		0.06207778930288575,
+		0.008859267493400269,
+		-0.010264064027633123,
+		-0.000473154498680044,
+		0.0010694900329086124,
+	},
+	10: {
+		0.0008625782262259737,
+		0.0007154205420543414,
+		-0.007056764062587309,
+		0.0005956827837425237,
+		0.0496861266469429,
+		0.02624036505844901,
+		-0.12155210554854894,
+		-0.01501923883913784,
+		0.5137098733480264,
+		0.7669548365606096,
+		0.3402160130234621,
+		-0.08787871151197514,
+		-0.0670899078083818,
+		0.033842354663575186,
+		-0.0008687521096892737,
+		-0.023005461353497497,
+		-0.0011404297952173285,
+		0.005071649198531798,
+		0.00034014926631481025,
+		-0.00041011591580439837,
+	},
+	11: {
+		0.00012227468089023554,
+		-0.00024605048313620215,
+		-0.0016456213226495524,
+		0.0024053042957380482,
+		0.009874122155829139,
+		-0.008585286331503345,
+		-0.02734703511111979,
+		0.023721547819585295,
+		0.018254152442556508,
+		-0.1499464788291983,
+		-0.08151515741285749,
+		0.4520007834697994,
+		0.768526679794067,
+		0.4078687489088601,
+		-0.028693838341039696,
+		-0.054082711096476464,
+		0.05094170715975539,
+		0.03720235722287957,
+		-0.002793177108764772,
+		-0.003918553158856674,
+		0.0013826742498805069,
+		0.0006871193688560963,
+	},
+	12: {
+		9.767610247723181e-05,
+		-8.418262000974766e-05,
+		-0.0013865502623702497,
+		0.0006610376737514802,
+		0.008634230791720498,
+		-0.0005948327807239555,
+		-0.025493025089340933,
+		0.0018619254598863965,
+		0.03068674351509149,
+		-0.08017578174217267,
+		-0.08927100096836153,
+		0.34345150160951954,
+		0.7608721850415804,
+		0.5166743899411826,
+		-0.007517992473075112,
+		-0.12359121292129567,
+		0.03125685988359173,
+		0.060058596234244845,
+		-0.0012870333171529733,
+		-0.013053840998593608,
+		0.0006915974586788293,
+		0.0021044473356296787,
+		-0.00017690949629193403,
+		-0.00020526600487138016,
+	},
+	13: {
+		7.04298669069627e-05,
+		3.6905373423238945e-05,
+		-0.0007213643851363755,
+		0.000413261198841678,
+		0.005674853760123337,
+		-0.001492447274258731,
+		-0.020749686325520662,
+		0.01761829688064503,
+		0.09292603089914396,
+		0.008819757670429793,
+		-0.14049009311367566,
+		0.11023022302128674,
+		0.644564383901157,
+		0.6957391505615691,
+		0.19770481877126617,
+		-0.12436246075150326,
+		-0.059750627717956396,
+		0.013862497435838464,
+		-0.017211642726304398,
+		-0.020216768133395485,
+		0.0052963597387218585,
+		0.007526225389968166,
+		-0.00017094285852957183,
+		-0.001136063438927968,
+		-3.5738623648715924e-05,
+		6.820325263074348e-05,
+	},
+	14: {
+		5.693577237358933e-05,
+		9.844328943538827e-05,
+		-0.00046159785048319055,
+		-0.0001224144564959642,
+		0.0044865481969773345,
+		0.002991921543643104,
+		-0.013747270363846681,
+		0.004544857390614731,
+		0.07974095078234944,
+		0.06073812238334528,
+		-0.07320906911265405,
+		0.06365758606087313,
+		0.5582428759931944,
+		0.7382784583937355,
+		0.2804049223296099,
+		-0.16119362227984527,
+		-0.13415725012516444,
+		0.012842975012276873,
+		0.0032293087107599996,
+		-0.025270538300147583,
+		0.001725863589678716,
+		0.013228961153639286,
+		0.001152210106118778,
+		-0.003034021686311831,
+		-0.0003927126872855805,
+		0.0003663334035676399,
+		3.5065844919292074e-05,
+		-2.0280721782694694e-05,
+	},
+	15: {
+		6.772367072370828e-06,
+		-1.5597226975927582e-05,
+		-0.00011885827717361488,
+		0.00022996241461875394,
+		0.0010026940752123278,
+		-0.001451242810390478,
+		-0.005138184299990961,
+		0.004908598855560862,
+		0.016221442803756266,
+		-0.00999554108334207,
+		-0.025023861838290522,
+		0.03143755358173885,
+		0.00015373429075283342,
+		-0.1860736599737523,
+		-0.10819627176655924,
+		0.4234680730689151,
+		0.7556901676698956,
+		0.43720361640736033,
+		0.0032629670324980835,
+		-0.039402013684543685,
+		0.07330794380479744,
+		0.05454299324802423,
+		-0.007519264534115428,
+		-0.01032509905699403,
+		0.003882240781658949,
+		0.0028789242627891725,
+		-0.0005194040351814532,
+		-0.0003408898500403673,
+		9.466311221485594e-05,
+		4.110303357907688e-05,
+	},
+	16: {
+		5.3590380462688635e-06,
+		-6.387996260198675e-06,
+		-0.00010014852036965556,
+		8.651115502326605e-05,
+		0.0008745695867130806,
+		-0.0004449474164188899,
+		-0.004539916546908626,
+		0.000854774949490164,
+		0.014702157960279292,
+		0.0005364340248222056,
+		-0.02620964925134774,
+		0.007986606099499385,
+		0.019521339077629544,
+		-0.11673387364225846,
+		-0.11888273199735602,
+		0.3231022390643002,
+		0.7467880503688473,
+		0.5367441345860698,
+		0.02999397311013679,
+		-0.10486312987748228,
+		0.046694175567007515,
+		0.0776635270454663,
+		-0.0029934480320459853,
+		-0.022975152888160377,
+		0.0016338240562740684,
+		0.006219665992202224,
+		-0.00048378332395056337,
+		-0.001214622299813307,
+		0.00011797321121146736,
+		0.00016355529607212985,
+		-1.496311761917041e-05,
+		-1.2552906004587462e-05,
+	},
+	17: {
+		4.2973433273384325e-06,
+		2.7801266938260947e-06,
+		-6.293702597546155e-05,
+		-1.3506383399800678e-05,
+		0.00047599638026319844,
+		-0.00013864230268100783,
+		-0.0027416759756782555,
+		0.0008567700701927597,
+		0.010482366933016353,
+		-0.004819212803181161,
+		-0.03329138349230665,
+		0.01790395221438856,
+		0.1047546148421952,
+		0.017271178210603286,
+		-0.11856693261099764,
+		0.14239835041510573,
+		0.6507166292043745,
+		0.6814889953443232,
+		0.18053958458075456,
+		-0.1550760053497068,
+		-0.08607087472063658,
+		0.016158808725918728,
+		-0.007261634750933521,
+		-0.018038897241902396,
+		0.009952982523507735,
+		0.012396988366635006,
+		-0.0019054076898564127,
+		-0.003932325279795176,
+		5.840042869517051e-05,
+		0.0007198270642145913,
+		2.5207933140674154e-05,
+		-7.607124405603441e-05,
+		-2.452716342574289e-06,
+		3.7912531943318928e-06,
+	},
+	18: {
+		-1.2580338547932722e-06,
+		1.7186501522618452e-06,
+		2.6357994114459055e-05,
+		-2.7261330102012238e-05,
+		-0.00025993068032953773,
+		0.00018317634592472437,
+		0.001575619446172251,
+		-0.0006102889525480541,
+		-0.0063532907658747,
+		0.0006733472927491016,
+		0.016788652469635987,
+		0.0008439635945940985,
+		-0.024985899226378033,
+		0.012195807040426871,
+		0.014959209067817192,
+		-0.13395832251912196,
+		-0.13652699127666498,
+		0.3088447699528909,
+		0.738921800291989,
+		0.5464836412657929,
+		0.049516425564839935,
+		-0.09268544547127487,
+		0.054579243881462756,
+		0.08497096776576382,
+		-0.0031339578106216164,
+		-0.026838027389539835,
+		0.0024181899362153815,
+		0.008693623896254917,
+		-0.0006074549319547644,
+		-0.0019960365796253777,
+		0.0002253256266496406,
+		0.0003729517956497459,
+		-3.955336477492516e-05,
+		-4.4946600948409056e-05,
+		4.292998104455183e-06,
+		3.1424295089144674e-06,
+	},
+	19: {
+		2.279701054915817e-06,
+		5.026578612018561e-06,
+		-2.7815549678947013e-05,
+		-3.9927529696504364e-05,
+		0.00027913178421923367,
+		0.00036825182956642835,
+		-0.0013821275730029035,
+		-0.0013024544430619098,
+		0.006942263251359237,
+		0.007588766490425077,
+		-0.016791165844580794,
+		-0.011086966841053139,
+		0.06944391840018393,
+		0.08336200752273876,
+		-0.05726949810743009,
+		-0.02654073392943776,
+		0.4128274143169043,
+		0.7515628048102352,
+		0.4572370181555456,
+		-0.06620360217723233,
+		-0.18054499731541748,
+		-0.014749913015557019,
+		0.025248732786120182,
+		-0.029352513965840883,
+		-0.014453448882067139,
+		0.01903143169089472,
+		0.007414951110336279,
+		-0.007505974951261026,
+		-0.00232217122348414,
+		0.0024898137065268856,
+		0.0006158548241052416,
+		-0.0006073492401261143,
+		-0.0001291652988698425,
+		9.698747363069934e-05,
+		1.6536002962877663e-05,
+		-9.294311116750678e-06,
+		-9.293517128896017e-07,
+		4.2148830124661007e-07,
+	},
+	20: {
+		-7.701110829327121e-07,
+		-1.0618448691652076e-06,
+		1.2656515384875111e-05,
+		1.2914225610705972e-05,
+		-0.00011200768892318305,
+		-8.0684738103462e-05,
+		0.0006689298444998731,
+		0.00031616129560093275,
+		-0.003007802312520667,
+		-0.0008379562792805181,
+		0.011051448395596216,
+		0.0027053671409613994,
+		-0.03144105538074789,
+		-0.005089926063563653,
+		0.08843471821392705,
+		0.05703445234251174,
+		-0.0885141298077132,
+		0.05881047754130255,
+		0.5502894121985197,
+		0.7341772641927573,
+		0.3039910867358486,
+		-0.14306979570277817,
+		-0.14252887994676,
+		0.01422942849611252,
+		0.01755530037710667,
+		-0.023185453210451318,
+		0.0007567133933860054,
+		0.018156532203242072,
+		0.0004120407956368395,
+		-0.008043613497940498,
+		-0.0006924581973358658,
+		0.002417211646069498,
+		0.0002875504227851211,
+		-0.0005131528650172113,
+		-6.330061703066955e-05,
+		7.52289082240141e-05,
+		7.747126284454082e-06,
+		-6.916320202201232e-06,
+		-4.1877031349816004e-07,
+		3.0371636101766874e-07,
+	},
+}
+
+// coifletCoefficients has the filters coif1 to coif3, which have length 6N.
+var coifletCoefficients = [][]float64{
+	1: {
+		-0.07273261951252645,
+		0.33789766245748176,
+		0.8525720202116004,
+		0.3848648468648578,
+		-0.07273261951252646,
+		-0.015655728135791996,
+	},
+	2: {
+		0.016387336463203696,
+		-0.04146493678687186,
+		-0.06737255472372573,
+		0.38611006682276316,
+		0.8127236354494135,
+		0.4170051844232386,
+		-0.07648859907828068,
+		-0.05943441864643083,
+		0.023680171946847666,
+		0.005611434819368762,
+		-0.001823208870910994,
+		-0.0007205494455203453,
+	},
+	3: {
+		-0.003793512864380279,
+		0.007782596425671819,
+		0.0234526961420745,
+		-0.0657719112814639,
+		-0.06112339000296757,
+		0.4051769024091048,
+		0.7937772226260839,
+		0.4284834763773872,
+		-0.07179982161915653,
+		-0.08230192710631225,
+		0.03455502757330181,
+		0.01588054486367412,
+		-0.009007976136733034,
+		-0.00257451768813752,
+		0.0011175187708311303,
+		0.0004662169598204507,
+		-7.098330250638246e-05,
+		-3.4599773197296145e-05,
+	},
+}
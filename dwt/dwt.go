@@ -18,15 +18,21 @@ Package DWT has functions supporting the Discrete Wavelet Transform.
 package dwt
 
 import (
+	"context"
+	"fmt"
 	"math"
+	"sync"
 
 	"github.com/goccmack/godsp"
 )
 
 type Transform struct {
 	st       []float64
+	n        int
 	level    int
 	sections []*transformSection
+	wavelet  Wavelet
+	workers  int
 }
 
 type transformSection struct {
@@ -35,24 +41,124 @@ type transformSection struct {
 }
 
 // Daubechies4 returns the DWT with Daubechies 4 coeficients to level.
-func Daubechies4(s []float64, level int) *Transform {
+func Daubechies4(s []float64, level int, opts ...Option) *Transform {
+	return NewTransform(s, D4, level, opts...)
+}
+
+/*
+Daubechies4Context returns Daubechies4(s, level, opts...) or ctx.Err() if ctx is
+done before the transform is complete. See NewTransformContext.
+*/
+func Daubechies4Context(ctx context.Context, s []float64, level int, opts ...Option) (*Transform, error) {
+	return NewTransformContext(ctx, s, D4, level, opts...)
+}
+
+// Haar returns the DWT with Haar coeficients to level.
+func Haar(s []float64, level int, opts ...Option) *Transform {
+	return NewTransform(s, HaarWavelet, level, opts...)
+}
+
+/*
+NewTransform returns the DWT of s with wavelet w to level.
+
+By default s is transformed in sections of length 2^k and a tail of s that is
+shorter than 64*2^level is not transformed. If a padding mode is selected with
+WithPadding, s is extended to an integer multiple of 2^level and transformed as
+a single section.
+*/
+func NewTransform(s []float64, w Wavelet, level int, opts ...Option) *Transform {
+	t, _ := NewTransformContext(context.Background(), s, w, level, opts...)
+	return t
+}
+
+/*
+NewTransformContext returns NewTransform(s, w, level, opts...) or ctx.Err() if
+ctx is done before the transform is complete. ctx is checked before each level
+of each section of s.
+*/
+func NewTransformContext(ctx context.Context, s []float64, w Wavelet, level int, opts ...Option) (*Transform, error) {
+	o := getOptions(opts)
 	t := &Transform{
-		st:       make([]float64, len(s)),
-		level:    level,
-		sections: getTransformSections(len(s), level),
+		n:       len(s),
+		level:   level,
+		wavelet: w,
+		workers: o.workers,
 	}
-	copy(t.st, s)
-	for _, section := range t.sections {
+	if o.padding == NoPadding {
+		t.st = make([]float64, len(s))
+		copy(t.st, s)
+		t.sections = getTransformSections(len(s), level)
+	} else {
+		blk := godsp.Pow2(level)
+		t.st = pad(s, (len(s)+blk-1)/blk*blk, o.padding)
+		t.sections = []*transformSection{{start: 0, size: len(t.st)}}
+	}
+	report := newProgress(o.progress, len(t.sections)*level)
+	t.forEachSection(func(section *transformSection) {
 		scaleSize := section.size
 		for l := level; l > 0; l-- {
+			if ctx.Err() != nil {
+				return
+			}
 			max := section.start + scaleSize
-			split(t.st[section.start:max])
-			daubechies4(t.st[section.start:max])
+			w.Forward(t.st[section.start:max])
 			scaleSize /= 2
+			report()
 		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	return t, nil
+}
 
-	return t
+/*
+forEachSection calls f for every section of t. The sections are processed
+concurrently by t.workers goroutines.
+*/
+func (t *Transform) forEachSection(f func(*transformSection)) {
+	forEachSection(t.sections, t.workers, f)
+}
+
+/*
+forEachSection calls f for every section of sections. The sections are
+processed concurrently by workers goroutines.
+*/
+func forEachSection(sections []*transformSection, workers int, f func(*transformSection)) {
+	work := make(chan *transformSection)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for section := range work {
+				f(section)
+			}
+		}()
+	}
+	for _, section := range sections {
+		work <- section
+	}
+	close(work)
+	wg.Wait()
+}
+
+/*
+newProgress returns a function that counts the calls to it by concurrent
+goroutines and reports the count out of total to progress, which may be nil.
+*/
+func newProgress(progress godsp.ProgressFunc, total int) func() {
+	if progress == nil {
+		return func() {}
+	}
+	var mu sync.Mutex
+	done := 0
+	return func() {
+		mu.Lock()
+		done++
+		progress(done, total)
+		mu.Unlock()
+	}
 }
 
 /*
@@ -87,9 +193,9 @@ where the even elements are in the first half
 of the vector and the odd elements are in the
 second half.
 */
-func split(s []float64) {
+func split[T godsp.Float](s []T) {
 	half := len(s) / 2
-	odd := make([]float64, half)
+	odd := make([]T, half)
 	for i := 1; i < len(s); i += 2 {
 		odd[i/2] = s[i]
 	}
@@ -101,25 +207,41 @@ func split(s []float64) {
 	}
 }
 
+/*
+Merge is the inverse of split. It interleaves the even elements in the first
+half of s with the odd elements in the second half.
+*/
+func merge[T godsp.Float](s []T) {
+	half := len(s) / 2
+	odd := make([]T, half)
+	copy(odd, s[half:])
+	for i := half - 1; i > 0; i-- {
+		s[2*i] = s[i]
+	}
+	for i, v := range odd {
+		s[2*i+1] = v
+	}
+}
+
 /*
 After: Ripples section 3.4
 */
-func daubechies4(s []float64) {
+func daubechies4[T godsp.Float](s []T) {
 	half := len(s) / 2
 
 	// Update 1:
 	for n := 0; n < half; n++ {
-		s[n] = s[n] + math.Sqrt(3)*s[half+n]
+		s[n] = s[n] + T(math.Sqrt(3))*s[half+n]
 	}
 
 	// Predict:
 	s[half] = s[half] -
-		(math.Sqrt(3)/4)*s[0] -
-		((math.Sqrt(3)-2)/4)*s[half-1]
+		T(math.Sqrt(3)/4)*s[0] -
+		T((math.Sqrt(3)-2)/4)*s[half-1]
 	for n := 1; n < half; n++ {
 		s[half+n] = s[half+n] -
-			(math.Sqrt(3)/4)*s[n] -
-			((math.Sqrt(3)-2)/4)*s[n-1]
+			T(math.Sqrt(3)/4)*s[n] -
+			T((math.Sqrt(3)-2)/4)*s[n-1]
 	}
 
 	// Update 2:
@@ -130,12 +252,117 @@ func daubechies4(s []float64) {
 
 	// Normalise:
 	for n := 0; n < half; n++ {
-		s[n] = ((math.Sqrt(3) - 1) / math.Sqrt(2)) * s[n]
-		s[n+half] = ((math.Sqrt(3) + 1) / math.Sqrt(2)) * s[n+half]
+		s[n] = T((math.Sqrt(3)-1)/math.Sqrt(2)) * s[n]
+		s[n+half] = T((math.Sqrt(3)+1)/math.Sqrt(2)) * s[n+half]
 	}
 }
 
-// GetCoefficients returns the coefficients of all transform levels
+/*
+After: Ripples section 2.1
+*/
+func haar[T godsp.Float](s []T) {
+	half := len(s) / 2
+
+	// Predict:
+	for n := 0; n < half; n++ {
+		s[half+n] = s[half+n] - s[n]
+	}
+
+	// Update:
+	for n := 0; n < half; n++ {
+		s[n] = s[n] + s[half+n]/2
+	}
+
+	// Normalise:
+	for n := 0; n < half; n++ {
+		s[n] = T(math.Sqrt(2)) * s[n]
+		s[n+half] = s[n+half] / T(math.Sqrt(2))
+	}
+}
+
+/*
+inverseHaar undoes the lifting steps of haar in reverse order.
+*/
+func inverseHaar[T godsp.Float](s []T) {
+	half := len(s) / 2
+
+	// Undo normalise:
+	for n := 0; n < half; n++ {
+		s[n] = s[n] / T(math.Sqrt(2))
+		s[n+half] = T(math.Sqrt(2)) * s[n+half]
+	}
+
+	// Undo update:
+	for n := 0; n < half; n++ {
+		s[n] = s[n] - s[half+n]/2
+	}
+
+	// Undo predict:
+	for n := 0; n < half; n++ {
+		s[half+n] = s[half+n] + s[n]
+	}
+}
+
+/*
+inverseDaubechies4 undoes the lifting steps of daubechies4 in reverse order.
+*/
+func inverseDaubechies4[T godsp.Float](s []T) {
+	half := len(s) / 2
+
+	// Undo normalise:
+	for n := 0; n < half; n++ {
+		s[n] = s[n] / T((math.Sqrt(3)-1)/math.Sqrt(2))
+		s[n+half] = s[n+half] / T((math.Sqrt(3)+1)/math.Sqrt(2))
+	}
+
+	// Undo update 2:
+	s[half-1] = s[half-1] + s[half]
+	for n := 0; n < half-1; n++ {
+		s[n] = s[n] + s[half+n+1]
+	}
+
+	// Undo predict:
+	s[half] = s[half] +
+		T(math.Sqrt(3)/4)*s[0] +
+		T((math.Sqrt(3)-2)/4)*s[half-1]
+	for n := 1; n < half; n++ {
+		s[half+n] = s[half+n] +
+			T(math.Sqrt(3)/4)*s[n] +
+			T((math.Sqrt(3)-2)/4)*s[n-1]
+	}
+
+	// Undo update 1:
+	for n := 0; n < half; n++ {
+		s[n] = s[n] - T(math.Sqrt(3))*s[half+n]
+	}
+}
+
+/*
+Inverse returns the time domain signal reconstructed from the current
+coefficients of t. The vector returned by GetDecomposition aliases the
+transform, so it may be modified (e.g. to denoise the signal) before calling
+Inverse. t is not modified.
+The padding added by WithPadding is removed from the reconstructed signal.
+*/
+func (t *Transform) Inverse() []float64 {
+	s := make([]float64, len(t.st))
+	copy(s, t.st)
+	t.forEachSection(func(section *transformSection) {
+		scaleSize := section.size / godsp.Pow2(t.level-1)
+		for l := 1; l <= t.level; l++ {
+			max := section.start + scaleSize
+			t.wavelet.Inverse(s[section.start:max])
+			scaleSize *= 2
+		}
+	})
+	return s[:t.n]
+}
+
+/*
+GetCoefficients returns copies of the detail coefficients of all transform
+levels. Element 0 of the result is the first (finest) level. Use
+SetCoefficients to modify the coefficients of the transform.
+*/
 func (t *Transform) GetCoefficients() [][]float64 {
 	cfs := make([][]float64, t.level)
 	for _, s := range t.sections {
@@ -154,15 +381,109 @@ func (t *Transform) GetDownSampledCoefficients() [][]float64 {
 	for _, s := range t.sections {
 		cfs := t.getSectionCoefficients(s)
 		for i, cf := range cfs {
-			if i < t.level-1 {
-				dscfs[i] = append(dscfs[i],
-					godsp.DownSample(cf, godsp.Pow2(t.level-(i+1)))...)
-			}
+			dscfs[i] = append(dscfs[i],
+				godsp.DownSample(cf, godsp.Pow2(t.level-(i+1)))...)
+		}
+	}
+	return dscfs
+}
+
+/*
+GetDownSampledCoefficientsTo returns the coefficients of all the levels resampled
+to length n. Element i of the result is x[i*len(x)/n] of the coefficients x of
+a level. A level without coefficients, such as of a signal shorter than one
+section without padding, is resampled to zeros. The function panics if n < 1.
+*/
+func (t *Transform) GetDownSampledCoefficientsTo(n int) [][]float64 {
+	if n < 1 {
+		panic(fmt.Sprintf("Invalid length %d", n))
+	}
+	cfs := t.GetCoefficients()
+	dscfs := make([][]float64, len(cfs))
+	for l, cf := range cfs {
+		dscfs[l] = make([]float64, n)
+		if len(cf) == 0 {
+			continue
+		}
+		for i := range dscfs[l] {
+			dscfs[l][i] = cf[i*len(cf)/n]
 		}
 	}
 	return dscfs
 }
 
+/*
+CopyCoefficients returns a copy of the detail coefficients of level, where 1 is
+the first (finest) level of the transform.
+The function panics if level is not in [1,t.level].
+*/
+func (t *Transform) CopyCoefficients(level int) []float64 {
+	t.checkLevel(level)
+	var cfs []float64
+	for _, s := range t.sections {
+		cfs = append(cfs, t.getSectionCoefficients(s)[level-1]...)
+	}
+	return cfs
+}
+
+/*
+SetCoefficients copies c to the detail coefficients of level, where 1 is the
+first (finest) level of the transform.
+The function panics if level is not in [1,t.level] or if len(c) is not equal
+to the number of coefficients of level.
+*/
+func (t *Transform) SetCoefficients(level int, c []float64) {
+	t.checkLevel(level)
+	n := 0
+	for _, s := range t.sections {
+		n += s.size / godsp.Pow2(level)
+	}
+	if len(c) != n {
+		panic(fmt.Sprintf("len(c) (%d) != number of coefficients of level %d (%d)",
+			len(c), level, n))
+	}
+	for _, s := range t.sections {
+		n = copy(t.getSectionCoefficients(s)[level-1], c)
+		c = c[n:]
+	}
+}
+
+func (t *Transform) checkLevel(level int) {
+	if level < 1 || level > t.level {
+		panic(fmt.Sprintf("Invalid level %d", level))
+	}
+}
+
+/*
+GetApproximation returns the scaling coefficients of the deepest level of the
+transform.
+*/
+func (t *Transform) GetApproximation() []float64 {
+	app, _ := t.GetAppDetail(t.level)
+	return app
+}
+
+/*
+GetAppDetail returns the approximation and detail coefficients of level, where
+1 is the first (finest) level of the transform. The approximation of a level
+above the deepest level is reconstructed from the deeper levels.
+The function panics if level is not in [1,t.level].
+*/
+func (t *Transform) GetAppDetail(level int) (app, detail []float64) {
+	t.checkLevel(level)
+	for _, s := range t.sections {
+		size := s.size / godsp.Pow2(level)
+		a := make([]float64, size)
+		copy(a, t.st[s.start:s.start+size])
+		for l := t.level; l > level; l-- {
+			t.wavelet.Inverse(a[:s.size/godsp.Pow2(l-1)])
+		}
+		app = append(app, a...)
+		detail = append(detail, t.st[s.start+size:s.start+2*size]...)
+	}
+	return
+}
+
 /*
 GetDecomposition returns the vector containing the DWT decomposion
 */
@@ -170,7 +491,10 @@ func (t *Transform) GetDecomposition() []float64 {
 	return t.st
 }
 
-// GetCoefficients returns the coefficients of all transform levels
+/*
+getSectionCoefficients returns the detail coefficients of all transform levels
+of section s. The returned slices alias the transform.
+*/
 func (t *Transform) getSectionCoefficients(s *transformSection) [][]float64 {
 	cfs := make([][]float64, t.level)
 	half := s.size / 2
@@ -1,8 +1,21 @@
 package dwt
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/internal/benchdata"
+	"github.com/goccmack/godsp/internal/golden"
 )
 
 func Test1(t *testing.T) {
@@ -16,3 +29,515 @@ func Test1(t *testing.T) {
 		t.Errorf("Sum = %d, difference=%f", sum, math.Abs(float64(sum-N)))
 	}
 }
+
+func TestInverse(t *testing.T) {
+	testInverse(t, Daubechies4)
+	testInverse(t, Haar)
+	for _, w := range []Wavelet{Daubechies(1), Daubechies(4), Daubechies(20),
+		Symlet(8), Coiflet(3)} {
+		testInverse(t, func(s []float64, level int, opts ...Option) *Transform {
+			return NewTransform(s, w, level, opts...)
+		})
+	}
+}
+
+func testInverse(t *testing.T, transform func([]float64, int, ...Option) *Transform) {
+	N := 3 * 1024
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i)/10) + 0.25*math.Cos(float64(i)/3)
+	}
+	x := transform(s, 4, WithWorkers(2)).Inverse()
+	if len(x) != N {
+		t.Fatalf("len(x) = %d, want %d", len(x), N)
+	}
+	for i := range s {
+		if math.Abs(x[i]-s[i]) > 1e-9 {
+			t.Fatalf("x[%d] = %f, want %f", i, x[i], s[i])
+		}
+	}
+}
+
+func TestMODWT(t *testing.T) {
+	N := 1000
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i)/10) + 0.25*math.Cos(float64(i)/3)
+	}
+	m := NewMODWT(s, Daubechies(4), 5)
+	for _, w := range m.GetCoefficients() {
+		if len(w) != N {
+			t.Fatalf("len(w) = %d, want %d", len(w), N)
+		}
+	}
+	x := m.Inverse()
+	for i := range s {
+		if math.Abs(x[i]-s[i]) > 1e-9 {
+			t.Fatalf("x[%d] = %f, want %f", i, x[i], s[i])
+		}
+	}
+}
+
+func TestWaveletPacket(t *testing.T) {
+	N := 512
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i)/10) + 0.25*math.Cos(float64(i)*2)
+	}
+	wp := NewWaveletPacket(s, Daubechies(4), 4)
+	x := wp.Inverse(wp.BestBasis(ShannonEntropy))
+	for i := range s {
+		if math.Abs(x[i]-s[i]) > 1e-9 {
+			t.Fatalf("x[%d] = %f, want %f", i, x[i], s[i])
+		}
+	}
+}
+
+func TestPadding(t *testing.T) {
+	s := []float64{1, 2, 3}
+	for mode, want := range map[Padding][]float64{
+		ZeroPadding:      {1, 2, 3, 0, 0, 0, 0},
+		SymmetricPadding: {1, 2, 3, 3, 2, 1, 1},
+		PeriodicPadding:  {1, 2, 3, 1, 2, 3, 1},
+		ReflectPadding:   {1, 2, 3, 2, 1, 2, 3},
+	} {
+		x := pad(s, len(want), mode)
+		for i := range want {
+			if x[i] != want[i] {
+				t.Errorf("mode %d: x = %v, want %v", mode, x, want)
+				break
+			}
+		}
+	}
+
+	N := 1000
+	s = make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	x := Daubechies4(s, 4, WithPadding(SymmetricPadding)).Inverse()
+	if len(x) != N {
+		t.Fatalf("len(x) = %d, want %d", len(x), N)
+	}
+	for i := range s {
+		if math.Abs(x[i]-s[i]) > 1e-9 {
+			t.Fatalf("x[%d] = %f, want %f", i, x[i], s[i])
+		}
+	}
+}
+
+func TestGetAppDetail(t *testing.T) {
+	N := 2048
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	t1, t4 := Daubechies4(s, 1), Daubechies4(s, 4)
+	app1, _ := t1.GetAppDetail(1)
+	app4, _ := t4.GetAppDetail(1)
+	for i := range app1 {
+		if math.Abs(app1[i]-app4[i]) > 1e-9 {
+			t.Fatalf("app4[%d] = %f, want %f", i, app4[i], app1[i])
+		}
+	}
+	if len(t4.GetApproximation()) != N/16 {
+		t.Errorf("len(GetApproximation()) = %d, want %d", len(t4.GetApproximation()), N/16)
+	}
+}
+
+func TestSingleLevel(t *testing.T) {
+	N := 1024
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i)/10) + 0.25*math.Cos(float64(i)/3)
+	}
+	orig := append([]float64{}, s...)
+	for name, w := range map[string]Wavelet{"D4": D4, "Haar": HaarWavelet, "coif2": Coiflet(2)} {
+		app, detail := SingleLevel(s, w)
+		if len(app) != N/2 || len(detail) != N/2 {
+			t.Fatalf("%s: len(app) = %d, len(detail) = %d", name, len(app), len(detail))
+		}
+		// Level 1 of the full transform is the single level transform
+		trApp, trDetail := NewTransform(s, w, 4).GetAppDetail(1)
+		for i := range app {
+			if math.Abs(app[i]-trApp[i]) > 1e-9 || detail[i] != trDetail[i] {
+				t.Fatalf("%s: app[%d], detail[%d] = %f, %f, want %f, %f",
+					name, i, i, app[i], detail[i], trApp[i], trDetail[i])
+			}
+		}
+		x := InverseSingleLevel(app, detail, w)
+		for i := range s {
+			if math.Abs(x[i]-s[i]) > 1e-9 {
+				t.Fatalf("%s: x[%d] = %f, want %f", name, i, x[i], s[i])
+			}
+		}
+	}
+	for i := range s {
+		if s[i] != orig[i] {
+			t.Fatalf("s[%d] was modified", i)
+		}
+	}
+}
+
+func TestStreamer(t *testing.T) {
+	N, level := 1024, 3
+	f := Daubechies(3)
+	s := make([]float64, N+len(f.h)-2)
+	for i := len(f.h) - 2; i < len(s); i++ {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	str := NewStreamer(f, level)
+	detail := make([][]float64, level)
+	for i := len(f.h) - 2; i < len(s); i += 100 {
+		end := i + 100
+		if end > len(s) {
+			end = len(s)
+		}
+		str.Write(s[i:end])
+		_, d := str.Read()
+		for l := range d {
+			detail[l] = append(detail[l], d[l]...)
+		}
+	}
+	f.Forward(s)
+	want := s[len(s)/2:]
+	// Coefficients at the end depend on the periodic extension
+	for i := 0; i < len(detail[0])-len(f.h); i++ {
+		if math.Abs(detail[0][i]-want[i]) > 1e-9 {
+			t.Fatalf("detail[0][%d] = %f, want %f", i, detail[0][i], want[i])
+		}
+	}
+}
+
+func TestSetCoefficients(t *testing.T) {
+	N := 3 * 1024
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	tr := Daubechies4(s, 3)
+	c := tr.CopyCoefficients(2)
+	for i := range c {
+		c[i] = float64(i)
+	}
+	if tr.CopyCoefficients(2)[1] == 1 {
+		t.Fatal("CopyCoefficients aliases the transform")
+	}
+	tr.SetCoefficients(2, c)
+	c1 := tr.GetCoefficients()[1]
+	for i := range c {
+		if c1[i] != c[i] {
+			t.Fatalf("c1[%d] = %f, want %f", i, c1[i], c[i])
+		}
+	}
+}
+
+func TestGetDownSampledCoefficients(t *testing.T) {
+	s := make([]float64, 3*1024)
+	tr := Daubechies4(s, 4)
+	for i, c := range tr.GetDownSampledCoefficients() {
+		if len(c) != 3*1024/16 {
+			t.Errorf("len(c[%d]) = %d, want %d", i, len(c), 3*1024/16)
+		}
+	}
+	for i, c := range tr.GetDownSampledCoefficientsTo(100) {
+		if len(c) != 100 {
+			t.Errorf("len(c[%d]) = %d, want 100", i, len(c))
+		}
+	}
+	// A signal shorter than one section has empty levels without padding
+	s = make([]float64, 100)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	tr = Daubechies4(s, 4)
+	for i, c := range tr.GetDownSampledCoefficientsTo(10) {
+		if len(c) != 10 || godsp.Sum(godsp.Abs(c)) != 0 {
+			t.Errorf("c[%d] = %v, want 10 zeros", i, c)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	N := 3 * 1024
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	path := filepath.Join(t.TempDir(), "dwt.bin")
+	for _, tr := range []*Transform{Daubechies4(s, 3), NewTransform(s, Symlet(4), 3)} {
+		tr.Save(path)
+		x := LoadTransform(path).Inverse()
+		for i := range s {
+			if math.Abs(x[i]-s[i]) > 1e-9 {
+				t.Fatalf("x[%d] = %f, want %f", i, x[i], s[i])
+			}
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	s := make([]float64, 1024)
+	for i := range s {
+		s[i] = math.Cos(float64(i) / 7)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := NewTransform(s, Coiflet(2), 4).WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	tr, err := LoadTransformFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range tr.Inverse() {
+		if math.Abs(f-s[i]) > 1e-9 {
+			t.Fatalf("x[%d] = %f, want %f", i, f, s[i])
+		}
+	}
+	if _, err := LoadTransformFrom(bytes.NewReader(data[:len(data)/2])); err == nil {
+		t.Error("no error for truncated transform")
+	}
+	// Invalid values of the Coiflet(2) transform: 12 filter coefficients end at 125
+	for _, c := range []struct {
+		name   string
+		offset int
+		value  uint64
+	}{
+		{"n", 12, 1025},
+		{"level", 20, 0},
+		{"odd filter length", 25, 11},
+		{"32 bit filter length", 25, 1 << 31},
+		{"section start", 129, 1},
+		{"section size", 137, 3522810254},
+		{"section size not a multiple of 2^level", 137, 1000},
+	} {
+		b := append([]byte{}, data...)
+		switch c.offset {
+		case 20, 25:
+			binary.LittleEndian.PutUint32(b[c.offset:], uint32(c.value))
+		default:
+			binary.LittleEndian.PutUint64(b[c.offset:], c.value)
+		}
+		if _, err := LoadTransformFrom(bytes.NewReader(b)); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+	// A transform without error can be used
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		b := append([]byte{}, data[:200]...)
+		for j := 0; j < 1+rnd.Intn(4); j++ {
+			b[rnd.Intn(len(b))] = byte(rnd.Intn(256))
+		}
+		if tr, err := LoadTransformFrom(bytes.NewReader(append(b, data[200:]...))); err == nil {
+			tr.GetCoefficients()
+			tr.GetApproximation()
+			tr.Inverse()
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	s := make([]float64, 256)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 3)
+	}
+	fname := filepath.Join(t.TempDir(), "summary.json")
+	WriteSummaryJSON(Haar(s, 3, WithPadding(SymmetricPadding)), fname)
+	sum := ReadSummaryJSON(fname)
+	if sum.N != 256 || sum.Wavelet != "Haar" || len(sum.Levels) != 3 {
+		t.Fatalf("summary = %+v", sum)
+	}
+	if l := sum.Levels[2]; l.Level != 3 || l.NumCoefficients != 32 || l.Energy <= 0 || l.Min > l.Max {
+		t.Errorf("level 3 = %+v", l)
+	}
+}
+
+func TestContext(t *testing.T) {
+	s := make([]float64, 1024)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	tr, err := Daubechies4Context(context.Background(), s, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range tr.Inverse() {
+		if math.Abs(f-s[i]) > 1e-9 {
+			t.Fatalf("x[%d] = %f, want %f", i, f, s[i])
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Daubechies4Context(ctx, s, 4); !errors.Is(err, context.Canceled) {
+		t.Errorf("Daubechies4Context: err = %v", err)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	s := make([]float64, 1024+512)
+	var mu sync.Mutex
+	last := 0
+	Daubechies4(s, 3, WithWorkers(2), WithProgress(func(done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		// 2 sections of 3 levels
+		if done != last+1 || total != 6 {
+			t.Errorf("progress %d of %d", done, total)
+		}
+		last = done
+	}))
+	if last != 6 {
+		t.Errorf("progress %d", last)
+	}
+}
+
+// wrapped hides the float32 kernels of a wavelet
+type wrapped struct{ Wavelet }
+
+func TestTransform32(t *testing.T) {
+	N := 3 * 1024
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i)/10) + 0.25*math.Cos(float64(i)/3)
+	}
+	s32 := godsp.Convert[float32](s)
+	for _, w := range []Wavelet{D4, HaarWavelet, Daubechies(4), wrapped{Symlet(4)}} {
+		tr, tr32 := NewTransform(s, w, 4), NewTransform32(s32, w, 4, WithWorkers(2))
+		d, d32 := tr.GetDecomposition(), tr32.GetDecomposition()
+		for i := range d {
+			if math.Abs(float64(d32[i])-d[i]) > 1e-4 {
+				t.Fatalf("%T: d32[%d] = %f, want %f", w, i, d32[i], d[i])
+			}
+		}
+		if len(tr32.GetCoefficients()[3]) != len(tr.GetCoefficients()[3]) ||
+			len(tr32.GetApproximation()) != len(tr.GetApproximation()) {
+			t.Errorf("%T: coefficient lengths differ", w)
+		}
+		for i, f := range tr32.Inverse() {
+			if math.Abs(float64(f)-s[i]) > 1e-4 {
+				t.Fatalf("%T: x[%d] = %f, want %f", w, i, f, s[i])
+			}
+		}
+	}
+}
+
+func TestTransformSections(t *testing.T) {
+	N := 3*1024 + 100
+	s := make([]float64, N)
+	for i := range s {
+		s[i] = math.Sin(float64(i) / 10)
+	}
+	starts := []int{}
+	err := TransformSections(godsp.NewSliceReader(s), D4, 3, 1024, func(start int, tr *Transform) error {
+		starts = append(starts, start)
+		for i, f := range tr.Inverse() {
+			if math.Abs(f-s[start+i]) > 1e-9 {
+				return fmt.Errorf("x[%d] = %f, want %f", start+i, f, s[start+i])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(starts) != "[0 1024 2048]" {
+		t.Errorf("starts = %v", starts)
+	}
+}
+
+func BenchmarkTransform(b *testing.B) {
+	for _, n := range benchdata.Sizes {
+		s := benchdata.Signal(n)
+		for _, tc := range []struct {
+			name    string
+			wavelet Wavelet
+		}{
+			{"D4", D4},
+			{"Haar", HaarWavelet},
+			{"db8", Daubechies(8)},
+		} {
+			for _, workers := range []int{1, 4} {
+				b.Run(fmt.Sprintf("%s/N=%d/workers=%d", tc.name, n, workers), func(b *testing.B) {
+					b.ReportAllocs()
+					b.SetBytes(int64(8 * n))
+					for i := 0; i < b.N; i++ {
+						NewTransform(s, tc.wavelet, 8, WithWorkers(workers))
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkInverse(b *testing.B) {
+	for _, n := range benchdata.Sizes {
+		tr := Daubechies4(benchdata.Signal(n), 8)
+		b.Run(fmt.Sprintf("D4/N=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(8 * n))
+			for i := 0; i < b.N; i++ {
+				tr.Inverse()
+			}
+		})
+	}
+}
+
+func TestGolden(t *testing.T) {
+	filters := map[string]*Filter{
+		"db1":   Daubechies(1),
+		"db2":   Daubechies(2),
+		"db4":   Daubechies(4),
+		"db6":   Daubechies(6),
+		"sym4":  Symlet(4),
+		"sym5":  Symlet(5),
+		"coif1": Coiflet(1),
+		"coif2": Coiflet(2),
+		"coif3": Coiflet(3),
+	}
+	for _, c := range golden.Load(t, "testdata/wavelets.json").Cases {
+		name := strings.Fields(c.Name)[0]
+		f, level := filters[name], int(c.Param(t, "level"))
+		if f == nil {
+			t.Fatalf("%s: unknown wavelet", c.Name)
+		}
+		c.Check(t, pywtDecomposition(c.Input, f, len(f.h)/2-1, 1, 0, level))
+		/*
+			The detail coefficients of the lifting wavelets are the negated
+			detail coefficients of db1 and db2, and those of D4 are delayed by
+			one coefficient.
+		*/
+		switch name {
+		case "db1":
+			c.Check(t, pywtDecomposition(c.Input, HaarWavelet, 0, -1, 0, level))
+		case "db2":
+			c.Check(t, pywtDecomposition(c.Input, D4, 1, -1, 1, level))
+		}
+	}
+}
+
+/*
+pywtDecomposition returns the DWT of x with w to level in the order of
+pywt.wavedec: [cA_level | cD_level | ... | cD_1]. PyWavelets centres a filter
+of length L on sample L/2 of each pair of samples, so the input of each level
+is delayed by delay samples, L/2-1 for a Filter. The detail coefficients are
+multiplied by sign and advanced by shift coefficients.
+*/
+func pywtDecomposition(x []float64, w Wavelet, delay int, sign float64, shift, level int) []float64 {
+	app, details := x, []float64{}
+	for l := 0; l < level; l++ {
+		N, half := len(app), len(app)/2
+		s := make([]float64, N)
+		for i := range s {
+			s[i] = app[((i-delay)%N+N)%N]
+		}
+		w.Forward(s)
+		d := make([]float64, half)
+		for i := range d {
+			d[i] = sign * s[half+(i+shift)%half]
+		}
+		app, details = s[:half], append(d, details...)
+	}
+	return append(append([]float64{}, app...), details...)
+}
@@ -0,0 +1,120 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"github.com/goccmack/godsp"
+)
+
+/*
+Transform32 is the DWT of a float32 signal. The coefficients are computed and
+stored as float32, which halves the memory of the transform of a long signal
+at the cost of precision.
+*/
+type Transform32 struct {
+	st       []float32
+	n        int
+	level    int
+	sections []*transformSection
+	wavelet  Wavelet
+	workers  int
+}
+
+/*
+NewTransform32 returns the DWT of s with wavelet w to level, as NewTransform,
+with float32 coefficients. The wavelets of this package transform the float32
+coefficients directly. Other wavelets transform a float64 copy of each level.
+*/
+func NewTransform32(s []float32, w Wavelet, level int, opts ...Option) *Transform32 {
+	o := getOptions(opts)
+	t := &Transform32{
+		n:       len(s),
+		level:   level,
+		wavelet: w,
+		workers: o.workers,
+	}
+	if o.padding == NoPadding {
+		t.st = make([]float32, len(s))
+		copy(t.st, s)
+		t.sections = getTransformSections(len(s), level)
+	} else {
+		blk := godsp.Pow2(level)
+		t.st = pad(s, (len(s)+blk-1)/blk*blk, o.padding)
+		t.sections = []*transformSection{{start: 0, size: len(t.st)}}
+	}
+	report := newProgress(o.progress, len(t.sections)*level)
+	forEachSection(t.sections, t.workers, func(section *transformSection) {
+		scaleSize := section.size
+		for l := level; l > 0; l-- {
+			forward32(w, t.st[section.start:section.start+scaleSize])
+			scaleSize /= 2
+			report()
+		}
+	})
+	return t
+}
+
+/*
+Inverse returns the time domain signal reconstructed from the current
+coefficients of t. See Transform.Inverse.
+*/
+func (t *Transform32) Inverse() []float32 {
+	s := make([]float32, len(t.st))
+	copy(s, t.st)
+	forEachSection(t.sections, t.workers, func(section *transformSection) {
+		scaleSize := section.size / godsp.Pow2(t.level-1)
+		for l := 1; l <= t.level; l++ {
+			inverse32(t.wavelet, s[section.start:section.start+scaleSize])
+			scaleSize *= 2
+		}
+	})
+	return s[:t.n]
+}
+
+/*
+GetCoefficients returns copies of the detail coefficients of all transform
+levels. Element 0 of the result is the first (finest) level.
+*/
+func (t *Transform32) GetCoefficients() [][]float32 {
+	cfs := make([][]float32, t.level)
+	for _, s := range t.sections {
+		half := s.size / 2
+		for l := range cfs {
+			cfs[l] = append(cfs[l], t.st[s.start+half:s.start+2*half]...)
+			half /= 2
+		}
+	}
+	return cfs
+}
+
+/*
+GetApproximation returns the scaling coefficients of the deepest level of the
+transform.
+*/
+func (t *Transform32) GetApproximation() []float32 {
+	var app []float32
+	for _, s := range t.sections {
+		app = append(app, t.st[s.start:s.start+s.size/godsp.Pow2(t.level)]...)
+	}
+	return app
+}
+
+/*
+GetDecomposition returns the vector containing the DWT decomposion. It aliases
+the transform.
+*/
+func (t *Transform32) GetDecomposition() []float32 {
+	return t.st
+}
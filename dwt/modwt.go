@@ -0,0 +1,120 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"math"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+MODWT is the maximal overlap (undecimated) discrete wavelet transform. Its
+coefficients are translation invariant and every level has the same length as
+the input signal. The signal is extended periodically at its boundaries.
+
+After: Wavelet Methods for Time Series Analysis.
+D. B. Percival and A. T. Walden. Cambridge University Press 2000. Section 5.4
+*/
+type MODWT struct {
+	w     [][]float64
+	v     []float64
+	h, g  []float64
+	level int
+}
+
+/*
+NewMODWT returns the MODWT of s with the wavelet filter f to level.
+*/
+func NewMODWT(s []float64, f *Filter, level int) *MODWT {
+	m := &MODWT{
+		w:     make([][]float64, level),
+		h:     godsp.DivS(f.h, math.Sqrt(2)),
+		g:     godsp.DivS(f.g, math.Sqrt(2)),
+		level: level,
+	}
+	v := make([]float64, len(s))
+	copy(v, s)
+	for j := 1; j <= level; j++ {
+		m.w[j-1], v = m.forward(v, godsp.Pow2(j-1))
+	}
+	m.v = v
+	return m
+}
+
+// forward computes one level of the MODWT with the filters dilated by step.
+func (m *MODWT) forward(v []float64, step int) (w, v1 []float64) {
+	N := len(v)
+	w, v1 = make([]float64, N), make([]float64, N)
+	for t := range v {
+		for l := range m.h {
+			vt := v[mod(t-step*l, N)]
+			w[t] += m.g[l] * vt
+			v1[t] += m.h[l] * vt
+		}
+	}
+	return
+}
+
+// inverse undoes forward.
+func (m *MODWT) inverse(w, v []float64, step int) []float64 {
+	N := len(v)
+	v0 := make([]float64, N)
+	for t := range v0 {
+		for l := range m.h {
+			i := mod(t+step*l, N)
+			v0[t] += m.g[l]*w[i] + m.h[l]*v[i]
+		}
+	}
+	return v0
+}
+
+/*
+GetApproximation returns the scaling coefficients of the deepest level of the
+transform. The returned slice aliases the transform.
+*/
+func (m *MODWT) GetApproximation() []float64 {
+	return m.v
+}
+
+/*
+GetCoefficients returns the wavelet coefficients of all transform levels.
+Every level has the same length as the transformed signal. The returned slices
+alias the transform, so they may be modified before calling Inverse.
+*/
+func (m *MODWT) GetCoefficients() [][]float64 {
+	return m.w
+}
+
+/*
+Inverse returns the time domain signal reconstructed from the current
+coefficients of m. m is not modified.
+*/
+func (m *MODWT) Inverse() []float64 {
+	v := m.v
+	for j := m.level; j > 0; j-- {
+		v = m.inverse(m.w[j-1], v, godsp.Pow2(j-1))
+	}
+	return v
+}
+
+// mod returns i mod n in [0,n)
+func mod(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
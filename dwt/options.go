@@ -0,0 +1,77 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/goccmack/godsp"
+)
+
+// Option configures a Transform
+type Option func(*options)
+
+type options struct {
+	padding  Padding
+	workers  int
+	progress godsp.ProgressFunc
+}
+
+func getOptions(opts []Option) *options {
+	o := &options{
+		padding: NoPadding,
+		workers: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+/*
+WithPadding selects the mode used to extend a signal whose length is not an
+integer multiple of 2^level.
+*/
+func WithPadding(p Padding) Option {
+	return func(o *options) {
+		o.padding = p
+	}
+}
+
+/*
+WithWorkers sets the number of goroutines that transform the sections of a
+signal concurrently. The default is runtime.GOMAXPROCS(0).
+The function panics if n < 1.
+*/
+func WithWorkers(n int) Option {
+	if n < 1 {
+		panic(fmt.Sprintf("Invalid number of workers %d", n))
+	}
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+/*
+WithProgress sets the function that is called with the number of levels of the
+sections of the signal transformed out of the total number of levels of all
+sections.
+*/
+func WithProgress(f godsp.ProgressFunc) Option {
+	return func(o *options) {
+		o.progress = f
+	}
+}
@@ -0,0 +1,155 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+WaveletPacket is the wavelet packet transform, which decomposes both the
+approximation and the detail coefficients of every level. Level l has 2^l
+nodes in natural order: the children of node i are the approximation 2i and
+the detail 2i+1 of node i. Level 0 has the single node containing the signal.
+*/
+type WaveletPacket struct {
+	nodes   [][][]float64
+	wavelet Wavelet
+	level   int
+}
+
+// PacketNode identifies a node of a WaveletPacket
+type PacketNode struct {
+	Level, Index int
+}
+
+/*
+CostFunction returns the cost of representing a signal by coefficients c.
+Cost functions used for best basis selection must be additive.
+*/
+type CostFunction func(c []float64) float64
+
+/*
+NewWaveletPacket returns the wavelet packet decomposition of s with wavelet w
+to level. The function panics if len(s) is not an integer multiple of 2^level.
+*/
+func NewWaveletPacket(s []float64, w Wavelet, level int) *WaveletPacket {
+	if len(s)%godsp.Pow2(level) != 0 {
+		panic(fmt.Sprintf("len(s) (%d) is not an integer multiple of 2^%d", len(s), level))
+	}
+	wp := &WaveletPacket{
+		nodes:   make([][][]float64, level+1),
+		wavelet: w,
+		level:   level,
+	}
+	wp.nodes[0] = [][]float64{make([]float64, len(s))}
+	copy(wp.nodes[0][0], s)
+	for l := 1; l <= level; l++ {
+		wp.nodes[l] = make([][]float64, godsp.Pow2(l))
+		for i, parent := range wp.nodes[l-1] {
+			x := make([]float64, len(parent))
+			copy(x, parent)
+			w.Forward(x)
+			half := len(x) / 2
+			wp.nodes[l][2*i], wp.nodes[l][2*i+1] = x[:half], x[half:]
+		}
+	}
+	return wp
+}
+
+/*
+Node returns the coefficients of node index at level. The returned slice aliases
+the transform. The function panics if the node does not exist.
+*/
+func (wp *WaveletPacket) Node(level, index int) []float64 {
+	if level < 0 || level > wp.level || index < 0 || index >= len(wp.nodes[level]) {
+		panic(fmt.Sprintf("Invalid node level=%d, index=%d", level, index))
+	}
+	return wp.nodes[level][index]
+}
+
+/*
+BestBasis returns the nodes of the basis with minimum cost, in increasing order
+of frequency band.
+
+After: R. R. Coifman and M. V. Wickerhauser. Entropy-based algorithms for best
+basis selection. IEEE Transactions on Information Theory, 38(2), 1992.
+*/
+func (wp *WaveletPacket) BestBasis(cost CostFunction) []PacketNode {
+	basis := make([][]PacketNode, len(wp.nodes[wp.level]))
+	costs := make([]float64, len(basis))
+	for i, c := range wp.nodes[wp.level] {
+		basis[i] = []PacketNode{{wp.level, i}}
+		costs[i] = cost(c)
+	}
+	for l := wp.level - 1; l >= 0; l-- {
+		for i, c := range wp.nodes[l] {
+			if nc := cost(c); nc <= costs[2*i]+costs[2*i+1] {
+				basis[i], costs[i] = []PacketNode{{l, i}}, nc
+			} else {
+				basis[i] = append(basis[2*i], basis[2*i+1]...)
+				costs[i] = costs[2*i] + costs[2*i+1]
+			}
+		}
+		basis, costs = basis[:len(wp.nodes[l])], costs[:len(wp.nodes[l])]
+	}
+	return basis[0]
+}
+
+/*
+Inverse returns the signal reconstructed from the nodes of basis, which must
+cover the frequency band of the signal exactly once, such as the basis
+returned by BestBasis. The function panics if basis is not a valid basis.
+*/
+func (wp *WaveletPacket) Inverse(basis []PacketNode) []float64 {
+	in := make(map[PacketNode]bool)
+	for _, n := range basis {
+		in[n] = true
+	}
+	return wp.reconstruct(PacketNode{0, 0}, in)
+}
+
+func (wp *WaveletPacket) reconstruct(n PacketNode, basis map[PacketNode]bool) []float64 {
+	if basis[n] {
+		x := make([]float64, len(wp.nodes[n.Level][n.Index]))
+		copy(x, wp.nodes[n.Level][n.Index])
+		return x
+	}
+	if n.Level == wp.level {
+		panic(fmt.Sprintf("Node %d,%d is not covered by the basis", n.Level, n.Index))
+	}
+	a := wp.reconstruct(PacketNode{n.Level + 1, 2 * n.Index}, basis)
+	d := wp.reconstruct(PacketNode{n.Level + 1, 2*n.Index + 1}, basis)
+	x := append(a, d...)
+	wp.wavelet.Inverse(x)
+	return x
+}
+
+/*
+ShannonEntropy returns the additive Shannon entropy -sum(c^2 log(c^2)) of the
+coefficients c.
+*/
+func ShannonEntropy(c []float64) float64 {
+	e := 0.0
+	for _, f := range c {
+		if f2 := f * f; f2 > 0 {
+			e -= f2 * math.Log(f2)
+		}
+	}
+	return e
+}
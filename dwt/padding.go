@@ -0,0 +1,57 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"fmt"
+
+	"github.com/goccmack/godsp"
+)
+
+// Padding is the mode used to extend a signal at its end
+type Padding int
+
+const (
+	// NoPadding transforms the signal in sections of length 2^k
+	NoPadding Padding = iota
+	// ZeroPadding extends the signal with zeros: 1 2 3 | 0 0 0
+	ZeroPadding
+	// SymmetricPadding mirrors the signal including its last sample: 1 2 3 | 3 2 1
+	SymmetricPadding
+	// PeriodicPadding repeats the signal from its start: 1 2 3 | 1 2 3
+	PeriodicPadding
+	// ReflectPadding mirrors the signal excluding its last sample: 1 2 3 | 2 1 2
+	ReflectPadding
+)
+
+// padModes are the godsp pad modes of the padding modes
+var padModes = map[Padding]godsp.PadMode{
+	ZeroPadding:      godsp.PadZero,
+	SymmetricPadding: godsp.PadSymmetric,
+	PeriodicPadding:  godsp.PadPeriodic,
+	ReflectPadding:   godsp.PadReflect,
+}
+
+/*
+pad returns a copy of s extended to length n according to mode.
+The function panics if s is empty, n > 0 and mode is not ZeroPadding.
+*/
+func pad[T godsp.Float](s []T, n int, mode Padding) []T {
+	m, ok := padModes[mode]
+	if !ok {
+		panic(fmt.Sprintf("Invalid padding mode %d", mode))
+	}
+	return godsp.PadTo(s, n, m)
+}
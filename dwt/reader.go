@@ -0,0 +1,68 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"fmt"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+TransformSections computes the DWT of the samples of r with wavelet w to level
+section by section, so that r may be larger than memory. The sections are
+chosen as by NewTransform, but no section is longer than maxSize. Each section
+is read from r, transformed and passed to f with the index of its first sample
+in r. Only one section is in memory at a time. A tail of r that is shorter than
+64*2^level is not transformed.
+
+TransformSections returns the first error returned by r or f.
+The function panics if maxSize is not a power of 2 or is less than
+64*2^level.
+*/
+func TransformSections(r godsp.SampleReader, w Wavelet, level, maxSize int,
+	f func(start int, t *Transform) error) error {
+	if !godsp.IsPowerOf2(maxSize) || maxSize < 64*godsp.Pow2(level) {
+		panic(fmt.Sprintf("Invalid section size %d", maxSize))
+	}
+	s := make([]float64, maxSize)
+	for _, section := range getBoundedSections(r.Len(), level, maxSize) {
+		x := s[:section.size]
+		if _, err := r.ReadSamplesAt(x, section.start); err != nil {
+			return err
+		}
+		if err := f(section.start, NewTransform(x, w, level, WithWorkers(1))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+getBoundedSections returns the sections of getTransformSections(N, level) split
+into sections of at most maxSize, which is a power of 2.
+*/
+func getBoundedSections(N, level, maxSize int) (sections []*transformSection) {
+	for _, s := range getTransformSections(N, level) {
+		for start := s.start; start < s.start+s.size; start += maxSize {
+			size := s.size
+			if size > maxSize {
+				size = maxSize
+			}
+			sections = append(sections, &transformSection{start: start, size: size})
+		}
+	}
+	return
+}
@@ -0,0 +1,228 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+/*
+The binary file format of a saved Transform. All values are little endian:
+
+	magic       [8]byte "GODSPDWT"
+	version     uint32
+	n           uint64  length of the transformed signal
+	level       uint32
+	wavelet     uint8   waveletD4, waveletHaar or waveletFilter
+	[len(h)     uint32  only for waveletFilter
+	 h          [len(h)]float64]
+	numSections uint32
+	sections    [numSections]{start, size uint64}
+	len(st)     uint64
+	st          [len(st)]float64
+*/
+const (
+	fileMagic   = "GODSPDWT"
+	fileVersion = 1
+
+	waveletD4     = 0
+	waveletHaar   = 1
+	waveletFilter = 2
+)
+
+/*
+Save writes t to the file at path in a compact binary format that can be read
+by LoadTransform. The function panics if the wavelet of t is not D4,
+HaarWavelet or a *Filter, or if the file cannot be written.
+*/
+func (t *Transform) Save(path string) {
+	buf := new(bytes.Buffer)
+	if _, err := t.WriteTo(buf); err != nil {
+		panic(err)
+	}
+	if err := myioutil.WriteFile(path, buf.Bytes()); err != nil {
+		panic(err)
+	}
+}
+
+/*
+WriteTo writes t to w in the format of Save. It returns the number of bytes
+written and the write error or an error if the wavelet of t is not D4,
+HaarWavelet or a *Filter.
+*/
+func (t *Transform) WriteTo(w io.Writer) (int64, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(fileMagic)
+	write(buf, uint32(fileVersion))
+	write(buf, uint64(t.n))
+	write(buf, uint32(t.level))
+	switch w := t.wavelet.(type) {
+	case *Filter:
+		write(buf, uint8(waveletFilter))
+		write(buf, uint32(len(w.h)))
+		write(buf, w.h)
+	default:
+		switch t.wavelet {
+		case D4:
+			write(buf, uint8(waveletD4))
+		case HaarWavelet:
+			write(buf, uint8(waveletHaar))
+		default:
+			return 0, fmt.Errorf("Cannot save transform with wavelet %T", t.wavelet)
+		}
+	}
+	write(buf, uint32(len(t.sections)))
+	for _, s := range t.sections {
+		write(buf, uint64(s.start))
+		write(buf, uint64(s.size))
+	}
+	write(buf, uint64(len(t.st)))
+	write(buf, t.st)
+	return buf.WriteTo(w)
+}
+
+/*
+LoadTransform returns the Transform saved in the file at path by
+Transform.Save. The function panics if the file cannot be read or is not a
+valid transform file.
+*/
+func LoadTransform(path string) *Transform {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	t, err := decodeTransform(data)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s", path, err))
+	}
+	return t
+}
+
+/*
+LoadTransformFrom returns the Transform written to r by Transform.WriteTo or
+Transform.Save, or the error reading r or decoding the transform.
+*/
+func LoadTransformFrom(r io.Reader) (*Transform, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTransform(data)
+}
+
+func decodeTransform(data []byte) (*Transform, error) {
+	rdr := &reader{r: bytes.NewReader(data)}
+	magic := make([]byte, len(fileMagic))
+	rdr.read(magic)
+	if rdr.err != nil || string(magic) != fileMagic {
+		return nil, fmt.Errorf("not a transform file")
+	}
+	var version uint32
+	if rdr.read(&version); version != fileVersion {
+		return nil, fmt.Errorf("Unsupported transform file version %d", version)
+	}
+	var n uint64
+	var level uint32
+	var wavelet uint8
+	rdr.read(&n)
+	rdr.read(&level)
+	rdr.read(&wavelet)
+	if rdr.err != nil {
+		return nil, rdr.err
+	}
+	if level < 1 || level > 62 {
+		return nil, fmt.Errorf("Invalid level %d", level)
+	}
+	t := &Transform{
+		level:   int(level),
+		workers: getOptions(nil).workers,
+	}
+	switch wavelet {
+	case waveletD4:
+		t.wavelet = D4
+	case waveletHaar:
+		t.wavelet = HaarWavelet
+	case waveletFilter:
+		var lenH uint32
+		rdr.read(&lenH)
+		// Lengths are compared unsigned because they may not fit an int
+		if rdr.err != nil || lenH == 0 || lenH%2 != 0 || uint64(lenH) > uint64(rdr.r.Len()/8) {
+			return nil, fmt.Errorf("Invalid filter length %d", lenH)
+		}
+		h := make([]float64, lenH)
+		rdr.read(h)
+		t.wavelet = NewFilter(h)
+	default:
+		return nil, fmt.Errorf("Invalid wavelet %d", wavelet)
+	}
+	var numSections uint32
+	rdr.read(&numSections)
+	if rdr.err != nil || uint64(numSections) > uint64(rdr.r.Len()/16) {
+		return nil, fmt.Errorf("Invalid number of sections %d", numSections)
+	}
+	sections := make([][2]uint64, numSections)
+	for i := range sections {
+		rdr.read(&sections[i])
+	}
+	var lenSt uint64
+	rdr.read(&lenSt)
+	if rdr.err != nil || lenSt > uint64(rdr.r.Len()/8) {
+		return nil, fmt.Errorf("Invalid length %d", lenSt)
+	}
+	if n > lenSt {
+		return nil, fmt.Errorf("Signal length %d > transform length %d", n, lenSt)
+	}
+	// The sections are disjoint, in order and transformed to level
+	end := uint64(0)
+	for _, sec := range sections {
+		start, size := sec[0], sec[1]
+		if start < end || start > lenSt || size > lenSt-start || size == 0 || size%(1<<level) != 0 {
+			return nil, fmt.Errorf("Invalid section start %d, size %d", start, size)
+		}
+		end = start + size
+		t.sections = append(t.sections, &transformSection{start: int(start), size: int(size)})
+	}
+	t.n = int(n)
+	t.st = make([]float64, lenSt)
+	rdr.read(t.st)
+	if rdr.err != nil {
+		return nil, rdr.err
+	}
+	return t, nil
+}
+
+func write(buf *bytes.Buffer, data interface{}) {
+	if err := binary.Write(buf, binary.LittleEndian, data); err != nil {
+		panic(err)
+	}
+}
+
+// reader reads little endian values until the first error, which it records in err
+type reader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (rdr *reader) read(data interface{}) {
+	if rdr.err == nil {
+		rdr.err = binary.Read(rdr.r, binary.LittleEndian, data)
+	}
+}
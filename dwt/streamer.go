@@ -0,0 +1,89 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+/*
+Streamer computes the DWT of a signal that arrives in blocks of arbitrary
+size, such as a live audio stream. Each level keeps the last len(h)-2 samples
+of its input, so coefficients that overlap two blocks are computed exactly as
+if the whole signal was available. The coefficients equal those of
+Filter.Forward applied to the signal prefixed by len(h)-2 zeros, without the
+periodic extension at the end.
+*/
+type Streamer struct {
+	f      *Filter
+	hist   [][]float64
+	app    []float64
+	detail [][]float64
+}
+
+// NewStreamer returns a Streamer computing the DWT with filter f to level.
+func NewStreamer(f *Filter, level int) *Streamer {
+	s := &Streamer{
+		f:      f,
+		hist:   make([][]float64, level),
+		detail: make([][]float64, level),
+	}
+	for l := range s.hist {
+		s.hist[l] = make([]float64, len(f.h)-2, len(f.h))
+	}
+	return s
+}
+
+/*
+Write adds the block x to the stream. The coefficients that can be computed
+from the samples received so far are available from Read.
+*/
+func (s *Streamer) Write(x []float64) {
+	for _, f := range x {
+		s.push(0, f)
+	}
+}
+
+/*
+Read returns the approximation coefficients of the deepest level and the
+detail coefficients of every level that were computed since the previous call
+to Read. detail[0] is the first (finest) level.
+*/
+func (s *Streamer) Read() (app []float64, detail [][]float64) {
+	app, s.app = s.app, nil
+	detail = make([][]float64, len(s.detail))
+	for l := range s.detail {
+		detail[l], s.detail[l] = s.detail[l], nil
+	}
+	return
+}
+
+// push adds the sample f to the input of level l.
+func (s *Streamer) push(l int, f float64) {
+	s.hist[l] = append(s.hist[l], f)
+	h := s.hist[l]
+	if len(h) < len(s.f.h) {
+		return
+	}
+	a, d := 0.0, 0.0
+	for k, x := range h {
+		a += s.f.h[k] * x
+		d += s.f.g[k] * x
+	}
+	copy(h, h[2:])
+	s.hist[l] = h[:len(h)-2]
+	s.detail[l] = append(s.detail[l], d)
+	if l+1 < len(s.hist) {
+		s.push(l+1, a)
+	} else {
+		s.app = append(s.app, a)
+	}
+}
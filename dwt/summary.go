@@ -0,0 +1,115 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/goccmack/godsp"
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+/*
+Summary describes a Transform without its coefficients, e.g. for reporting by
+web services and dashboards. It is encoded to JSON by encoding/json.
+*/
+type Summary struct {
+	// N is the length of the transformed signal
+	N int `json:"n"`
+	// Wavelet is "D4", "Haar", "filter" or "other"
+	Wavelet string `json:"wavelet"`
+	// FilterLength is the number of scaling filter coefficients of a "filter" wavelet
+	FilterLength int            `json:"filterLength,omitempty"`
+	Levels       []LevelSummary `json:"levels"`
+}
+
+// LevelSummary describes the detail coefficients of a level of a Transform
+type LevelSummary struct {
+	// Level is 1 for the first (finest) level
+	Level           int     `json:"level"`
+	NumCoefficients int     `json:"numCoefficients"`
+	Energy          float64 `json:"energy"`
+	Min             float64 `json:"min"`
+	Max             float64 `json:"max"`
+	Mean            float64 `json:"mean"`
+	Std             float64 `json:"std"`
+}
+
+// Summary returns the summary of t
+func (t *Transform) Summary() *Summary {
+	s := &Summary{N: t.n, Levels: make([]LevelSummary, t.level)}
+	switch w := t.wavelet.(type) {
+	case *Filter:
+		s.Wavelet, s.FilterLength = "filter", len(w.h)
+	default:
+		switch t.wavelet {
+		case D4:
+			s.Wavelet = "D4"
+		case HaarWavelet:
+			s.Wavelet = "Haar"
+		default:
+			s.Wavelet = "other"
+		}
+	}
+	for i, cfs := range t.GetCoefficients() {
+		ls := LevelSummary{Level: i + 1, NumCoefficients: len(cfs)}
+		if len(cfs) > 0 {
+			ls.Energy = godsp.Dot(cfs, cfs)
+			ls.Min, ls.Max = cfs[0], cfs[0]
+			for _, c := range cfs {
+				if c < ls.Min {
+					ls.Min = c
+				}
+				if c > ls.Max {
+					ls.Max = c
+				}
+			}
+			ls.Mean, ls.Std = godsp.Average(cfs), godsp.Std(cfs)
+		}
+		s.Levels[i] = ls
+	}
+	return s
+}
+
+/*
+WriteSummaryJSON writes the summary of t to the file fname as JSON.
+The function panics if the file cannot be written.
+*/
+func WriteSummaryJSON(t *Transform, fname string) {
+	b, err := json.MarshalIndent(t.Summary(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	if err := myioutil.WriteFile(fname, b); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ReadSummaryJSON returns the summary written to the file fname by
+WriteSummaryJSON. The function panics if the file cannot be read or decoded.
+*/
+func ReadSummaryJSON(fname string) *Summary {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		panic(err)
+	}
+	s := new(Summary)
+	if err := json.Unmarshal(b, s); err != nil {
+		panic(err)
+	}
+	return s
+}
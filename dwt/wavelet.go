@@ -0,0 +1,256 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package dwt
+
+import (
+	"fmt"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+Wavelet computes one level of the DWT.
+*/
+type Wavelet interface {
+	/*
+		Forward replaces s, which has even length, by its approximation
+		coefficients in the first half and its detail coefficients in the
+		second half.
+	*/
+	Forward(s []float64)
+
+	// Inverse undoes Forward.
+	Inverse(s []float64)
+}
+
+var (
+	// D4 is the lifting implementation of the Daubechies 4 wavelet.
+	D4 Wavelet = &lifting{daubechies4[float64], inverseDaubechies4[float64],
+		daubechies4[float32], inverseDaubechies4[float32]}
+
+	// HaarWavelet is the lifting implementation of the Haar wavelet.
+	HaarWavelet Wavelet = &lifting{haar[float64], inverseHaar[float64],
+		haar[float32], inverseHaar[float32]}
+)
+
+/*
+wavelet32 is implemented by the wavelets of this package, which transform
+float32 vectors without conversion to float64.
+*/
+type wavelet32 interface {
+	forward32(s []float32)
+	inverse32(s []float32)
+}
+
+/*
+forward32 computes w.Forward of the float32 vector s. A wavelet that does not
+implement wavelet32 transforms a float64 copy of s.
+*/
+func forward32(w Wavelet, s []float32) {
+	if w32, ok := w.(wavelet32); ok {
+		w32.forward32(s)
+		return
+	}
+	x := godsp.Convert[float64](s)
+	w.Forward(x)
+	for i, f := range x {
+		s[i] = float32(f)
+	}
+}
+
+// inverse32 computes w.Inverse of the float32 vector s. See forward32.
+func inverse32(w Wavelet, s []float32) {
+	if w32, ok := w.(wavelet32); ok {
+		w32.inverse32(s)
+		return
+	}
+	x := godsp.Convert[float64](s)
+	w.Inverse(x)
+	for i, f := range x {
+		s[i] = float32(f)
+	}
+}
+
+/*
+lifting is a wavelet implemented by lifting steps on a vector that has been
+split into its even and odd elements.
+*/
+type lifting struct {
+	forward, inverse       func([]float64)
+	forward32f, inverse32f func([]float32)
+}
+
+func (l *lifting) Forward(s []float64) {
+	split(s)
+	l.forward(s)
+}
+
+func (l *lifting) Inverse(s []float64) {
+	l.inverse(s)
+	merge(s)
+}
+
+func (l *lifting) forward32(s []float32) {
+	split(s)
+	l.forward32f(s)
+}
+
+func (l *lifting) inverse32(s []float32) {
+	l.inverse32f(s)
+	merge(s)
+}
+
+/*
+Filter is an orthogonal wavelet defined by its scaling filter coefficients.
+The signal is extended periodically at its boundaries.
+*/
+type Filter struct {
+	h, g []float64
+}
+
+/*
+NewFilter returns the orthogonal wavelet with scaling filter coefficients h.
+The wavelet filter is the quadrature mirror of h. The function panics if len(h)
+is not even.
+*/
+func NewFilter(h []float64) *Filter {
+	if len(h)%2 != 0 {
+		panic(fmt.Sprintf("len(h) (%d) is not even", len(h)))
+	}
+	f := &Filter{
+		h: make([]float64, len(h)),
+		g: make([]float64, len(h)),
+	}
+	copy(f.h, h)
+	for k := range h {
+		f.g[k] = h[len(h)-1-k]
+		if k%2 != 0 {
+			f.g[k] = -f.g[k]
+		}
+	}
+	return f
+}
+
+/*
+Daubechies returns the extremal phase Daubechies wavelet dbN with N vanishing
+moments. The function panics if N is not in [1,20].
+*/
+func Daubechies(N int) *Filter {
+	if N < 1 || N >= len(daubechiesCoefficients) {
+		panic(fmt.Sprintf("Invalid Daubechies order %d", N))
+	}
+	return NewFilter(daubechiesCoefficients[N])
+}
+
+/*
+Symlet returns the least asymmetric Daubechies wavelet symN with N vanishing
+moments. The function panics if N is not in [2,20].
+*/
+func Symlet(N int) *Filter {
+	if N < 2 || N >= len(symletCoefficients) {
+		panic(fmt.Sprintf("Invalid Symlet order %d", N))
+	}
+	return NewFilter(symletCoefficients[N])
+}
+
+/*
+Coiflet returns the Coiflet coifN with 2N vanishing moments and a filter of
+length 6N. The function panics if N is not in [1,3].
+*/
+func Coiflet(N int) *Filter {
+	if N < 1 || N >= len(coifletCoefficients) {
+		panic(fmt.Sprintf("Invalid Coiflet order %d", N))
+	}
+	return NewFilter(coifletCoefficients[N])
+}
+
+// Forward implements Wavelet
+func (f *Filter) Forward(s []float64) {
+	filterForward(f, s)
+}
+
+// Inverse implements Wavelet
+func (f *Filter) Inverse(s []float64) {
+	filterInverse(f, s)
+}
+
+func (f *Filter) forward32(s []float32) {
+	filterForward(f, s)
+}
+
+func (f *Filter) inverse32(s []float32) {
+	filterInverse(f, s)
+}
+
+// filterForward computes f.Forward(s). The sums are accumulated in float64.
+func filterForward[T godsp.Float](f *Filter, s []T) {
+	N, half := len(s), len(s)/2
+	x := make([]T, N)
+	copy(x, s)
+	for n := 0; n < half; n++ {
+		a, d := 0.0, 0.0
+		for k := range f.h {
+			xk := float64(x[(2*n+k)%N])
+			a += f.h[k] * xk
+			d += f.g[k] * xk
+		}
+		s[n], s[half+n] = T(a), T(d)
+	}
+}
+
+// filterInverse computes f.Inverse(s). The sums are accumulated in float64.
+func filterInverse[T godsp.Float](f *Filter, s []T) {
+	N, half := len(s), len(s)/2
+	x := make([]float64, N)
+	for n := 0; n < half; n++ {
+		a, d := float64(s[n]), float64(s[half+n])
+		for k := range f.h {
+			x[(2*n+k)%N] += f.h[k]*a + f.g[k]*d
+		}
+	}
+	for i, v := range x {
+		s[i] = T(v)
+	}
+}
+
+/*
+SingleLevel returns the approximation and detail coefficients of one level of
+the DWT of s with wavelet w. s is not modified.
+The function panics if len(s) is not even.
+*/
+func SingleLevel(s []float64, w Wavelet) (app, detail []float64) {
+	if len(s)%2 != 0 {
+		panic(fmt.Sprintf("len(s) (%d) is not even", len(s)))
+	}
+	x := make([]float64, len(s))
+	copy(x, s)
+	w.Forward(x)
+	return x[:len(x)/2], x[len(x)/2:]
+}
+
+/*
+InverseSingleLevel returns the signal reconstructed from the approximation and
+detail coefficients of one level of the DWT with wavelet w.
+The function panics if len(app) != len(detail).
+*/
+func InverseSingleLevel(app, detail []float64, w Wavelet) []float64 {
+	if len(app) != len(detail) {
+		panic(fmt.Sprintf("len(app) (%d) != len(detail) (%d)", len(app), len(detail)))
+	}
+	x := make([]float64, 0, 2*len(app))
+	x = append(append(x, app...), detail...)
+	w.Inverse(x)
+	return x
+}
@@ -0,0 +1,184 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package dwt2 has functions supporting the separable 2D Discrete Wavelet
+Transform of matrices such as images.
+
+At each level the rows of the current approximation are transformed, followed
+by its columns. The sub-bands of a level are stored in the quadrants of the
+approximation of the previous level:
+
+	LL HL
+	LH HH
+
+where the first letter is the filter applied along the rows and the second the
+filter applied along the columns (L: lowpass, H: highpass).
+*/
+package dwt2
+
+import (
+	"fmt"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/dwt"
+)
+
+type Transform struct {
+	st         [][]float64
+	level      int
+	rows, cols int
+	wavelet    dwt.Wavelet
+}
+
+// Daubechies4 returns the 2D DWT of x with Daubechies 4 coeficients to level.
+func Daubechies4(x [][]float64, level int) *Transform {
+	return NewTransform(x, dwt.D4, level)
+}
+
+/*
+NewTransform returns the 2D DWT of the matrix x with wavelet w to level.
+The function panics if the rows of x have different lengths or if the number
+of rows or columns of x is not an integer multiple of 2^level.
+*/
+func NewTransform(x [][]float64, w dwt.Wavelet, level int) *Transform {
+	rows := len(x)
+	if rows == 0 {
+		panic("x is empty")
+	}
+	cols := len(x[0])
+	for i, row := range x {
+		if len(row) != cols {
+			panic(fmt.Sprintf("cols=%d but len(x[%d])=%d", cols, i, len(row)))
+		}
+	}
+	if rows%godsp.Pow2(level) != 0 || cols%godsp.Pow2(level) != 0 {
+		panic(fmt.Sprintf("%d x %d matrix cannot be transformed to level %d",
+			rows, cols, level))
+	}
+	t := &Transform{
+		st:      make([][]float64, rows),
+		level:   level,
+		rows:    rows,
+		cols:    cols,
+		wavelet: w,
+	}
+	for i, row := range x {
+		t.st[i] = make([]float64, cols)
+		copy(t.st[i], row)
+	}
+	r, c := rows, cols
+	for l := 1; l <= level; l++ {
+		t.forward(r, c)
+		r, c = r/2, c/2
+	}
+	return t
+}
+
+/*
+forward computes one level of the transform on the r x c approximation in the
+top left corner of t.st.
+*/
+func (t *Transform) forward(r, c int) {
+	for i := 0; i < r; i++ {
+		t.wavelet.Forward(t.st[i][:c])
+	}
+	col := make([]float64, r)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			col[i] = t.st[i][j]
+		}
+		t.wavelet.Forward(col)
+		for i := 0; i < r; i++ {
+			t.st[i][j] = col[i]
+		}
+	}
+}
+
+// inverse undoes forward.
+func inverse(st [][]float64, r, c int, w dwt.Wavelet) {
+	col := make([]float64, r)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			col[i] = st[i][j]
+		}
+		w.Inverse(col)
+		for i := 0; i < r; i++ {
+			st[i][j] = col[i]
+		}
+	}
+	for i := 0; i < r; i++ {
+		w.Inverse(st[i][:c])
+	}
+}
+
+/*
+GetApproximation returns the LL sub-band of the deepest level of the transform.
+The returned matrix aliases the transform.
+*/
+func (t *Transform) GetApproximation() [][]float64 {
+	r, c := t.rows/godsp.Pow2(t.level), t.cols/godsp.Pow2(t.level)
+	return t.subMatrix(0, 0, r, c)
+}
+
+/*
+GetDecomposition returns the matrix containing the 2D DWT decomposition.
+*/
+func (t *Transform) GetDecomposition() [][]float64 {
+	return t.st
+}
+
+/*
+GetSubBands returns the sub-bands of level, where 1 is the first (finest)
+level of the transform. LL is the approximation at level, which is further
+decomposed by the deeper levels of the transform. The returned matrices alias
+the transform, so they may be modified before calling Inverse.
+The function panics if level is not in [1,t.level].
+*/
+func (t *Transform) GetSubBands(level int) (LL, LH, HL, HH [][]float64) {
+	if level < 1 || level > t.level {
+		panic(fmt.Sprintf("Invalid level %d", level))
+	}
+	r, c := t.rows/godsp.Pow2(level), t.cols/godsp.Pow2(level)
+	LL = t.subMatrix(0, 0, r, c)
+	LH = t.subMatrix(r, 0, r, c)
+	HL = t.subMatrix(0, c, r, c)
+	HH = t.subMatrix(r, c, r, c)
+	return
+}
+
+/*
+Inverse returns the matrix reconstructed from the current coefficients of t.
+t is not modified.
+*/
+func (t *Transform) Inverse() [][]float64 {
+	x := make([][]float64, t.rows)
+	for i, row := range t.st {
+		x[i] = make([]float64, t.cols)
+		copy(x[i], row)
+	}
+	for l := t.level; l > 0; l-- {
+		r, c := t.rows/godsp.Pow2(l-1), t.cols/godsp.Pow2(l-1)
+		inverse(x, r, c, t.wavelet)
+	}
+	return x
+}
+
+func (t *Transform) subMatrix(row, col, r, c int) [][]float64 {
+	m := make([][]float64, r)
+	for i := range m {
+		m[i] = t.st[row+i][col : col+c]
+	}
+	return m
+}
@@ -0,0 +1,25 @@
+package dwt2
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInverse(t *testing.T) {
+	rows, cols := 64, 128
+	x := make([][]float64, rows)
+	for i := range x {
+		x[i] = make([]float64, cols)
+		for j := range x[i] {
+			x[i][j] = math.Sin(float64(i)/5) * math.Cos(float64(j)/7)
+		}
+	}
+	y := Daubechies4(x, 3).Inverse()
+	for i := range x {
+		for j := range x[i] {
+			if math.Abs(x[i][j]-y[i][j]) > 1e-9 {
+				t.Fatalf("y[%d][%d] = %f, want %f", i, j, y[i][j], x[i][j])
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"errors"
+)
+
+/*
+The functions of this package panic on invalid input or I/O errors. The
+functions that validate their input or do I/O have an error-returning variant
+with the suffix Err, e.g. SubErr and ReadWavFileErr. Invalid input is reported
+by an error wrapping one of the following errors.
+*/
+var (
+	// ErrLength is returned when the lengths of vectors are incompatible
+	ErrLength = errors.New("godsp: invalid length")
+	// ErrArgument is returned when an argument is out of range
+	ErrArgument = errors.New("godsp: invalid argument")
+)
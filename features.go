@@ -0,0 +1,249 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ZCR returns the zero-crossing rate of x: the fraction of consecutive pairs of
+samples of x that have different signs. Zero is counted as positive.
+*/
+func ZCR(x []float64) float64 {
+	if len(x) < 2 {
+		return 0
+	}
+	n := 0
+	for i := 1; i < len(x); i++ {
+		if (x[i] >= 0) != (x[i-1] >= 0) {
+			n++
+		}
+	}
+	return float64(n) / float64(len(x)-1)
+}
+
+// RMS returns the root mean square of x
+func RMS(x []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	return math.Sqrt(Dot(x, x) / float64(len(x)))
+}
+
+// FrameStat contains the features of a frame returned by FrameStats
+type FrameStat struct {
+	// Energy is the sum of the squares of the samples
+	Energy float64
+	// RMS is the root mean square of the samples
+	RMS float64
+	// ZCR is the zero-crossing rate of the frame. See ZCR.
+	ZCR float64
+	// Peak is the maximum absolute sample value
+	Peak float64
+}
+
+/*
+FrameStats returns the features of each frame of x. Frame i starts at sample
+i*hop and has length frameLen. A tail of x that does not fill a frame is
+ignored.
+The function panics if frameLen < 1 or hop < 1.
+*/
+func FrameStats(x []float64, frameLen, hop int) []FrameStat {
+	stats, err := FrameStatsErr(x, frameLen, hop)
+	if err != nil {
+		panic(err)
+	}
+	return stats
+}
+
+/*
+FrameStatsErr returns FrameStats(x, frameLen, hop) or an error wrapping
+ErrArgument if frameLen < 1 or hop < 1.
+*/
+func FrameStatsErr(x []float64, frameLen, hop int) ([]FrameStat, error) {
+	if err := checkFrames(frameLen, hop, 1); err != nil {
+		return nil, err
+	}
+	it := Frames(x, frameLen, hop, WithZeroCopy())
+	stats := make([]FrameStat, it.Len())
+	for it.Next() {
+		frame := it.Frame()
+		energy := Dot(frame, frame)
+		stats[it.Index()] = FrameStat{
+			Energy: energy,
+			RMS:    math.Sqrt(energy / float64(frameLen)),
+			ZCR:    ZCR(frame),
+			Peak:   Max(Abs(frame)),
+		}
+	}
+	return stats, nil
+}
+
+/*
+SpectralCentroid returns the centroid in Hz of each frame of the magnitude
+spectrogram S: the mean of the frequencies of the bins weighted by their
+magnitudes. The frames of S are the frameLen/2+1 non-negative frequency bins
+of frames of even length frameLen, e.g. of Spectrogram. The centroid of a
+silent frame is 0.
+*/
+func SpectralCentroid(S [][]float64, sampleRate float64) []float64 {
+	c := make([]float64, len(S))
+	for i, frame := range S {
+		c[i] = spectralCentroid(frame, sampleRate)
+	}
+	return c
+}
+
+/*
+SpectralBandwidth returns the bandwidth in Hz of each frame of the magnitude
+spectrogram S: the standard deviation of the frequencies of the bins about the
+centroid weighted by their magnitudes. See SpectralCentroid for the layout of
+the frames.
+*/
+func SpectralBandwidth(S [][]float64, sampleRate float64) []float64 {
+	bw := make([]float64, len(S))
+	for i, frame := range S {
+		c, sum := spectralCentroid(frame, sampleRate), 0.0
+		for k, m := range frame {
+			d := binFrequency(k, len(frame), sampleRate) - c
+			bw[i] += m * d * d
+			sum += m
+		}
+		if sum > 0 {
+			bw[i] = math.Sqrt(bw[i] / sum)
+		}
+	}
+	return bw
+}
+
+/*
+SpectralRolloff returns the rolloff frequency in Hz of each frame of the
+magnitude spectrogram S: the frequency of the lowest bin at which the
+cumulative sum of the magnitudes reaches fraction, typically 0.85, of their
+total. See SpectralCentroid for the layout of the frames. The rolloff of a
+silent frame is 0.
+The function panics if fraction is not in (0,1].
+*/
+func SpectralRolloff(S [][]float64, sampleRate, fraction float64) []float64 {
+	r, err := SpectralRolloffErr(S, sampleRate, fraction)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+/*
+SpectralRolloffErr returns SpectralRolloff(S, sampleRate, fraction) or an
+error wrapping ErrArgument if fraction is not in (0,1].
+*/
+func SpectralRolloffErr(S [][]float64, sampleRate, fraction float64) ([]float64, error) {
+	if !(fraction > 0 && fraction <= 1) {
+		return nil, fmt.Errorf("%w: fraction %f", ErrArgument, fraction)
+	}
+	r := make([]float64, len(S))
+	for i, frame := range S {
+		total := Sum(frame)
+		if total <= 0 {
+			continue
+		}
+		sum := 0.0
+		for k, m := range frame {
+			if sum += m; sum >= fraction*total {
+				r[i] = binFrequency(k, len(frame), sampleRate)
+				break
+			}
+		}
+	}
+	return r, nil
+}
+
+/*
+SpectralFlatness returns the flatness of each frame of the magnitude
+spectrogram S: the ratio of the geometric mean to the arithmetic mean of the
+power of its bins. The flatness is close to 1 for noise and close to 0 for
+tones. Powers below 1e-10 are raised to 1e-10, so the flatness of a silent
+frame is 1.
+*/
+func SpectralFlatness(S [][]float64) []float64 {
+	const minPower = 1e-10
+	fl := make([]float64, len(S))
+	for i, frame := range S {
+		logSum, sum := 0.0, 0.0
+		for _, m := range frame {
+			p := math.Max(m*m, minPower)
+			logSum += math.Log(p)
+			sum += p
+		}
+		if n := float64(len(frame)); n > 0 {
+			fl[i] = math.Exp(logSum/n) / (sum / n)
+		}
+	}
+	return fl
+}
+
+/*
+SpectralFlux returns the flux of each frame of the magnitude spectrogram S:
+the sum of the increases of the magnitudes of its bins from the previous
+frame. The flux of the first frame is 0.
+The function panics if the frames of S do not have equal lengths.
+*/
+func SpectralFlux(S [][]float64) []float64 {
+	flux, err := SpectralFluxErr(S)
+	if err != nil {
+		panic(err)
+	}
+	return flux
+}
+
+/*
+SpectralFluxErr returns SpectralFlux(S) or an error wrapping ErrLength if the
+frames of S do not have equal lengths.
+*/
+func SpectralFluxErr(S [][]float64) ([]float64, error) {
+	flux := make([]float64, len(S))
+	for i := 1; i < len(S); i++ {
+		if len(S[i]) != len(S[i-1]) {
+			return nil, fmt.Errorf("%w: frame %d has %d bins, frame %d has %d", ErrLength, i-1, len(S[i-1]), i, len(S[i]))
+		}
+		for k, m := range S[i] {
+			if d := m - S[i-1][k]; d > 0 {
+				flux[i] += d
+			}
+		}
+	}
+	return flux, nil
+}
+
+func spectralCentroid(frame []float64, sampleRate float64) float64 {
+	c, sum := 0.0, 0.0
+	for k, m := range frame {
+		c += m * binFrequency(k, len(frame), sampleRate)
+		sum += m
+	}
+	if sum <= 0 {
+		return 0
+	}
+	return c / sum
+}
+
+// binFrequency returns the frequency of bin k of a frame of numBins non-negative frequency bins
+func binFrequency(k, numBins int, sampleRate float64) float64 {
+	if numBins < 2 {
+		return 0
+	}
+	return float64(k) * sampleRate / float64(2*(numBins-1))
+}
@@ -0,0 +1,249 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+)
+
+/*
+FFT returns the discrete Fourier transform of x:
+
+	X[k] = sum_n x[n] exp(-2 pi i k n / N)
+
+Vectors whose length is a power of 2 are transformed by the radix-2 algorithm.
+Other lengths are transformed by Bluestein's algorithm. x is not modified.
+*/
+func FFT(x []complex128) []complex128 {
+	X := make([]complex128, len(x))
+	copy(X, x)
+	switch {
+	case len(X) <= 1:
+	case IsPowerOf2(len(X)):
+		radix2(X, false)
+	default:
+		X = bluestein(X, false)
+	}
+	return X
+}
+
+/*
+IFFT returns the inverse discrete Fourier transform of X:
+
+	x[n] = 1/N sum_k X[k] exp(2 pi i k n / N)
+*/
+func IFFT(X []complex128) []complex128 {
+	x := make([]complex128, len(X))
+	copy(x, X)
+	switch {
+	case len(x) <= 1:
+	case IsPowerOf2(len(x)):
+		radix2(x, true)
+	default:
+		x = bluestein(x, true)
+	}
+	N := complex(float64(len(x)), 0)
+	for i := range x {
+		x[i] /= N
+	}
+	return x
+}
+
+/*
+FFTContext returns FFT(x) or ctx.Err() if ctx is done before the transform is
+complete. ctx is checked between the stages of the transform.
+*/
+func FFTContext(ctx context.Context, x []complex128) ([]complex128, error) {
+	X := make([]complex128, len(x))
+	copy(X, x)
+	switch {
+	case len(X) <= 1:
+	case IsPowerOf2(len(X)):
+		if err := radix2Context(ctx, X, false); err != nil {
+			return nil, err
+		}
+	default:
+		return bluesteinContext(ctx, X, false)
+	}
+	return X, nil
+}
+
+/*
+IFFTContext returns IFFT(X) or ctx.Err() if ctx is done before the transform is
+complete. ctx is checked between the stages of the transform.
+*/
+func IFFTContext(ctx context.Context, X []complex128) ([]complex128, error) {
+	x := make([]complex128, len(X))
+	copy(x, X)
+	var err error
+	switch {
+	case len(x) <= 1:
+	case IsPowerOf2(len(x)):
+		err = radix2Context(ctx, x, true)
+	default:
+		x, err = bluesteinContext(ctx, x, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	N := complex(float64(len(x)), 0)
+	for i := range x {
+		x[i] /= N
+	}
+	return x, nil
+}
+
+// FFTReal returns the discrete Fourier transform of the real vector x.
+func FFTReal(x []float64) []complex128 {
+	return FFT(ToComplex(x))
+}
+
+/*
+IFFTReal returns the real part of the inverse discrete Fourier transform of X.
+The imaginary part is zero if X is the transform of a real vector.
+*/
+func IFFTReal(X []complex128) []float64 {
+	return Real(IFFT(X))
+}
+
+// Real returns the real parts of the elements of x
+func Real(x []complex128) []float64 {
+	y := make([]float64, len(x))
+	for i, c := range x {
+		y[i] = real(c)
+	}
+	return y
+}
+
+// ToComplex returns a copy of x with type []complex128
+func ToComplex(x []float64) []complex128 {
+	y := make([]complex128, len(x))
+	for i, f := range x {
+		y[i] = complex(f, 0)
+	}
+	return y
+}
+
+/*
+radix2 computes the in-place iterative radix-2 FFT of x, whose length must be a
+power of 2. If inverse is true the sign of the exponent is positive and the
+result is not scaled.
+*/
+func radix2(x []complex128, inverse bool) {
+	radix2Context(context.Background(), x, inverse)
+}
+
+/*
+radix2Context computes radix2(x, inverse) and returns ctx.Err() if ctx is done
+before the last stage of the transform.
+*/
+func radix2Context(ctx context.Context, x []complex128, inverse bool) error {
+	N := len(x)
+	for i, j := 1, 0; i < N; i++ {
+		bit := N >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= N; size <<= 1 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		half := size / 2
+		theta := sign * 2 * math.Pi / float64(size)
+		for k := 0; k < half; k++ {
+			w := cmplx.Rect(1, theta*float64(k))
+			for i := k; i < N; i += size {
+				t := w * x[i+half]
+				x[i+half] = x[i] - t
+				x[i] += t
+			}
+		}
+	}
+	return nil
+}
+
+/*
+bluestein computes the FFT of x of arbitrary length as a convolution that is
+computed by radix-2 FFTs.
+*/
+func bluestein(x []complex128, inverse bool) []complex128 {
+	X, _ := bluesteinContext(context.Background(), x, inverse)
+	return X
+}
+
+/*
+bluesteinContext computes bluestein(x, inverse) and returns ctx.Err() if ctx
+is done before the transform is complete.
+*/
+func bluesteinContext(ctx context.Context, x []complex128, inverse bool) ([]complex128, error) {
+	N := len(x)
+	M := NextPow2(2*N - 1)
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	// chirp[n] = exp(sign i pi n^2 / N). n^2 is reduced mod 2N for precision.
+	chirp := make([]complex128, N)
+	for n := range chirp {
+		n2 := (n * n) % (2 * N)
+		chirp[n] = cmplx.Rect(1, sign*math.Pi*float64(n2)/float64(N))
+	}
+	a, b := make([]complex128, M), make([]complex128, M)
+	for n := range x {
+		a[n] = x[n] * chirp[n]
+	}
+	b[0] = cmplx.Conj(chirp[0])
+	for n := 1; n < N; n++ {
+		b[n] = cmplx.Conj(chirp[n])
+		b[M-n] = b[n]
+	}
+	if err := radix2Context(ctx, a, false); err != nil {
+		return nil, err
+	}
+	if err := radix2Context(ctx, b, false); err != nil {
+		return nil, err
+	}
+	for i := range a {
+		a[i] *= b[i]
+	}
+	if err := radix2Context(ctx, a, true); err != nil {
+		return nil, err
+	}
+	X := make([]complex128, N)
+	for k := range X {
+		X[k] = a[k] * chirp[k] / complex(float64(M), 0)
+	}
+	return X, nil
+}
+
+// NextPow2 returns the smallest power of 2 >= n.
+func NextPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,149 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package filter
+
+import (
+	"math"
+)
+
+/*
+Biquad is a second order IIR filter section with transfer function
+
+	H(z) = (B0 + B1 z^-1 + B2 z^-2) / (1 + A1 z^-1 + A2 z^-2)
+
+It is implemented in Direct Form II transposed and keeps its state between
+calls to Process and ProcessBlock, so a stream may be filtered block by block.
+
+The Biquad* constructors use the formulae of:
+
+	Cookbook formulae for audio EQ biquad filter coefficients. R. Bristow-Johnson.
+	https://www.w3.org/TR/audio-eq-cookbook/
+*/
+type Biquad struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+	z1, z2     float64
+}
+
+// BiquadLowpass returns a lowpass filter with cutoff f0Hz and quality factor Q.
+func BiquadLowpass(f0Hz, sampleRate, Q float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	return newBiquad((1-cosw)/2, 1-cosw, (1-cosw)/2, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// BiquadHighpass returns a highpass filter with cutoff f0Hz and quality factor Q.
+func BiquadHighpass(f0Hz, sampleRate, Q float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	return newBiquad((1+cosw)/2, -(1 + cosw), (1+cosw)/2, 1+alpha, -2*cosw, 1-alpha)
+}
+
+/*
+BiquadBandpass returns a bandpass filter with center frequency f0Hz, quality
+factor Q and a peak gain of 0 dB.
+*/
+func BiquadBandpass(f0Hz, sampleRate, Q float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	return newBiquad(alpha, 0, -alpha, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// BiquadNotch returns a notch filter with center frequency f0Hz and quality factor Q.
+func BiquadNotch(f0Hz, sampleRate, Q float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	return newBiquad(1, -2*cosw, 1, 1+alpha, -2*cosw, 1-alpha)
+}
+
+/*
+BiquadPeaking returns a peaking EQ filter with center frequency f0Hz, quality
+factor Q and gainDb at f0Hz.
+*/
+func BiquadPeaking(f0Hz, sampleRate, Q, gainDb float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	A := math.Pow(10, gainDb/40)
+	return newBiquad(1+alpha*A, -2*cosw, 1-alpha*A, 1+alpha/A, -2*cosw, 1-alpha/A)
+}
+
+/*
+BiquadLowShelf returns a low shelf filter with midpoint frequency f0Hz,
+quality factor Q and gainDb below f0Hz.
+*/
+func BiquadLowShelf(f0Hz, sampleRate, Q, gainDb float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	A := math.Pow(10, gainDb/40)
+	sq := 2 * math.Sqrt(A) * alpha
+	return newBiquad(
+		A*((A+1)-(A-1)*cosw+sq),
+		2*A*((A-1)-(A+1)*cosw),
+		A*((A+1)-(A-1)*cosw-sq),
+		(A+1)+(A-1)*cosw+sq,
+		-2*((A-1)+(A+1)*cosw),
+		(A+1)+(A-1)*cosw-sq)
+}
+
+/*
+BiquadHighShelf returns a high shelf filter with midpoint frequency f0Hz,
+quality factor Q and gainDb above f0Hz.
+*/
+func BiquadHighShelf(f0Hz, sampleRate, Q, gainDb float64) *Biquad {
+	cosw, alpha := rbj(f0Hz, sampleRate, Q)
+	A := math.Pow(10, gainDb/40)
+	sq := 2 * math.Sqrt(A) * alpha
+	return newBiquad(
+		A*((A+1)+(A-1)*cosw+sq),
+		-2*A*((A-1)+(A+1)*cosw),
+		A*((A+1)+(A-1)*cosw-sq),
+		(A+1)-(A-1)*cosw+sq,
+		2*((A-1)-(A+1)*cosw),
+		(A+1)-(A-1)*cosw-sq)
+}
+
+// newBiquad returns the section with coefficients normalised by a0.
+func newBiquad(b0, b1, b2, a0, a1, a2 float64) *Biquad {
+	return &Biquad{
+		B0: b0 / a0, B1: b1 / a0, B2: b2 / a0,
+		A1: a1 / a0, A2: a2 / a0,
+	}
+}
+
+// rbj returns cos(w0) and alpha = sin(w0)/(2Q) of the cookbook formulae.
+func rbj(f0Hz, sampleRate, Q float64) (cosw, alpha float64) {
+	checkCutoff(f0Hz, sampleRate)
+	if Q <= 0 {
+		panic("Q must be > 0")
+	}
+	w0 := 2 * math.Pi * f0Hz / sampleRate
+	return math.Cos(w0), math.Sin(w0) / (2 * Q)
+}
+
+// Process returns the output of the filter for the next input sample x.
+func (b *Biquad) Process(x float64) float64 {
+	y := b.B0*x + b.z1
+	b.z1 = b.B1*x - b.A1*y + b.z2
+	b.z2 = b.B2*x - b.A2*y
+	return y
+}
+
+// ProcessBlock returns the output of the filter for the input block x.
+func (b *Biquad) ProcessBlock(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for i, xi := range x {
+		y[i] = b.Process(xi)
+	}
+	return y
+}
+
+// Reset clears the state of the filter.
+func (b *Biquad) Reset() {
+	b.z1, b.z2 = 0, 0
+}
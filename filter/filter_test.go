@@ -0,0 +1,257 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/internal/golden"
+)
+
+// gain returns the gain of filter h at frequency f in cycles per sample
+func gain(h []float64, f float64) float64 {
+	re, im := 0.0, 0.0
+	for n, c := range h {
+		re += c * math.Cos(2*math.Pi*f*float64(n))
+		im += c * math.Sin(2*math.Pi*f*float64(n))
+	}
+	return math.Hypot(re, im)
+}
+
+func TestFIR(t *testing.T) {
+	fs := 1000.0
+	for _, test := range []struct {
+		name       string
+		f          *FIR
+		pass, stop float64
+	}{
+		{"lowpass", FIRLowpass(100, fs, 101, nil), 20, 300},
+		{"highpass", FIRHighpass(100, fs, 101, nil), 300, 20},
+		{"bandpass", FIRBandpass(100, 200, fs, 101, nil), 150, 400},
+		{"bandstop", FIRBandstop(100, 200, fs, 101, nil), 400, 150},
+	} {
+		if g := gain(test.f.Taps, test.pass/fs); math.Abs(g-1) > 0.01 {
+			t.Errorf("%s: pass band gain = %f", test.name, g)
+		}
+		if g := gain(test.f.Taps, test.stop/fs); g > 0.01 {
+			t.Errorf("%s: stop band gain = %f", test.name, g)
+		}
+	}
+}
+
+func TestFIRApply(t *testing.T) {
+	f := FIRLowpass(100, 1000, 31, nil)
+	x := make([]float64, 200)
+	for i := range x {
+		x[i] = math.Sin(float64(i))
+	}
+	want := godsp.Conv(x, f.Taps)
+	y := append(f.Apply(x[:77]), f.Apply(x[77:])...)
+	for i := range y {
+		if math.Abs(y[i]-want[i]) > 1e-12 {
+			t.Fatalf("y[%d] = %f, want %f", i, y[i], want[i])
+		}
+	}
+}
+
+// iirGain returns the gain of filter f at frequency f0 in cycles per sample
+func iirGain(f *IIR, f0 float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*f0))
+	H := complex(1, 0)
+	for _, s := range f.Sections {
+		num := complex(s.B0, 0) + complex(s.B1, 0)*z + complex(s.B2, 0)*z*z
+		den := 1 + complex(s.A1, 0)*z + complex(s.A2, 0)*z*z
+		H *= num / den
+	}
+	return cmplx.Abs(H)
+}
+
+func TestIIR(t *testing.T) {
+	fs := 1000.0
+	for _, test := range []struct {
+		name       string
+		f          *IIR
+		pass, stop float64
+		ripple     float64
+	}{
+		{"butterworth lowpass", ButterworthLowpass(6, 100, fs), 20, 300, 0.01},
+		{"butterworth highpass", ButterworthHighpass(5, 100, fs), 300, 20, 0.01},
+		{"butterworth bandpass", ButterworthBandpass(4, 100, 200, fs), 145, 400, 0.01},
+		{"butterworth bandstop", ButterworthBandstop(4, 100, 200, fs), 400, 145, 0.01},
+		{"chebyshev lowpass", Chebyshev1Lowpass(10, 1, 100, fs), 50, 300, 0.11},
+		{"chebyshev highpass", Chebyshev1Highpass(3, 1, 100, fs), 300, 20, 0.11},
+		{"chebyshev bandpass", Chebyshev1Bandpass(4, 0.5, 100, 200, fs), 145, 400, 0.06},
+	} {
+		if g := iirGain(test.f, test.pass/fs); math.Abs(g-1) > test.ripple {
+			t.Errorf("%s: pass band gain = %f", test.name, g)
+		}
+		if g := iirGain(test.f, test.stop/fs); g > 0.01 {
+			t.Errorf("%s: stop band gain = %f", test.name, g)
+		}
+	}
+}
+
+func TestFiltFilt(t *testing.T) {
+	f := ButterworthLowpass(4, 50, 1000)
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * 5 * float64(i) / 1000)
+	}
+	y := f.FiltFilt(x)
+	for i := 100; i < 900; i++ {
+		if math.Abs(y[i]-x[i]) > 1e-3 {
+			t.Fatalf("y[%d] = %f, want %f", i, y[i], x[i])
+		}
+	}
+}
+
+func TestBiquad(t *testing.T) {
+	fs := 48000.0
+	for _, test := range []struct {
+		name string
+		b    *Biquad
+		f    float64
+		want float64
+	}{
+		{"lowpass", BiquadLowpass(1000, fs, math.Sqrt2/2), 10, 1},
+		{"highpass", BiquadHighpass(1000, fs, math.Sqrt2/2), 20000, 1},
+		{"bandpass", BiquadBandpass(1000, fs, 2), 1000, 1},
+		{"notch", BiquadNotch(1000, fs, 2), 1000, 0},
+		{"peaking", BiquadPeaking(1000, fs, 2, 6), 1000, math.Pow(10, 6.0/20)},
+		{"low shelf", BiquadLowShelf(1000, fs, math.Sqrt2/2, -6), 1, math.Pow(10, -6.0/20)},
+		{"high shelf", BiquadHighShelf(1000, fs, math.Sqrt2/2, 6), 23000, math.Pow(10, 6.0/20)},
+	} {
+		if g := iirGain(&IIR{Sections: []*Biquad{test.b}}, test.f/fs); math.Abs(g-test.want) > 0.01 {
+			t.Errorf("%s: gain = %f, want %f", test.name, g, test.want)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	from, to, f0 := 48000, 44100, 1000.0
+	x := make([]float64, 4800)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * f0 * float64(i) / float64(from))
+	}
+	var last, calls int
+	y := ResampleProgress(x, from, to, func(done, total int) {
+		if done < last || total != 4410 {
+			t.Fatalf("progress %d of %d", done, total)
+		}
+		last = done
+		calls++
+	})
+	if len(y) != 4410 || last != 4410 || calls != 1 {
+		t.Fatalf("len(y) = %d, progress %d in %d calls", len(y), last, calls)
+	}
+	for i := 200; i < len(y)-200; i++ {
+		want := math.Sin(2 * math.Pi * f0 * float64(i) / float64(to))
+		if math.Abs(y[i]-want) > 1e-3 {
+			t.Fatalf("y[%d] = %f, want %f", i, y[i], want)
+		}
+	}
+}
+
+func TestDecimate(t *testing.T) {
+	fs, f0, f1 := 8000.0, 100.0, 3000.0
+	x := make([]float64, 8000)
+	for i := range x {
+		x[i] = math.Sin(2*math.Pi*f0*float64(i)/fs) + math.Sin(2*math.Pi*f1*float64(i)/fs)
+	}
+	for _, p := range []Prefilter{FIRPrefilter, IIRPrefilter} {
+		y := Decimate(x, 4, p)
+		if len(y) != 2000 {
+			t.Fatalf("len(y) = %d, want 2000", len(y))
+		}
+		for i := 100; i < len(y)-100; i++ {
+			want := math.Sin(2 * math.Pi * f0 * float64(i) / (fs / 4))
+			if math.Abs(y[i]-want) > 0.01 {
+				t.Fatalf("prefilter %d: y[%d] = %f, want %f", p, i, y[i], want)
+			}
+		}
+	}
+}
+
+func TestReadWavFileResampled(t *testing.T) {
+	from, to := 8000, 16000
+	x := make([]float64, 800)
+	for i := range x {
+		x[i] = 0.5 * math.Sin(2*math.Pi*440*float64(i)/float64(from))
+	}
+	fname := filepath.Join(t.TempDir(), "test.wav")
+	writeWav16(t, fname, from, x, x)
+	channels, bitsPerSample := ReadWavFileResampled(fname, to)
+	if len(channels) != 2 || bitsPerSample != 16 || len(channels[0]) != 1600 {
+		t.Fatalf("%d channels, %d bits, %d samples", len(channels), bitsPerSample, len(channels[0]))
+	}
+	// ReadWavFile maps 16 bit samples to [0,1]
+	for i := 100; i < 1500; i++ {
+		want := 0.5 + 0.25*math.Sin(2*math.Pi*440*float64(i)/float64(to))
+		if math.Abs(channels[1][i]-want) > 1e-3 {
+			t.Fatalf("y[%d] = %f, want %f", i, channels[1][i], want)
+		}
+	}
+}
+
+// writeWav16 writes the channels to a 16 bit PCM wav file
+func writeWav16(t *testing.T, fname string, sampleRate int, channels ...[]float64) {
+	buf := new(bytes.Buffer)
+	numCh, n := len(channels), len(channels[0])
+	dataLen := 2 * numCh * n
+	w := func(v interface{}) { binary.Write(buf, binary.LittleEndian, v) }
+	buf.WriteString("RIFF")
+	w(uint32(36 + dataLen))
+	buf.WriteString("WAVEfmt ")
+	w(uint32(16))
+	w(uint16(1))
+	w(uint16(numCh))
+	w(uint32(sampleRate))
+	w(uint32(sampleRate * numCh * 2))
+	w(uint16(numCh * 2))
+	w(uint16(16))
+	buf.WriteString("data")
+	w(uint32(dataLen))
+	for i := 0; i < n; i++ {
+		for _, ch := range channels {
+			w(int16(math.Round(ch[i] * 32767)))
+		}
+	}
+	if err := os.WriteFile(fname, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	for _, c := range golden.Load(t, "testdata/filters.json").Cases {
+		fs := c.Param(t, "sampleRate")
+		impulse := make([]float64, len(c.Want))
+		impulse[0] = 1
+		switch name := strings.Fields(c.Name)[0]; name {
+		case "BiquadLowpass":
+			c.Check(t, BiquadLowpass(c.Param(t, "f0"), fs, c.Param(t, "Q")).ProcessBlock(impulse))
+		case "BiquadPeaking":
+			c.Check(t, BiquadPeaking(c.Param(t, "f0"), fs, c.Param(t, "Q"), c.Param(t, "gainDb")).ProcessBlock(impulse))
+		default:
+			order, cutoff := int(c.Param(t, "order")), c.Param(t, "cutoff")
+			f := map[string]func() *IIR{
+				"ButterworthLowpass":  func() *IIR { return ButterworthLowpass(order, cutoff, fs) },
+				"ButterworthHighpass": func() *IIR { return ButterworthHighpass(order, cutoff, fs) },
+				"Chebyshev1Lowpass":   func() *IIR { return Chebyshev1Lowpass(order, c.Param(t, "rippleDb"), cutoff, fs) },
+			}[name]()
+			impulse = make([]float64, 4096)
+			impulse[0] = 1
+			h := f.Apply(impulse)
+			got := make([]float64, len(c.Input))
+			for i, freq := range c.Input {
+				got[i] = gain(h, freq/fs)
+			}
+			c.Check(t, got)
+		}
+	}
+}
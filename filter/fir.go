@@ -0,0 +1,188 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package filter has functions for the design and application of digital filters.
+*/
+package filter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/windows"
+)
+
+/*
+FIR is a finite impulse response filter. Taps may be used with godsp.Conv to
+filter a complete signal. Apply filters a signal that arrives in blocks.
+*/
+type FIR struct {
+	Taps []float64
+	hist []float64
+}
+
+// NewFIR returns a FIR filter with the taps h.
+func NewFIR(h []float64) *FIR {
+	f := &FIR{
+		Taps: make([]float64, len(h)),
+		hist: make([]float64, len(h)),
+	}
+	copy(f.Taps, h)
+	return f
+}
+
+/*
+FIRLowpass returns a windowed-sinc lowpass filter with cutoff frequency
+cutoffHz and numTaps taps. If window is nil a Hamming window is used.
+The gain of the filter at 0 Hz is 1.
+*/
+func FIRLowpass(cutoffHz, sampleRate float64, numTaps int, window godsp.Window) *FIR {
+	checkCutoff(cutoffHz, sampleRate)
+	h := sinc(cutoffHz/sampleRate, numTaps, window)
+	return NewFIR(godsp.DivS(h, godsp.Sum(h)))
+}
+
+/*
+FIRHighpass returns a windowed-sinc highpass filter with cutoff frequency
+cutoffHz and numTaps taps. If window is nil a Hamming window is used.
+The function panics if numTaps is even.
+*/
+func FIRHighpass(cutoffHz, sampleRate float64, numTaps int, window godsp.Window) *FIR {
+	checkOdd(numTaps)
+	return NewFIR(invert(FIRLowpass(cutoffHz, sampleRate, numTaps, window).Taps))
+}
+
+/*
+FIRBandpass returns a windowed-sinc bandpass filter passing lowHz to highHz
+with numTaps taps. If window is nil a Hamming window is used.
+The gain of the filter at the center of the pass band is 1.
+*/
+func FIRBandpass(lowHz, highHz, sampleRate float64, numTaps int, window godsp.Window) *FIR {
+	checkBand(lowHz, highHz, sampleRate)
+	h := godsp.Sub(sinc(highHz/sampleRate, numTaps, window),
+		sinc(lowHz/sampleRate, numTaps, window))
+	// Normalise the gain at the center frequency of the band
+	fc := (lowHz + highHz) / 2 / sampleRate
+	re, im := 0.0, 0.0
+	for n, f := range h {
+		re += f * math.Cos(2*math.Pi*fc*float64(n))
+		im += f * math.Sin(2*math.Pi*fc*float64(n))
+	}
+	return NewFIR(godsp.DivS(h, math.Hypot(re, im)))
+}
+
+/*
+FIRBandstop returns a windowed-sinc bandstop filter rejecting lowHz to highHz
+with numTaps taps. If window is nil a Hamming window is used.
+The function panics if numTaps is even.
+*/
+func FIRBandstop(lowHz, highHz, sampleRate float64, numTaps int, window godsp.Window) *FIR {
+	checkOdd(numTaps)
+	checkBand(lowHz, highHz, sampleRate)
+	lp := FIRLowpass(lowHz, sampleRate, numTaps, window).Taps
+	hp := FIRHighpass(highHz, sampleRate, numTaps, window).Taps
+	h := make([]float64, numTaps)
+	for i := range h {
+		h[i] = lp[i] + hp[i]
+	}
+	return NewFIR(h)
+}
+
+/*
+Apply returns the next len(x) samples of the output of the filter for the
+input block x. The filter keeps the last len(f.Taps)-1 input samples, so
+consecutive blocks are filtered as one continuous signal.
+*/
+func (f *FIR) Apply(x []float64) []float64 {
+	y := make([]float64, len(x))
+	N := len(f.Taps)
+	if N == 0 {
+		return y
+	}
+	for i, xi := range x {
+		copy(f.hist[1:], f.hist[:N-1])
+		f.hist[0] = xi
+		sum := 0.0
+		for k, h := range f.Taps {
+			sum += h * f.hist[k]
+		}
+		y[i] = sum
+	}
+	return y
+}
+
+// Reset clears the input history of the filter.
+func (f *FIR) Reset() {
+	for i := range f.hist {
+		f.hist[i] = 0
+	}
+}
+
+/*
+sinc returns the windowed ideal lowpass filter with normalised cutoff frequency
+fc (cycles per sample) and numTaps taps.
+*/
+func sinc(fc float64, numTaps int, window godsp.Window) []float64 {
+	if numTaps < 1 {
+		panic(fmt.Sprintf("Invalid numTaps %d", numTaps))
+	}
+	if window == nil {
+		window = windows.Hamming
+	}
+	w := window(numTaps)
+	h := make([]float64, numTaps)
+	M := float64(numTaps-1) / 2
+	for n := range h {
+		t := float64(n) - M
+		if t == 0 {
+			h[n] = 2 * fc
+		} else {
+			h[n] = math.Sin(2*math.Pi*fc*t) / (math.Pi * t)
+		}
+		h[n] *= w[n]
+	}
+	return h
+}
+
+// invert returns the spectral inversion delta - h of the odd length filter h.
+func invert(h []float64) []float64 {
+	h1 := make([]float64, len(h))
+	for i, f := range h {
+		h1[i] = -f
+	}
+	h1[len(h)/2] += 1
+	return h1
+}
+
+func checkCutoff(cutoffHz, sampleRate float64) {
+	if cutoffHz <= 0 || cutoffHz >= sampleRate/2 {
+		panic(fmt.Sprintf("Cutoff %f Hz is not in (0, %f)", cutoffHz, sampleRate/2))
+	}
+}
+
+func checkBand(lowHz, highHz, sampleRate float64) {
+	checkCutoff(lowHz, sampleRate)
+	checkCutoff(highHz, sampleRate)
+	if lowHz >= highHz {
+		panic(fmt.Sprintf("lowHz (%f) >= highHz (%f)", lowHz, highHz))
+	}
+}
+
+func checkOdd(numTaps int) {
+	if numTaps%2 == 0 {
+		panic(fmt.Sprintf("numTaps (%d) must be odd", numTaps))
+	}
+}
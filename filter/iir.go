@@ -0,0 +1,407 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// MaxOrder is the maximum order of the IIR filter designs
+const MaxOrder = 10
+
+/*
+IIR is an infinite impulse response filter implemented as a cascade of second
+order sections.
+*/
+type IIR struct {
+	Sections []*Biquad
+}
+
+type bandType int
+
+const (
+	lowpass bandType = iota
+	highpass
+	bandpass
+	bandstop
+)
+
+// zpk is a filter given by its zeros, poles and gain
+type zpk struct {
+	z, p []complex128
+	k    float64
+}
+
+// ButterworthLowpass returns a Butterworth lowpass filter of order.
+func ButterworthLowpass(order int, cutoffHz, sampleRate float64) *IIR {
+	return design(butterworth(order), lowpass, sampleRate, cutoffHz)
+}
+
+// ButterworthHighpass returns a Butterworth highpass filter of order.
+func ButterworthHighpass(order int, cutoffHz, sampleRate float64) *IIR {
+	return design(butterworth(order), highpass, sampleRate, cutoffHz)
+}
+
+/*
+ButterworthBandpass returns a Butterworth bandpass filter passing lowHz to
+highHz. The resulting filter has order 2*order.
+*/
+func ButterworthBandpass(order int, lowHz, highHz, sampleRate float64) *IIR {
+	return design(butterworth(order), bandpass, sampleRate, lowHz, highHz)
+}
+
+/*
+ButterworthBandstop returns a Butterworth bandstop filter rejecting lowHz to
+highHz. The resulting filter has order 2*order.
+*/
+func ButterworthBandstop(order int, lowHz, highHz, sampleRate float64) *IIR {
+	return design(butterworth(order), bandstop, sampleRate, lowHz, highHz)
+}
+
+/*
+Chebyshev1Lowpass returns a Chebyshev type I lowpass filter of order with
+rippleDb peak to peak ripple in the pass band.
+*/
+func Chebyshev1Lowpass(order int, rippleDb, cutoffHz, sampleRate float64) *IIR {
+	return design(chebyshev1(order, rippleDb), lowpass, sampleRate, cutoffHz)
+}
+
+/*
+Chebyshev1Highpass returns a Chebyshev type I highpass filter of order with
+rippleDb peak to peak ripple in the pass band.
+*/
+func Chebyshev1Highpass(order int, rippleDb, cutoffHz, sampleRate float64) *IIR {
+	return design(chebyshev1(order, rippleDb), highpass, sampleRate, cutoffHz)
+}
+
+/*
+Chebyshev1Bandpass returns a Chebyshev type I bandpass filter passing lowHz to
+highHz with rippleDb peak to peak ripple in the pass band. The resulting filter
+has order 2*order.
+*/
+func Chebyshev1Bandpass(order int, rippleDb, lowHz, highHz, sampleRate float64) *IIR {
+	return design(chebyshev1(order, rippleDb), bandpass, sampleRate, lowHz, highHz)
+}
+
+/*
+Chebyshev1Bandstop returns a Chebyshev type I bandstop filter rejecting lowHz
+to highHz with rippleDb peak to peak ripple in the pass band. The resulting
+filter has order 2*order.
+*/
+func Chebyshev1Bandstop(order int, rippleDb, lowHz, highHz, sampleRate float64) *IIR {
+	return design(chebyshev1(order, rippleDb), bandstop, sampleRate, lowHz, highHz)
+}
+
+/*
+Apply returns the output of the filter for the input block x. The state of the
+filter is kept, so consecutive blocks are filtered as one continuous signal.
+*/
+func (f *IIR) Apply(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for i, xi := range x {
+		for _, s := range f.Sections {
+			xi = s.Process(xi)
+		}
+		y[i] = xi
+	}
+	return y
+}
+
+/*
+FiltFilt returns x filtered forwards and backwards, which gives zero phase
+distortion and the squared magnitude response of the filter. x is extended by
+odd reflection at both ends to reduce transients. The state of the filter is
+not used or modified.
+*/
+func (f *IIR) FiltFilt(x []float64) []float64 {
+	if len(x) == 0 {
+		return []float64{}
+	}
+	padLen := 3 * (2*len(f.Sections) + 1)
+	if padLen > len(x)-1 {
+		padLen = len(x) - 1
+	}
+	ext := make([]float64, 0, len(x)+2*padLen)
+	for i := padLen; i > 0; i-- {
+		ext = append(ext, 2*x[0]-x[i])
+	}
+	ext = append(ext, x...)
+	for i := 1; i <= padLen; i++ {
+		ext = append(ext, 2*x[len(x)-1]-x[len(x)-1-i])
+	}
+	g := f.clone()
+	y := reverse(g.Apply(ext))
+	g.Reset()
+	y = reverse(g.Apply(y))
+	return y[padLen : padLen+len(x)]
+}
+
+// Reset clears the state of the filter.
+func (f *IIR) Reset() {
+	for _, s := range f.Sections {
+		s.Reset()
+	}
+}
+
+// clone returns a copy of f with cleared state.
+func (f *IIR) clone() *IIR {
+	g := &IIR{Sections: make([]*Biquad, len(f.Sections))}
+	for i, s := range f.Sections {
+		g.Sections[i] = &Biquad{B0: s.B0, B1: s.B1, B2: s.B2, A1: s.A1, A2: s.A2}
+	}
+	return g
+}
+
+func reverse(x []float64) []float64 {
+	for i, j := 0, len(x)-1; i < j; i, j = i+1, j-1 {
+		x[i], x[j] = x[j], x[i]
+	}
+	return x
+}
+
+func checkOrder(order int) {
+	if order < 1 || order > MaxOrder {
+		panic(fmt.Sprintf("Order %d is not in [1,%d]", order, MaxOrder))
+	}
+}
+
+// butterworth returns the analog Butterworth lowpass prototype with cutoff 1 rad/s.
+func butterworth(order int) *zpk {
+	checkOrder(order)
+	p := make([]complex128, order)
+	for k := range p {
+		p[k] = cmplx.Exp(complex(0, math.Pi*float64(2*k+order+1)/float64(2*order)))
+	}
+	return &zpk{p: p, k: 1}
+}
+
+/*
+chebyshev1 returns the analog Chebyshev type I lowpass prototype with pass band
+edge 1 rad/s and rippleDb ripple.
+*/
+func chebyshev1(order int, rippleDb float64) *zpk {
+	checkOrder(order)
+	if rippleDb <= 0 {
+		panic(fmt.Sprintf("Invalid ripple %f dB", rippleDb))
+	}
+	eps := math.Sqrt(math.Pow(10, rippleDb/10) - 1)
+	mu := math.Asinh(1/eps) / float64(order)
+	p := make([]complex128, order)
+	k := complex(1, 0)
+	for i := range p {
+		theta := math.Pi * float64(2*i+1) / float64(2*order)
+		p[i] = complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta))
+		k *= -p[i]
+	}
+	gain := real(k)
+	if order%2 == 0 {
+		gain /= math.Sqrt(1 + eps*eps)
+	}
+	return &zpk{p: p, k: gain}
+}
+
+/*
+design transforms the analog lowpass prototype proto to band with the
+prewarped cutoff frequencies, maps it to the z-plane by the bilinear transform
+and returns it as a cascade of second order sections.
+*/
+func design(proto *zpk, band bandType, sampleRate float64, cutoffHz ...float64) *IIR {
+	w := make([]float64, len(cutoffHz))
+	for i, f := range cutoffHz {
+		checkCutoff(f, sampleRate)
+		w[i] = 2 * sampleRate * math.Tan(math.Pi*f/sampleRate)
+	}
+	var a *zpk
+	switch band {
+	case lowpass:
+		a = lp2lp(proto, w[0])
+	case highpass:
+		a = lp2hp(proto, w[0])
+	case bandpass:
+		checkBand(cutoffHz[0], cutoffHz[1], sampleRate)
+		a = lp2bp(proto, math.Sqrt(w[0]*w[1]), w[1]-w[0])
+	case bandstop:
+		checkBand(cutoffHz[0], cutoffHz[1], sampleRate)
+		a = lp2bs(proto, math.Sqrt(w[0]*w[1]), w[1]-w[0])
+	}
+	return toSections(bilinear(a, sampleRate))
+}
+
+func lp2lp(f *zpk, wo float64) *zpk {
+	z, p := scale(f.z, wo), scale(f.p, wo)
+	return &zpk{z, p, f.k * math.Pow(wo, float64(len(f.p)-len(f.z)))}
+}
+
+func lp2hp(f *zpk, wo float64) *zpk {
+	z, p := make([]complex128, 0, len(f.p)), make([]complex128, len(f.p))
+	for _, zi := range f.z {
+		z = append(z, complex(wo, 0)/zi)
+	}
+	for i, pi := range f.p {
+		p[i] = complex(wo, 0) / pi
+	}
+	for len(z) < len(p) {
+		z = append(z, 0)
+	}
+	return &zpk{z, p, f.k * real(prodNeg(f.z)/prodNeg(f.p))}
+}
+
+func lp2bp(f *zpk, wo, bw float64) *zpk {
+	transform := func(r []complex128) []complex128 {
+		var t []complex128
+		for _, ri := range r {
+			rl := ri * complex(bw/2, 0)
+			d := cmplx.Sqrt(rl*rl - complex(wo*wo, 0))
+			t = append(t, rl+d, rl-d)
+		}
+		return t
+	}
+	z, p := transform(f.z), transform(f.p)
+	degree := len(f.p) - len(f.z)
+	for i := 0; i < degree; i++ {
+		z = append(z, 0)
+	}
+	return &zpk{z, p, f.k * math.Pow(bw, float64(degree))}
+}
+
+func lp2bs(f *zpk, wo, bw float64) *zpk {
+	transform := func(r []complex128) []complex128 {
+		var t []complex128
+		for _, ri := range r {
+			rh := complex(bw/2, 0) / ri
+			d := cmplx.Sqrt(rh*rh - complex(wo*wo, 0))
+			t = append(t, rh+d, rh-d)
+		}
+		return t
+	}
+	z, p := transform(f.z), transform(f.p)
+	for len(z) < len(p) {
+		z = append(z, complex(0, wo), complex(0, -wo))
+	}
+	return &zpk{z, p, f.k * real(prodNeg(f.z)/prodNeg(f.p))}
+}
+
+// bilinear maps the analog filter f to the z-plane.
+func bilinear(f *zpk, sampleRate float64) *zpk {
+	fs2 := complex(2*sampleRate, 0)
+	z, p := make([]complex128, 0, len(f.p)), make([]complex128, len(f.p))
+	num, den := complex(1, 0), complex(1, 0)
+	for _, zi := range f.z {
+		z = append(z, (fs2+zi)/(fs2-zi))
+		num *= fs2 - zi
+	}
+	for i, pi := range f.p {
+		p[i] = (fs2 + pi) / (fs2 - pi)
+		den *= fs2 - pi
+	}
+	for len(z) < len(p) {
+		z = append(z, -1)
+	}
+	return &zpk{z, p, f.k * real(num/den)}
+}
+
+/*
+toSections groups the conjugate pairs and real values of the poles and zeros of
+f into second order sections. The gain of f is applied to the first section.
+*/
+func toSections(f *zpk) *IIR {
+	pp, zp := pairs(f.p), pairs(f.z)
+	// Process poles closest to the unit circle first and give each the
+	// nearest remaining zeros.
+	sort.Slice(pp, func(i, j int) bool { return cmplx.Abs(pp[i][0]) > cmplx.Abs(pp[j][0]) })
+	iir := &IIR{}
+	for _, p := range pp {
+		best := 0
+		for j := range zp {
+			if cmplx.Abs(zp[j][0]-p[0]) < cmplx.Abs(zp[best][0]-p[0]) {
+				best = j
+			}
+		}
+		z := zp[best]
+		zp = append(zp[:best], zp[best+1:]...)
+		b0, b1, b2 := quadratic(z)
+		_, a1, a2 := quadratic(p)
+		iir.Sections = append(iir.Sections, &Biquad{B0: b0, B1: b1, B2: b2, A1: a1, A2: a2})
+	}
+	s := iir.Sections[0]
+	s.B0, s.B1, s.B2 = s.B0*f.k, s.B1*f.k, s.B2*f.k
+	return iir
+}
+
+/*
+pairs returns r grouped into conjugate pairs and pairs of real values.
+A single remaining real value is paired with 0, which represents a first order
+section.
+*/
+func pairs(r []complex128) [][2]complex128 {
+	const tol = 1e-9
+	var ps [][2]complex128
+	var reals []complex128
+	used := make([]bool, len(r))
+	for i, ri := range r {
+		if used[i] {
+			continue
+		}
+		if math.Abs(imag(ri)) <= tol*math.Max(1, cmplx.Abs(ri)) {
+			reals = append(reals, complex(real(ri), 0))
+			continue
+		}
+		used[i] = true
+		for j := i + 1; j < len(r); j++ {
+			if !used[j] && cmplx.Abs(r[j]-cmplx.Conj(ri)) <= tol*math.Max(1, cmplx.Abs(ri)) {
+				used[j] = true
+				break
+			}
+		}
+		ps = append(ps, [2]complex128{ri, cmplx.Conj(ri)})
+	}
+	sort.Slice(reals, func(i, j int) bool { return real(reals[i]) < real(reals[j]) })
+	for i := 0; i < len(reals); i += 2 {
+		if i+1 < len(reals) {
+			ps = append(ps, [2]complex128{reals[i], reals[i+1]})
+		} else {
+			ps = append(ps, [2]complex128{reals[i], 0})
+		}
+	}
+	return ps
+}
+
+/*
+quadratic returns the coefficients of (1 - r0 z^-1)(1 - r1 z^-1). A pair
+(r0, 0) gives the first order polynomial 1 - r0 z^-1.
+*/
+func quadratic(r [2]complex128) (c0, c1, c2 float64) {
+	return 1, -real(r[0] + r[1]), real(r[0] * r[1])
+}
+
+func scale(r []complex128, s float64) []complex128 {
+	r1 := make([]complex128, len(r))
+	for i, ri := range r {
+		r1[i] = ri * complex(s, 0)
+	}
+	return r1
+}
+
+// prodNeg returns the product of -r[i]
+func prodNeg(r []complex128) complex128 {
+	p := complex(1, 0)
+	for _, ri := range r {
+		p *= -ri
+	}
+	return p
+}
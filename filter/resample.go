@@ -0,0 +1,167 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/windows"
+)
+
+/*
+Resample returns x, sampled at fromRate, resampled to toRate by a polyphase
+FIR filter. x is upsampled by L and downsampled by M, where L/M is
+toRate/fromRate in lowest terms. The anti-aliasing filter is a Kaiser windowed
+sinc with cutoff at the lower of the two Nyquist frequencies and 20*max(L,M)+1
+taps. The delay of the filter is compensated, so the result is aligned with x
+and has length ceil(len(x)*L/M).
+The function panics if fromRate or toRate is not positive.
+*/
+func Resample(x []float64, fromRate, toRate int) []float64 {
+	return ResampleProgress(x, fromRate, toRate, nil)
+}
+
+/*
+ResampleProgress returns Resample(x, fromRate, toRate) and calls progress, if
+it is not nil, with the number of output samples computed out of the length of
+the result every resampleProgressInterval samples and on completion.
+The function panics if fromRate or toRate is not positive.
+*/
+func ResampleProgress(x []float64, fromRate, toRate int, progress godsp.ProgressFunc) []float64 {
+	if fromRate <= 0 || toRate <= 0 {
+		panic(fmt.Sprintf("Invalid rates %d, %d", fromRate, toRate))
+	}
+	g := gcd(fromRate, toRate)
+	L, M := toRate/g, fromRate/g
+	if L == 1 && M == 1 {
+		y := make([]float64, len(x))
+		copy(y, x)
+		if progress != nil {
+			progress(len(y), len(y))
+		}
+		return y
+	}
+	max := L
+	if M > max {
+		max = M
+	}
+	halfLen := 10 * max
+	h := sinc(0.5/float64(max), 2*halfLen+1, kaiser5)
+	h = godsp.DivS(h, godsp.Sum(h)/float64(L))
+	return polyphase(x, h, L, M, halfLen, progress)
+}
+
+// resampleProgressInterval is the number of output samples between the progress reports of ResampleProgress
+const resampleProgressInterval = 1 << 16
+
+/*
+polyphase returns x upsampled by L, filtered by h and downsampled by M.
+Only the non-zero samples of the upsampled signal are multiplied. The output
+is advanced by delay samples at the upsampled rate. progress, if not nil, is
+called every resampleProgressInterval output samples and on completion.
+*/
+func polyphase(x, h []float64, L, M, delay int, progress godsp.ProgressFunc) []float64 {
+	y := make([]float64, (len(x)*L+M-1)/M)
+	for m := range y {
+		t := m*M + delay
+		// x[i] contributes with tap t-i*L in [0,len(h))
+		i0 := 0
+		if t-len(h)+1 > 0 {
+			i0 = (t - len(h) + L) / L
+		}
+		i1 := t / L
+		if i1 > len(x)-1 {
+			i1 = len(x) - 1
+		}
+		sum := 0.0
+		for i := i0; i <= i1; i++ {
+			sum += h[t-i*L] * x[i]
+		}
+		y[m] = sum
+		if progress != nil && ((m+1)%resampleProgressInterval == 0 || m == len(y)-1) {
+			progress(m+1, len(y))
+		}
+	}
+	return y
+}
+
+func kaiser5(N int) []float64 {
+	return windows.Kaiser(N, 5)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Prefilter selects the anti-aliasing filter of Decimate
+type Prefilter int
+
+const (
+	// FIRPrefilter is a linear phase Hamming windowed sinc of 20*factor+1 taps
+	FIRPrefilter Prefilter = iota
+	// IIRPrefilter is an order 8 Chebyshev type I filter applied by FiltFilt
+	IIRPrefilter
+)
+
+/*
+Decimate returns x lowpass filtered and downsampled by factor. Both prefilters
+have zero phase, so the result is aligned with x and has length
+ceil(len(x)/factor).
+The function panics if factor < 1.
+*/
+func Decimate(x []float64, factor int, prefilter Prefilter) []float64 {
+	if factor < 1 {
+		panic(fmt.Sprintf("Invalid factor %d", factor))
+	}
+	if factor == 1 {
+		y := make([]float64, len(x))
+		copy(y, x)
+		return y
+	}
+	switch prefilter {
+	case FIRPrefilter:
+		halfLen := 10 * factor
+		h := sinc(0.5/float64(factor), 2*halfLen+1, windows.Hamming)
+		return polyphase(x, godsp.DivS(h, godsp.Sum(h)), 1, factor, halfLen, nil)
+	case IIRPrefilter:
+		f := Chebyshev1Lowpass(8, 0.05, 0.4/float64(factor), 1)
+		xf := f.FiltFilt(x)
+		y := make([]float64, (len(x)+factor-1)/factor)
+		for i := range y {
+			y[i] = xf[i*factor]
+		}
+		return y
+	default:
+		panic(fmt.Sprintf("Invalid prefilter %d", prefilter))
+	}
+}
+
+/*
+Upsample returns x upsampled by factor. Zeros are inserted between the samples
+of x and the result is filtered by a Kaiser windowed sinc interpolation filter
+with 20*factor+1 taps. The delay of the filter is compensated, so the result
+is aligned with x and has length len(x)*factor.
+The function panics if factor < 1.
+*/
+func Upsample(x []float64, factor int) []float64 {
+	if factor < 1 {
+		panic(fmt.Sprintf("Invalid factor %d", factor))
+	}
+	return Resample(x, 1, factor)
+}
@@ -0,0 +1,68 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package filter
+
+import (
+	"io"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+ReadWavFileResampled returns the demultiplexed channels of a wav file
+resampled to targetRate Hz by Resample. It is in package filter rather than
+next to godsp.ReadWavFile because it depends on Resample.
+The function panics if the file cannot be read or targetRate is not positive.
+*/
+func ReadWavFileResampled(wavName string, targetRate int) (channels [][]float64, bitsPerSample int) {
+	channels, bitsPerSample, err := ReadWavFileResampledErr(wavName, targetRate)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+ReadWavFileResampledErr returns ReadWavFileResampled(wavName, targetRate) or
+the error reading the file.
+The function panics if targetRate is not positive.
+*/
+func ReadWavFileResampledErr(wavName string, targetRate int) (channels [][]float64, bitsPerSample int, err error) {
+	channels, sampleRate, bitsPerSample, err := godsp.ReadWavFileErr(wavName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resampleChannels(channels, sampleRate, targetRate), bitsPerSample, nil
+}
+
+/*
+ReadWavResampledFrom returns the demultiplexed channels of the wav file read
+from r resampled to targetRate Hz, or the error reading or decoding r.
+The function panics if targetRate is not positive.
+*/
+func ReadWavResampledFrom(r io.Reader, targetRate int) (channels [][]float64, bitsPerSample int, err error) {
+	channels, sampleRate, bitsPerSample, err := godsp.ReadWavFrom(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resampleChannels(channels, sampleRate, targetRate), bitsPerSample, nil
+}
+
+func resampleChannels(channels [][]float64, sampleRate, targetRate int) [][]float64 {
+	for i, ch := range channels {
+		channels[i] = Resample(ch, sampleRate, targetRate)
+	}
+	return channels
+}
@@ -0,0 +1,236 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Filterbank is a bank of triangular band filters on the frequency bins of a
+spectrum of an FFT of size fftSize: the fftSize/2+1 bins of the frames of STFT,
+Spectrogram or PowerSpectrogram. The bands are stored sparsely: only the bins
+with non-zero weights.
+*/
+type Filterbank struct {
+	bands   []band
+	centres []float64
+	numBins int
+}
+
+// band contains the weights of the bins start, start+1, ... of a band
+type band struct {
+	start   int
+	weights []float64
+}
+
+/*
+MelFilterbank returns a filterbank of numBands triangular filters with centre
+frequencies equally spaced on the mel scale, mel = 2595 log10(1 + f/700),
+between fmin and fmax Hz. The filters of adjacent bands overlap: each filter
+rises from the centre of the band below it to 1 at its centre and falls to 0
+at the centre of the band above it.
+The function panics if numBands < 1, fmin < 0, fmax <= fmin, fmax is above
+sampleRate/2 or fftSize < 2.
+*/
+func MelFilterbank(numBands int, fmin, fmax, sampleRate float64, fftSize int) *Filterbank {
+	fb, err := MelFilterbankErr(numBands, fmin, fmax, sampleRate, fftSize)
+	if err != nil {
+		panic(err)
+	}
+	return fb
+}
+
+/*
+MelFilterbankErr returns MelFilterbank(numBands, fmin, fmax, sampleRate,
+fftSize) or an error wrapping ErrArgument if an argument is out of range.
+*/
+func MelFilterbankErr(numBands int, fmin, fmax, sampleRate float64, fftSize int) (*Filterbank, error) {
+	return newFilterbank(numBands, fmin, fmax, sampleRate, fftSize, HzToMel, MelToHz)
+}
+
+/*
+BarkFilterbank returns a filterbank of numBands triangular filters like
+MelFilterbank with centre frequencies equally spaced on the Bark scale. See
+HzToBark.
+The function panics if numBands < 1, fmin < 0, fmax <= fmin, fmax is above
+sampleRate/2 or fftSize < 2.
+*/
+func BarkFilterbank(numBands int, fmin, fmax, sampleRate float64, fftSize int) *Filterbank {
+	fb, err := BarkFilterbankErr(numBands, fmin, fmax, sampleRate, fftSize)
+	if err != nil {
+		panic(err)
+	}
+	return fb
+}
+
+/*
+BarkFilterbankErr returns BarkFilterbank(numBands, fmin, fmax, sampleRate,
+fftSize) or an error wrapping ErrArgument if an argument is out of range.
+*/
+func BarkFilterbankErr(numBands int, fmin, fmax, sampleRate float64, fftSize int) (*Filterbank, error) {
+	return newFilterbank(numBands, fmin, fmax, sampleRate, fftSize, HzToBark, BarkToHz)
+}
+
+/*
+ERBFilterbank returns a filterbank of numBands triangular filters like
+MelFilterbank with centre frequencies equally spaced on the ERB-rate scale.
+See HzToERB. The triangular filters approximate the gammatone filters of the
+auditory models that use this scale.
+The function panics if numBands < 1, fmin < 0, fmax <= fmin, fmax is above
+sampleRate/2 or fftSize < 2.
+*/
+func ERBFilterbank(numBands int, fmin, fmax, sampleRate float64, fftSize int) *Filterbank {
+	fb, err := ERBFilterbankErr(numBands, fmin, fmax, sampleRate, fftSize)
+	if err != nil {
+		panic(err)
+	}
+	return fb
+}
+
+/*
+ERBFilterbankErr returns ERBFilterbank(numBands, fmin, fmax, sampleRate,
+fftSize) or an error wrapping ErrArgument if an argument is out of range.
+*/
+func ERBFilterbankErr(numBands int, fmin, fmax, sampleRate float64, fftSize int) (*Filterbank, error) {
+	return newFilterbank(numBands, fmin, fmax, sampleRate, fftSize, HzToERB, ERBToHz)
+}
+
+// HzToMel returns the frequency f Hz on the mel scale: 2595 log10(1 + f/700)
+func HzToMel(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// MelToHz returns the frequency in Hz of m mel. It is the inverse of HzToMel.
+func MelToHz(m float64) float64 {
+	return 700 * (math.Pow(10, m/2595) - 1)
+}
+
+/*
+HzToBark returns the frequency f Hz on the Bark scale by Traunmüller's
+formula: 26.81 f/(1960 + f) - 0.53
+*/
+func HzToBark(f float64) float64 {
+	return 26.81*f/(1960+f) - 0.53
+}
+
+// BarkToHz returns the frequency in Hz of z Bark. It is the inverse of HzToBark.
+func BarkToHz(z float64) float64 {
+	return 1960 * (z + 0.53) / (26.28 - z)
+}
+
+/*
+HzToERB returns the frequency f Hz on the ERB-rate scale of Glasberg and
+Moore: 21.4 log10(1 + 0.00437 f), the number of equivalent rectangular
+bandwidths below f.
+*/
+func HzToERB(f float64) float64 {
+	return 21.4 * math.Log10(1+0.00437*f)
+}
+
+// ERBToHz returns the frequency in Hz of e ERBs. It is the inverse of HzToERB.
+func ERBToHz(e float64) float64 {
+	return (math.Pow(10, e/21.4) - 1) / 0.00437
+}
+
+func newFilterbank(numBands int, fmin, fmax, sampleRate float64, fftSize int,
+	toScale, fromScale func(float64) float64) (*Filterbank, error) {
+
+	if numBands < 1 || fmin < 0 || fmax <= fmin || fmax > sampleRate/2 || fftSize < 2 {
+		return nil, fmt.Errorf("%w: numBands %d, fmin %f, fmax %f, sampleRate %f, fftSize %d",
+			ErrArgument, numBands, fmin, fmax, sampleRate, fftSize)
+	}
+	// The edges of the bands: band i spans edges[i], edges[i+1], edges[i+2]
+	smin, smax := toScale(fmin), toScale(fmax)
+	edges := make([]float64, numBands+2)
+	for i := range edges {
+		edges[i] = fromScale(smin + float64(i)*(smax-smin)/float64(numBands+1))
+	}
+	edges[0], edges[numBands+1] = fmin, fmax
+	fb := &Filterbank{
+		bands:   make([]band, numBands),
+		centres: edges[1 : numBands+1],
+		numBins: fftSize/2 + 1,
+	}
+	binWidth := sampleRate / float64(fftSize)
+	for i := range fb.bands {
+		lo, c, hi := edges[i], edges[i+1], edges[i+2]
+		b := &fb.bands[i]
+		b.start = int(math.Ceil(lo / binWidth))
+		for k := b.start; k < fb.numBins && float64(k)*binWidth <= hi; k++ {
+			f := float64(k) * binWidth
+			w := 0.0
+			if f <= c {
+				w = (f - lo) / (c - lo)
+			} else {
+				w = (hi - f) / (hi - c)
+			}
+			b.weights = append(b.weights, math.Max(w, 0))
+		}
+	}
+	return fb, nil
+}
+
+// NumBands returns the number of bands of fb
+func (fb *Filterbank) NumBands() int {
+	return len(fb.bands)
+}
+
+// CentreFrequencies returns the centre frequencies of the bands of fb in Hz
+func (fb *Filterbank) CentreFrequencies() []float64 {
+	return append([]float64{}, fb.centres...)
+}
+
+/*
+Weights returns the dense weights of the bins of band i: a vector of length
+fftSize/2+1.
+*/
+func (fb *Filterbank) Weights(i int) []float64 {
+	w := make([]float64, fb.numBins)
+	copy(w[fb.bands[i].start:], fb.bands[i].weights)
+	return w
+}
+
+/*
+Apply returns the band energies of the frames of the magnitude or power
+spectrogram S: Y[i][j] is the weighted sum of the bins of band j of frame S[i].
+The function panics if a frame does not have fftSize/2+1 bins.
+*/
+func (fb *Filterbank) Apply(S [][]float64) [][]float64 {
+	Y, err := fb.ApplyErr(S)
+	if err != nil {
+		panic(err)
+	}
+	return Y
+}
+
+/*
+ApplyErr returns fb.Apply(S) or an error wrapping ErrLength if a frame does not
+have fftSize/2+1 bins.
+*/
+func (fb *Filterbank) ApplyErr(S [][]float64) ([][]float64, error) {
+	Y := make([][]float64, len(S))
+	for i, frame := range S {
+		if len(frame) != fb.numBins {
+			return nil, fmt.Errorf("%w: frame %d has %d bins, want %d", ErrLength, i, len(frame), fb.numBins)
+		}
+		Y[i] = make([]float64, len(fb.bands))
+		for j, b := range fb.bands {
+			Y[i][j] = Dot(frame[b.start:b.start+len(b.weights)], b.weights)
+		}
+	}
+	return Y, nil
+}
@@ -0,0 +1,240 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+)
+
+// FramePadding selects how Frames treats the ends of a signal
+type FramePadding int
+
+const (
+	// NoFramePadding returns only the frames that lie within x: a tail of x that does not fill a frame is dropped
+	NoFramePadding FramePadding = iota
+	// EndFramePadding pads the end of x with zeros so that the last frame contains the last sample of x
+	EndFramePadding
+	/*
+		CenterFramePadding centres frame i on sample i*hop by padding both ends
+		of x with frameLen/2 zeros. The last frame contains the last sample of x.
+	*/
+	CenterFramePadding
+)
+
+// FrameOption configures Frames
+type FrameOption func(*FrameIterator)
+
+/*
+WithFramePadding selects how the ends of the signal are padded. The default is
+NoFramePadding.
+*/
+func WithFramePadding(p FramePadding) FrameOption {
+	return func(it *FrameIterator) {
+		it.padding = p
+	}
+}
+
+/*
+WithZeroCopy makes Frame return sub-slices of the signal for the frames that
+need no padding instead of copies. The frames must not be modified unless the
+signal may be modified.
+*/
+func WithZeroCopy() FrameOption {
+	return func(it *FrameIterator) {
+		it.zeroCopy = true
+	}
+}
+
+/*
+FrameIterator iterates over the frames of a signal. Frame i starts at sample
+i*hop - offset of the signal and has length frameLen, where offset is
+frameLen/2 for CenterFramePadding and 0 otherwise. Samples outside the signal
+are zero. Typical use:
+
+	it := Frames(x, frameLen, hop)
+	for it.Next() {
+		frame := it.Frame()
+		...
+	}
+*/
+type FrameIterator struct {
+	x                    []float64
+	r                    SampleReader
+	n                    int
+	err                  error
+	frameLen, hop        int
+	padding              FramePadding
+	zeroCopy             bool
+	offset, numFrames, i int
+	buf                  []float64
+}
+
+/*
+Frames returns an iterator over the frames of length frameLen of x, which
+start hop samples apart.
+The function panics if frameLen < 1 or hop < 1.
+*/
+func Frames(x []float64, frameLen, hop int, opts ...FrameOption) *FrameIterator {
+	it, err := FramesErr(x, frameLen, hop, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return it
+}
+
+/*
+FramesErr returns Frames(x, frameLen, hop, opts...) or an error wrapping
+ErrArgument if frameLen < 1 or hop < 1.
+*/
+func FramesErr(x []float64, frameLen, hop int, opts ...FrameOption) (*FrameIterator, error) {
+	return newFrameIterator(&FrameIterator{x: x, n: len(x)}, frameLen, hop, opts)
+}
+
+/*
+FramesFrom returns an iterator over the frames of the samples of r, as Frames.
+Only the current frame is in memory, so r may be larger than memory. The frame
+is read from r by Next, which returns false if r returns an error. See Err.
+The frames are copies, even if the iterator is zero-copy.
+The function panics if frameLen < 1 or hop < 1.
+*/
+func FramesFrom(r SampleReader, frameLen, hop int, opts ...FrameOption) *FrameIterator {
+	it, err := FramesFromErr(r, frameLen, hop, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return it
+}
+
+/*
+FramesFromErr returns FramesFrom(r, frameLen, hop, opts...) or an error
+wrapping ErrArgument if frameLen < 1 or hop < 1.
+*/
+func FramesFromErr(r SampleReader, frameLen, hop int, opts ...FrameOption) (*FrameIterator, error) {
+	return newFrameIterator(&FrameIterator{r: r, n: r.Len()}, frameLen, hop, opts)
+}
+
+// newFrameIterator initialises the iterator it of a signal of it.n samples
+func newFrameIterator(it *FrameIterator, frameLen, hop int, opts []FrameOption) (*FrameIterator, error) {
+	if err := checkFrames(frameLen, hop, 1); err != nil {
+		return nil, err
+	}
+	it.frameLen, it.hop, it.i = frameLen, hop, -1
+	it.buf = make([]float64, frameLen)
+	for _, opt := range opts {
+		opt(it)
+	}
+	switch it.padding {
+	case NoFramePadding:
+		if it.n >= frameLen {
+			it.numFrames = 1 + (it.n-frameLen)/hop
+		}
+	case EndFramePadding:
+		if it.n > 0 {
+			it.numFrames = 1 + (it.n-1)/hop
+		}
+	case CenterFramePadding:
+		it.offset = frameLen / 2
+		if it.n > 0 {
+			it.numFrames = 1 + (it.n-1)/hop
+		}
+	default:
+		return nil, fmt.Errorf("%w: padding %d", ErrArgument, it.padding)
+	}
+	return it, nil
+}
+
+// Len returns the number of frames
+func (it *FrameIterator) Len() int {
+	return it.numFrames
+}
+
+/*
+Next advances the iterator to the next frame and returns false after the last
+frame or if reading the frame from the SampleReader of FramesFrom fails.
+*/
+func (it *FrameIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.i < it.numFrames {
+		it.i++
+	}
+	if it.i < it.numFrames && it.r != nil {
+		it.err = it.read()
+	}
+	return it.err == nil && it.i < it.numFrames
+}
+
+/*
+Err returns the error reading the SampleReader of FramesFrom, or nil if there
+was no error.
+*/
+func (it *FrameIterator) Err() error {
+	return it.err
+}
+
+// read reads the current frame from it.r to it.buf
+func (it *FrameIterator) read() error {
+	for j := range it.buf {
+		it.buf[j] = 0
+	}
+	start, end := it.Start(), it.Start()+it.frameLen
+	if end > it.n {
+		end = it.n
+	}
+	skip := 0
+	if start < 0 {
+		skip = -start
+	}
+	if start+skip >= end {
+		return nil
+	}
+	_, err := it.r.ReadSamplesAt(it.buf[skip:end-start], start+skip)
+	return err
+}
+
+// Index returns the index of the current frame
+func (it *FrameIterator) Index() int {
+	return it.i
+}
+
+/*
+Start returns the index in the signal of the first sample of the current
+frame. It is negative for the first frames of CenterFramePadding.
+*/
+func (it *FrameIterator) Start() int {
+	return it.i*it.hop - it.offset
+}
+
+/*
+Frame returns the current frame. Unless the iterator is zero-copy, the frame is
+a buffer that may be modified and is overwritten by the next call of Frame.
+*/
+func (it *FrameIterator) Frame() []float64 {
+	if it.r != nil {
+		return it.buf
+	}
+	start := it.Start()
+	if it.zeroCopy && start >= 0 && start+it.frameLen <= len(it.x) {
+		return it.x[start : start+it.frameLen]
+	}
+	for j := range it.buf {
+		it.buf[j] = 0
+		if k := start + j; k >= 0 && k < len(it.x) {
+			it.buf[j] = it.x[k]
+		}
+	}
+	return it.buf
+}
@@ -0,0 +1,166 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package gen generates test and calibration signals. The length of each signal
+is int(duration*sampleRate) samples. Frequencies are in Hz, durations in
+seconds and phases in radians.
+*/
+package gen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ChirpMode determines how the frequency of a chirp changes with time
+type ChirpMode int
+
+const (
+	// Linear chirps change frequency linearly with time
+	Linear ChirpMode = iota
+	// Logarithmic chirps change frequency exponentially with time
+	Logarithmic
+)
+
+/*
+Sine returns amplitude*sin(2*pi*freq*t + phase).
+*/
+func Sine(freq, sampleRate, duration, amplitude, phase float64) []float64 {
+	return periodic(freq, sampleRate, duration, amplitude, phase, math.Sin)
+}
+
+/*
+Chirp returns a sine wave whose frequency sweeps from f0 at t=0 to f1 at
+t=duration. The function panics if mode is Logarithmic and f0 or f1 <= 0.
+*/
+func Chirp(f0, f1, sampleRate, duration, amplitude, phase float64, mode ChirpMode) []float64 {
+	x := make([]float64, length(sampleRate, duration))
+	switch mode {
+	case Linear:
+		k := (f1 - f0) / duration
+		for i := range x {
+			t := float64(i) / sampleRate
+			x[i] = amplitude * math.Sin(2*math.Pi*(f0*t+k*t*t/2)+phase)
+		}
+	case Logarithmic:
+		if f0 <= 0 || f1 <= 0 {
+			panic(fmt.Sprintf("Invalid logarithmic chirp frequencies %f, %f", f0, f1))
+		}
+		if f0 == f1 {
+			return Sine(f0, sampleRate, duration, amplitude, phase)
+		}
+		k := math.Log(f1/f0) / duration
+		for i := range x {
+			t := float64(i) / sampleRate
+			x[i] = amplitude * math.Sin(2*math.Pi*f0*(math.Exp(k*t)-1)/k+phase)
+		}
+	default:
+		panic(fmt.Sprintf("Invalid chirp mode %d", mode))
+	}
+	return x
+}
+
+/*
+Square returns a square wave of frequency freq switching between +amplitude
+and -amplitude. It has the sign of the sine wave with the same frequency and
+phase.
+*/
+func Square(freq, sampleRate, duration, amplitude, phase float64) []float64 {
+	return periodic(freq, sampleRate, duration, amplitude, phase, func(ph float64) float64 {
+		if cycle(ph) < 0.5 {
+			return 1
+		}
+		return -1
+	})
+}
+
+/*
+Sawtooth returns a sawtooth wave of frequency freq rising from -amplitude to
++amplitude in each period. At phase 0 the wave starts at 0.
+*/
+func Sawtooth(freq, sampleRate, duration, amplitude, phase float64) []float64 {
+	return periodic(freq, sampleRate, duration, amplitude, phase, func(ph float64) float64 {
+		return 2*cycle(ph+math.Pi) - 1
+	})
+}
+
+/*
+WhiteNoise returns uniformly distributed white noise in [-amplitude, amplitude).
+seed seeds the random number generator so that the noise is reproducible.
+*/
+func WhiteNoise(sampleRate, duration, amplitude float64, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	x := make([]float64, length(sampleRate, duration))
+	for i := range x {
+		x[i] = amplitude * (2*rnd.Float64() - 1)
+	}
+	return x
+}
+
+/*
+PinkNoise returns noise with a power spectral density proportional to 1/f,
+scaled so that its peak absolute value is amplitude. The white noise generated
+from seed is filtered with Paul Kellet's refined pinking filter, which is
+accurate to within 0.05 dB above 9.2 Hz at a 44.1 kHz sample rate.
+*/
+func PinkNoise(sampleRate, duration, amplitude float64, seed int64) []float64 {
+	x := WhiteNoise(sampleRate, duration, 1, seed)
+	var b0, b1, b2, b3, b4, b5, b6 float64
+	peak := 0.0
+	for i, w := range x {
+		b0 = 0.99886*b0 + w*0.0555179
+		b1 = 0.99332*b1 + w*0.0750759
+		b2 = 0.96900*b2 + w*0.1538520
+		b3 = 0.86650*b3 + w*0.3104856
+		b4 = 0.55000*b4 + w*0.5329522
+		b5 = -0.7616*b5 - w*0.0168980
+		x[i] = b0 + b1 + b2 + b3 + b4 + b5 + b6 + w*0.5362
+		b6 = w * 0.115926
+		peak = math.Max(peak, math.Abs(x[i]))
+	}
+	if peak > 0 {
+		for i := range x {
+			x[i] *= amplitude / peak
+		}
+	}
+	return x
+}
+
+// periodic returns amplitude*wave(2*pi*freq*t + phase)
+func periodic(freq, sampleRate, duration, amplitude, phase float64, wave func(float64) float64) []float64 {
+	x := make([]float64, length(sampleRate, duration))
+	for i := range x {
+		x[i] = amplitude * wave(2*math.Pi*freq*float64(i)/sampleRate+phase)
+	}
+	return x
+}
+
+// cycle returns the fraction of the period of phase ph in [0, 1)
+func cycle(ph float64) float64 {
+	f := math.Mod(ph/(2*math.Pi), 1)
+	if f < 0 {
+		f++
+	}
+	return f
+}
+
+// length returns the number of samples of a signal. It panics if sampleRate <= 0 or duration < 0.
+func length(sampleRate, duration float64) int {
+	if sampleRate <= 0 || duration < 0 {
+		panic(fmt.Sprintf("Invalid sampleRate %f or duration %f", sampleRate, duration))
+	}
+	return int(duration * sampleRate)
+}
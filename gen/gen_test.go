@@ -0,0 +1,60 @@
+package gen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goccmack/godsp"
+)
+
+func TestGen(t *testing.T) {
+	x := Sine(1000, 8000, 1, 2, math.Pi/2)
+	if len(x) != 8000 || x[0] != 2 || math.Abs(x[4]+2) > 1e-12 {
+		t.Errorf("Sine: len %d, x[0] %f, x[4] %f", len(x), x[0], x[4])
+	}
+	sq := Square(1000, 8000, 0.001, 1, 0)
+	if want := []float64{1, 1, 1, 1, -1, -1, -1, -1}; !equal(sq, want) {
+		t.Errorf("Square = %v", sq)
+	}
+	saw := Sawtooth(1000, 8000, 0.001, 1, 0)
+	if want := []float64{0, 0.25, 0.5, 0.75, -1, -0.75, -0.5, -0.25}; !equal(saw, want) {
+		t.Errorf("Sawtooth = %v", saw)
+	}
+	for _, mode := range []ChirpMode{Linear, Logarithmic} {
+		c := Chirp(100, 1000, 8000, 1, 1, 0, mode)
+		z0, z1 := zeroCrossings(c[:800]), zeroCrossings(c[7200:])
+		if z0 >= z1 {
+			t.Errorf("Chirp %d: %d crossings at start, %d at end", mode, z0, z1)
+		}
+	}
+	w := WhiteNoise(8000, 1, 1, 1)
+	if m := godsp.Max(godsp.Abs(w)); m > 1 {
+		t.Errorf("WhiteNoise peak %f", m)
+	}
+	p := PinkNoise(8000, 1, 1, 1)
+	if m := godsp.Max(godsp.Abs(p)); math.Abs(m-1) > 1e-12 {
+		t.Errorf("PinkNoise peak %f", m)
+	}
+}
+
+func equal(x, y []float64) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if math.Abs(x[i]-y[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func zeroCrossings(x []float64) int {
+	n := 0
+	for i := 1; i < len(x); i++ {
+		if (x[i] < 0) != (x[i-1] < 0) {
+			n++
+		}
+	}
+	return n
+}
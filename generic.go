@@ -0,0 +1,37 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+/*
+Float is the constraint of the element type of the generic vector functions.
+float32 vectors are processed without conversion to float64, but sums are
+accumulated in float64.
+*/
+type Float interface {
+	~float32 | ~float64
+}
+
+/*
+Convert returns a copy of x with element type To, e.g.
+
+	Convert[float32](x)
+*/
+func Convert[To, From Float](x []From) []To {
+	y := make([]To, len(x))
+	for i, f := range x {
+		y[i] = To(f)
+	}
+	return y
+}
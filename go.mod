@@ -1,11 +1,5 @@
 module github.com/goccmack/godsp
 
-go 1.13
+go 1.18
 
-require (
-	github.com/go-audio/audio v1.0.0
-	github.com/goccmack/goutil v0.4.0
-	github.com/mjibson/go-dsp v0.0.0-20180508042940-11479a337f12
-	github.com/youpy/go-riff v0.0.0-20131220112943-557d78c11efb // indirect
-	github.com/youpy/go-wav v0.0.0-20160223082350-b63a9887d320
-)
+require github.com/goccmack/goutil v0.4.0
@@ -0,0 +1,44 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"math"
+)
+
+/*
+Goertzel returns the power |X(f)|^2 of x at targetFreq, computed by the
+Goertzel algorithm in O(len(x)). X(f) is the DFT of x evaluated at
+targetFreq, which need not be an integer multiple of sampleRate/len(x).
+*/
+func Goertzel(x []float64, targetFreq, sampleRate float64) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*targetFreq/sampleRate)
+	s1, s2 := 0.0, 0.0
+	for _, f := range x {
+		s1, s2 = f+coeff*s1-s2, s1
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
+
+/*
+GoertzelMulti returns the Goertzel power of x at each of freqs.
+*/
+func GoertzelMulti(x []float64, freqs []float64, sampleRate float64) []float64 {
+	p := make([]float64, len(freqs))
+	for i, f := range freqs {
+		p[i] = Goertzel(x, f, sampleRate)
+	}
+	return p
+}
@@ -0,0 +1,50 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"math/cmplx"
+)
+
+/*
+Hilbert returns the analytic signal of x, computed by FFT. The real part of the
+result is x and the imaginary part is the Hilbert transform of x.
+*/
+func Hilbert(x []float64) []complex128 {
+	N := len(x)
+	X := FFTReal(x)
+	for k := 1; k < N; k++ {
+		switch {
+		case 2*k < N:
+			X[k] *= 2
+		case 2*k > N:
+			X[k] = 0
+		}
+	}
+	return IFFT(X)
+}
+
+/*
+Envelope returns the amplitude envelope of x, which is the magnitude of the
+analytic signal of x.
+*/
+func Envelope(x []float64) []float64 {
+	a := Hilbert(x)
+	env := make([]float64, len(a))
+	for i, c := range a {
+		env[i] = cmplx.Abs(c)
+	}
+	return env
+}
@@ -0,0 +1,155 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Hist returns the histogram of x in nbins bins of equal width spanning
+[min(x), max(x)]: counts[i] is the number of elements of x in
+[edges[i], edges[i+1]). The last bin also contains max(x). If all elements of
+x are equal the bins span [x[0]-0.5, x[0]+0.5]. NaNs and infinities are
+ignored, so min(x) and max(x) are those of the finite elements.
+The counts can be clustered by dbscan.Histogram.
+The function panics if x has no finite elements or nbins < 1.
+*/
+func Hist[T Float](x []T, nbins int) (counts []int, edges []float64) {
+	counts, edges, err := HistErr(x, nbins)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+HistErr returns Hist(x, nbins) or an error wrapping ErrLength if x has no
+finite elements or ErrArgument if nbins < 1.
+*/
+func HistErr[T Float](x []T, nbins int) (counts []int, edges []float64, err error) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range x {
+		f := float64(v)
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			min, max = math.Min(min, f), math.Max(max, f)
+		}
+	}
+	if min > max {
+		return nil, nil, fmt.Errorf("%w: x has no finite values", ErrLength)
+	}
+	if min == max {
+		min, max = min-0.5, max+0.5
+	}
+	return HistRangeErr(x, min, max, nbins)
+}
+
+/*
+HistRange returns the histogram of x in nbins bins of equal width spanning
+[min, max]: counts[i] is the number of elements of x in [edges[i], edges[i+1]).
+The last bin also contains max. Elements outside [min, max] and NaNs are
+ignored.
+The function panics if nbins < 1 or min >= max.
+*/
+func HistRange[T Float](x []T, min, max float64, nbins int) (counts []int, edges []float64) {
+	counts, edges, err := HistRangeErr(x, min, max, nbins)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+HistRangeErr returns HistRange(x, min, max, nbins) or an error wrapping
+ErrArgument if nbins < 1 or min >= max.
+*/
+func HistRangeErr[T Float](x []T, min, max float64, nbins int) (counts []int, edges []float64, err error) {
+	if err = checkHistRange(min, max, nbins); err != nil {
+		return nil, nil, err
+	}
+	counts = make([]int, nbins)
+	for _, v := range x {
+		if i := histBin(float64(v), min, max, nbins); i >= 0 {
+			counts[i]++
+		}
+	}
+	return counts, histEdges(min, max, nbins), nil
+}
+
+/*
+HistWeighted returns the histogram of x in nbins bins of equal width spanning
+[min, max], where element x[i] contributes weights[i] to its bin:
+sums[j] is the sum of the weights of the elements of x in
+[edges[j], edges[j+1]). The last bin also contains max. Elements outside
+[min, max] and NaNs are ignored.
+The function panics if len(weights) != len(x), nbins < 1 or min >= max.
+*/
+func HistWeighted[T Float](x, weights []T, min, max float64, nbins int) (sums, edges []float64) {
+	sums, edges, err := HistWeightedErr(x, weights, min, max, nbins)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+/*
+HistWeightedErr returns HistWeighted(x, weights, min, max, nbins) or an error
+wrapping ErrLength if len(weights) != len(x) or ErrArgument if nbins < 1 or
+min >= max.
+*/
+func HistWeightedErr[T Float](x, weights []T, min, max float64, nbins int) (sums, edges []float64, err error) {
+	if len(weights) != len(x) {
+		return nil, nil, fmt.Errorf("%w: len(x) %d, len(weights) %d", ErrLength, len(x), len(weights))
+	}
+	if err = checkHistRange(min, max, nbins); err != nil {
+		return nil, nil, err
+	}
+	sums = make([]float64, nbins)
+	for i, v := range x {
+		if j := histBin(float64(v), min, max, nbins); j >= 0 {
+			sums[j] += float64(weights[i])
+		}
+	}
+	return sums, histEdges(min, max, nbins), nil
+}
+
+func checkHistRange(min, max float64, nbins int) error {
+	if nbins < 1 || !(min < max) {
+		return fmt.Errorf("%w: min %f, max %f, nbins %d", ErrArgument, min, max, nbins)
+	}
+	return nil
+}
+
+// histBin returns the bin of v or -1 if v is outside [min, max] or NaN
+func histBin(v, min, max float64, nbins int) int {
+	if !(v >= min && v <= max) {
+		return -1
+	}
+	i := int((v - min) / (max - min) * float64(nbins))
+	if i >= nbins {
+		i = nbins - 1
+	}
+	return i
+}
+
+func histEdges(min, max float64, nbins int) []float64 {
+	edges := make([]float64, nbins+1)
+	for i := range edges {
+		edges[i] = min + float64(i)*(max-min)/float64(nbins)
+	}
+	edges[nbins] = max
+	return edges
+}
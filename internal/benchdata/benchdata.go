@@ -0,0 +1,108 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package benchdata generates the test data of the benchmarks of godsp. The data
+of a call depends only on its arguments, so that the results of benchmarks run
+at different times are comparable.
+*/
+package benchdata
+
+import (
+	"math"
+	"math/rand"
+)
+
+// seed is the seed of the random number generators of all data
+const seed = 1
+
+// Sizes are the signal lengths of the benchmarks: about 1.5 s and 24 s of audio at 44.1 kHz
+var Sizes = []int{1 << 16, 1 << 20}
+
+/*
+Signal returns n samples of a sum of sines with white noise, resembling a
+recording of music with a peak amplitude of about 1.
+*/
+func Signal(n int) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	x := make([]float64, n)
+	for i := range x {
+		t := float64(i)
+		x[i] = 0.4*math.Sin(t/7) + 0.3*math.Sin(t/29) + 0.2*math.Sin(t/113) +
+			0.1*rnd.NormFloat64()
+	}
+	return x
+}
+
+/*
+Envelope returns n samples of a non-negative envelope with a decaying pulse
+of random height every period samples, resembling an onset envelope.
+*/
+func Envelope(n, period int) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	x := make([]float64, n)
+	height := 0.0
+	for i := range x {
+		if i%period == 0 {
+			height = 0.5 + rnd.Float64()
+		}
+		x[i] = height*math.Exp(-float64(i%period)/float64(period)*8) + 0.05*rnd.Float64()
+	}
+	return x
+}
+
+/*
+Histogram returns a histogram of nbins bins with k clusters of high counts on
+a background of low counts.
+*/
+func Histogram(nbins, k int) []int {
+	rnd := rand.New(rand.NewSource(seed))
+	h := make([]int, nbins)
+	for i := range h {
+		h[i] = rnd.Intn(2)
+	}
+	for c := 0; c < k; c++ {
+		centre := rnd.Intn(nbins)
+		for i := 0; i < 1000; i++ {
+			if b := centre + int(rnd.NormFloat64()*5); b >= 0 && b < nbins {
+				h[b]++
+			}
+		}
+	}
+	return h
+}
+
+/*
+Points returns n points of dim dimensions in k Gaussian clusters with standard
+deviation 1 and centres uniformly distributed in [0,100)^dim.
+*/
+func Points(n, dim, k int) [][]float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	centres := make([][]float64, k)
+	for c := range centres {
+		centres[c] = make([]float64, dim)
+		for d := range centres[c] {
+			centres[c][d] = 100 * rnd.Float64()
+		}
+	}
+	points := make([][]float64, n)
+	for i := range points {
+		centre := centres[i%k]
+		points[i] = make([]float64, dim)
+		for d := range points[i] {
+			points[i][d] = centre[d] + rnd.NormFloat64()
+		}
+	}
+	return points
+}
@@ -0,0 +1,157 @@
+#!/usr/bin/env python3
+#  Copyright 2019 Marius Ackerman
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+"""
+Writes the golden files of the tests of godsp. See package golden.
+
+The reference outputs are the outputs of SciPy and PyWavelets, unchanged:
+
+  - FFT: scipy.fft.fft.
+  - Wavelets: pywt.wavedec with mode='periodization'. The tests map the
+    conventions of godsp to those of PyWavelets.
+  - Biquads: scipy.signal.lfilter with the coefficients of the audio EQ
+    cookbook of R. Bristow-Johnson.
+  - Butterworth and Chebyshev type I filters: scipy.signal.sosfreqz of
+    scipy.signal.butter and scipy.signal.cheby1.
+
+The versions of the libraries are recorded in the golden files. Run from this
+directory:
+
+    $ pip install numpy scipy PyWavelets
+    $ python3 generate.py
+"""
+
+import json
+import math
+import os
+import platform
+
+import numpy as np
+import pywt
+import scipy
+import scipy.fft
+import scipy.signal
+
+ROOT = os.path.join(os.path.dirname(os.path.abspath(__file__)), "..", "..")
+GENERATOR = "internal/golden/generate.py"
+VERSIONS = {
+    "python": platform.python_version(),
+    "numpy": np.__version__,
+    "scipy": scipy.__version__,
+    "pywt": pywt.__version__,
+}
+
+
+def signal(n):
+    """A deterministic test signal of n samples."""
+    i = np.arange(n)
+    return np.sin(0.3 * i) + 0.5 * np.cos(1.7 * i) + 0.01 * (i % 5)
+
+
+def write(path, method, cases):
+    path = os.path.join(ROOT, path)
+    os.makedirs(os.path.dirname(path), exist_ok=True)
+    with open(path, "w") as f:
+        json.dump({"generator": GENERATOR + ": " + method, "versions": VERSIONS, "cases": cases},
+                  f, indent=1)
+        f.write("\n")
+
+
+def case(name, params, x, want, tolerance, want_imag=None):
+    c = {"name": name, "params": params, "input": [float(v) for v in x],
+         "want": [float(v) for v in want], "tolerance": tolerance}
+    if want_imag is not None:
+        c["wantImag"] = [float(v) for v in want_imag]
+    return c
+
+
+def fft_cases():
+    cases = []
+    for n in [8, 64, 12, 100]:
+        x = signal(n)
+        X = scipy.fft.fft(x)
+        cases.append(case("FFT N=%d" % n, {"N": n}, x, X.real, 1e-9, X.imag))
+    return cases
+
+
+def wavelet_cases():
+    cases = []
+    for name in ["db1", "db2", "db4", "db6", "sym4", "sym5", "coif1", "coif2", "coif3"]:
+        for n, level in [(32, 1), (256, 3)]:
+            x = signal(n)
+            coeffs = pywt.wavedec(x, name, mode="periodization", level=level)
+            cases.append(case("%s level %d" % (name, level), {"level": level}, x,
+                              np.concatenate(coeffs), 1e-10))
+    return cases
+
+
+def rbj(f0, fs, q):
+    w0 = 2 * math.pi * f0 / fs
+    return math.cos(w0), math.sin(w0) / (2 * q)
+
+
+def biquad_lowpass(f0, fs, q):
+    c, a = rbj(f0, fs, q)
+    return [(1 - c) / 2, 1 - c, (1 - c) / 2], [1 + a, -2 * c, 1 - a]
+
+
+def biquad_peaking(f0, fs, q, gain_db):
+    c, a = rbj(f0, fs, q)
+    A = 10 ** (gain_db / 40)
+    return [1 + a * A, -2 * c, 1 - a * A], [1 + a / A, -2 * c, 1 - a / A]
+
+
+def impulse_response(b, a, n):
+    x = np.zeros(n)
+    x[0] = 1
+    return scipy.signal.lfilter(b, a, x)
+
+
+def magnitude_response(sos, freqs, fs):
+    _, h = scipy.signal.sosfreqz(sos, worN=freqs, fs=fs)
+    return np.abs(h)
+
+
+def filter_cases():
+    cases = []
+    fs = 44100
+    b, a = biquad_lowpass(1000, fs, 1 / math.sqrt(2))
+    cases.append(case("BiquadLowpass impulse response", {"f0": 1000, "sampleRate": fs, "Q": 1 / math.sqrt(2)},
+                      [], impulse_response(b, a, 64), 1e-12))
+    b, a = biquad_peaking(3000, fs, 2, 6)
+    cases.append(case("BiquadPeaking impulse response",
+                      {"f0": 3000, "sampleRate": fs, "Q": 2, "gainDb": 6},
+                      [], impulse_response(b, a, 64), 1e-12))
+    fs = 8000
+    freqs = np.arange(64) * fs / 128
+    for name, order, fc, btype in [("ButterworthLowpass", 4, 1000, "lowpass"),
+                                   ("ButterworthHighpass", 3, 1500, "highpass")]:
+        sos = scipy.signal.butter(order, fc, btype, fs=fs, output="sos")
+        cases.append(case("%s magnitude response" % name, {"order": order, "cutoff": fc, "sampleRate": fs},
+                          freqs, magnitude_response(sos, freqs, fs), 1e-6))
+    order, ripple, fc = 5, 1, 1000
+    sos = scipy.signal.cheby1(order, ripple, fc, "lowpass", fs=fs, output="sos")
+    cases.append(case("Chebyshev1Lowpass magnitude response",
+                      {"order": order, "rippleDb": ripple, "cutoff": fc, "sampleRate": fs},
+                      freqs, magnitude_response(sos, freqs, fs), 1e-6))
+    return cases
+
+
+if __name__ == "__main__":
+    write("testdata/fft.json", "scipy.fft.fft", fft_cases())
+    write("dwt/testdata/wavelets.json", "pywt.wavedec with mode='periodization'", wavelet_cases())
+    write("filter/testdata/filters.json",
+          "scipy.signal.lfilter of cookbook biquads and scipy.signal.sosfreqz of butter and cheby1",
+          filter_cases())
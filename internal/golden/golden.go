@@ -0,0 +1,151 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package golden loads the golden files of the tests of godsp and compares the
+outputs of the package under test with their reference outputs.
+
+A golden file is a JSON file in the testdata directory of a package. It holds
+test cases with the input, parameters and reference output of a function,
+computed by a trusted library that is independent of godsp, and the versions
+of the libraries:
+
+	{
+	  "generator": "the program and method that computed the reference outputs",
+	  "versions": {"scipy": "1.11.4", "pywt": "1.5.0"},
+	  "cases": [
+	    {
+	      "name": "FFT N=12",
+	      "params": {"N": 12},
+	      "input": [...],
+	      "want": [...],
+	      "wantImag": [...],
+	      "tolerance": 1e-9
+	    }
+	  ]
+	}
+
+The golden files of godsp are written by generate.py in this directory from
+the outputs of SciPy and PyWavelets. The reference outputs are not adapted to
+the conventions of godsp. A test that differs in convention, such as the sign
+or delay of wavelet coefficients, maps its outputs to those of the library.
+*/
+package golden
+
+//go:generate python3 generate.py
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+
+// File is a golden file
+type File struct {
+	// Generator describes the program and method that computed the reference outputs
+	Generator string `json:"generator"`
+	// Versions are the versions of the libraries that computed the reference outputs
+	Versions map[string]string `json:"versions"`
+	Cases    []*Case           `json:"cases"`
+}
+
+// Case is a test case of a golden file
+type Case struct {
+	Name   string             `json:"name"`
+	Params map[string]float64 `json:"params"`
+	Input  []float64          `json:"input"`
+	// Want is the reference output, or its real part if the output is complex
+	Want []float64 `json:"want"`
+	// WantImag is the imaginary part of a complex reference output
+	WantImag []float64 `json:"wantImag,omitempty"`
+	/*
+		Tolerance is the maximum error of an output value v relative to
+		max(1,|v|).
+	*/
+	Tolerance float64 `json:"tolerance"`
+}
+
+/*
+Load returns the golden file fname. The test fails immediately if the file
+does not exist, cannot be read or parsed, or does not record the versions of
+the libraries that generated it.
+*/
+func Load(t testing.TB, fname string) *File {
+	t.Helper()
+	b, err := ioutil.ReadFile(fname)
+	if os.IsNotExist(err) {
+		t.Fatalf("%s does not exist: run go generate ./internal/golden", fname)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := new(File)
+	if err := json.Unmarshal(b, f); err != nil {
+		t.Fatalf("%s: %s", fname, err)
+	}
+	if len(f.Versions) == 0 {
+		t.Fatalf("%s: no library versions", fname)
+	}
+	return f
+}
+
+/*
+Param returns the parameter name of c. The test fails immediately if c has no
+such parameter.
+*/
+func (c *Case) Param(t testing.TB, name string) float64 {
+	t.Helper()
+	v, ok := c.Params[name]
+	if !ok {
+		t.Fatalf("%s: no parameter %q", c.Name, name)
+	}
+	return v
+}
+
+// Check reports an error if got differs from c.Want by more than c.Tolerance
+func (c *Case) Check(t testing.TB, got []float64) {
+	t.Helper()
+	c.check(t, "", got, c.Want)
+}
+
+/*
+CheckComplex reports an error if the real or imaginary parts of got differ
+from c.Want or c.WantImag by more than c.Tolerance.
+*/
+func (c *Case) CheckComplex(t testing.TB, got []complex128) {
+	t.Helper()
+	re, im := make([]float64, len(got)), make([]float64, len(got))
+	for i, v := range got {
+		re[i], im[i] = real(v), imag(v)
+	}
+	c.check(t, "real ", re, c.Want)
+	c.check(t, "imag ", im, c.WantImag)
+}
+
+// check reports the first value of got that differs from want
+func (c *Case) check(t testing.TB, part string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: %slen(got) = %d, want %d", c.Name, part, len(got), len(want))
+		return
+	}
+	for i, v := range want {
+		if math.Abs(got[i]-v) > c.Tolerance*math.Max(1, math.Abs(v)) {
+			t.Errorf("%s: %sgot[%d] = %g, want %g", c.Name, part, i, got[i], v)
+			return
+		}
+	}
+}
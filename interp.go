@@ -0,0 +1,138 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Interpolator returns the values of x at the fractional sample positions t.
+Positions outside [0,len(x)-1] are clamped to the first or last sample.
+*/
+type Interpolator func(x, t []float64) []float64
+
+// sincHalfWidth is the number of samples on each side used by InterpSinc
+const sincHalfWidth = 16
+
+/*
+InterpTo returns x resampled to length n on an evenly spaced grid from the
+first to the last sample of x by interp.
+The function panics if x is empty or n < 1.
+*/
+func InterpTo(x []float64, n int, interp Interpolator) []float64 {
+	y, err := InterpToErr(x, n, interp)
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
+
+/*
+InterpToErr returns InterpTo(x, n, interp) or an error wrapping ErrArgument if
+x is empty or n < 1.
+*/
+func InterpToErr(x []float64, n int, interp Interpolator) ([]float64, error) {
+	if len(x) == 0 || n < 1 {
+		return nil, fmt.Errorf("%w: Invalid len(x) %d or n %d", ErrArgument, len(x), n)
+	}
+	t := make([]float64, n)
+	if n > 1 {
+		step := float64(len(x)-1) / float64(n-1)
+		for i := range t {
+			t[i] = float64(i) * step
+		}
+	}
+	return interp(x, t), nil
+}
+
+// InterpLinear is a linear Interpolator
+func InterpLinear(x, t []float64) []float64 {
+	y := make([]float64, len(t))
+	for i, ti := range t {
+		j, f := interpIndex(ti, len(x))
+		if f == 0 {
+			y[i] = x[j]
+		} else {
+			y[i] = x[j] + f*(x[j+1]-x[j])
+		}
+	}
+	return y
+}
+
+/*
+InterpCubic is a cubic convolution (Catmull-Rom) Interpolator. The samples
+beyond the ends of x are taken to be equal to the end samples.
+*/
+func InterpCubic(x, t []float64) []float64 {
+	at := func(i int) float64 {
+		if i < 0 {
+			return x[0]
+		}
+		if i >= len(x) {
+			return x[len(x)-1]
+		}
+		return x[i]
+	}
+	y := make([]float64, len(t))
+	for i, ti := range t {
+		j, f := interpIndex(ti, len(x))
+		p0, p1, p2, p3 := at(j-1), at(j), at(j+1), at(j+2)
+		y[i] = p1 + 0.5*f*(p2-p0+f*(2*p0-5*p1+4*p2-p3+f*(3*(p1-p2)+p3-p0)))
+	}
+	return y
+}
+
+/*
+InterpSinc is a band limited Interpolator using a Hann windowed sinc kernel
+of 2*16 samples.
+*/
+func InterpSinc(x, t []float64) []float64 {
+	y := make([]float64, len(t))
+	for i, ti := range t {
+		j, f := interpIndex(ti, len(x))
+		if f == 0 {
+			y[i] = x[j]
+			continue
+		}
+		sum := 0.0
+		for k := j - sincHalfWidth + 1; k <= j+sincHalfWidth; k++ {
+			if k < 0 || k >= len(x) {
+				continue
+			}
+			d := float64(k-j) - f
+			w := 0.5 * (1 + math.Cos(math.Pi*d/sincHalfWidth))
+			sum += x[k] * math.Sin(math.Pi*d) / (math.Pi * d) * w
+		}
+		y[i] = sum
+	}
+	return y
+}
+
+/*
+interpIndex returns the integer part j and fraction f of position t clamped to
+[0,n-1].
+*/
+func interpIndex(t float64, n int) (j int, f float64) {
+	if t <= 0 {
+		return 0, 0
+	}
+	if t >= float64(n-1) {
+		return n - 1, 0
+	}
+	j = int(t)
+	return j, t - float64(j)
+}
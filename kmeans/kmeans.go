@@ -0,0 +1,236 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package kmeans implements k-means clustering of points by Lloyd's algorithm
+with k-means++ or random seeding, for grouping data into a known number of
+clusters. See package dbscan for clustering when the number of clusters is
+not known.
+
+See: D. Arthur and S. Vassilvitskii. k-means++: The Advantages of Careful
+Seeding. SODA 2007.
+*/
+package kmeans
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Init selects the initial centroids of KMeans
+type Init int
+
+const (
+	// PlusPlus selects the initial centroids by k-means++ seeding
+	PlusPlus Init = iota
+	// Random selects k distinct points as the initial centroids
+	Random
+)
+
+// Option configures KMeans
+type Option func(*options)
+
+type options struct {
+	init          Init
+	maxIterations int
+	seed          int64
+}
+
+func getOptions(opts []Option) *options {
+	o := &options{
+		init:          PlusPlus,
+		maxIterations: 300,
+		seed:          1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithInit selects the initial centroids. The default is PlusPlus.
+func WithInit(init Init) Option {
+	return func(o *options) {
+		o.init = init
+	}
+}
+
+/*
+WithMaxIterations sets the maximum number of iterations of Lloyd's algorithm.
+The default is 300. The function panics if n < 1.
+*/
+func WithMaxIterations(n int) Option {
+	if n < 1 {
+		panic(fmt.Sprintf("Invalid number of iterations %d", n))
+	}
+	return func(o *options) {
+		o.maxIterations = n
+	}
+}
+
+/*
+WithSeed sets the seed of the random selection of the initial centroids. The
+default seed is 1, so that the result of KMeans is reproducible.
+*/
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+/*
+KMeans clusters points, which have equal dimensions, into k clusters by
+minimising the sum of the squared Euclidean distances of the points to the
+centroids of their clusters. It returns the cluster 0..k-1 of each point and
+the k centroids. The iterations stop when no point changes its cluster. A
+cluster that becomes empty is moved to the point furthest from its centroid.
+The function panics if k < 1 or k > len(points).
+*/
+func KMeans(points [][]float64, k int, opts ...Option) (labels []int, centroids [][]float64) {
+	if k < 1 || k > len(points) {
+		panic(fmt.Sprintf("Invalid k %d for %d points", k, len(points)))
+	}
+	o := getOptions(opts)
+	rnd := rand.New(rand.NewSource(o.seed))
+	if o.init == Random {
+		centroids = randomInit(points, k, rnd)
+	} else {
+		centroids = plusPlusInit(points, k, rnd)
+	}
+	labels = make([]int, len(points))
+	for i := range labels {
+		labels[i] = -1
+	}
+	dim := len(points[0])
+	for it := 0; it < o.maxIterations; it++ {
+		changed := false
+		for i, p := range points {
+			if c, _ := nearest(p, centroids); c != labels[i] {
+				labels[i], changed = c, true
+			}
+		}
+		if !changed {
+			break
+		}
+		counts := make([]int, k)
+		for c := range centroids {
+			centroids[c] = make([]float64, dim)
+		}
+		for i, p := range points {
+			counts[labels[i]]++
+			for d, f := range p {
+				centroids[labels[i]][d] += f
+			}
+		}
+		for c := range centroids {
+			for d := range centroids[c] {
+				if counts[c] > 0 {
+					centroids[c][d] /= float64(counts[c])
+				}
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				centroids[c] = append([]float64{}, points[furthest(points, labels, centroids)]...)
+			}
+		}
+	}
+	return labels, centroids
+}
+
+/*
+Inertia returns the sum of the squared Euclidean distances of the points to the
+centroids of their clusters.
+*/
+func Inertia(points [][]float64, labels []int, centroids [][]float64) float64 {
+	sum := 0.0
+	for i, p := range points {
+		sum += dist2(p, centroids[labels[i]])
+	}
+	return sum
+}
+
+func randomInit(points [][]float64, k int, rnd *rand.Rand) [][]float64 {
+	centroids := make([][]float64, k)
+	for c, i := range rnd.Perm(len(points))[:k] {
+		centroids[c] = append([]float64{}, points[i]...)
+	}
+	return centroids
+}
+
+/*
+plusPlusInit selects the first centroid at random and every next centroid from
+the points with probability proportional to their squared distance to the
+nearest centroid selected so far.
+*/
+func plusPlusInit(points [][]float64, k int, rnd *rand.Rand) [][]float64 {
+	centroids := [][]float64{append([]float64{}, points[rnd.Intn(len(points))]...)}
+	d2 := make([]float64, len(points))
+	for i, p := range points {
+		d2[i] = dist2(p, centroids[0])
+	}
+	for len(centroids) < k {
+		sum := 0.0
+		for _, d := range d2 {
+			sum += d
+		}
+		next := 0
+		if sum == 0 {
+			// All points coincide with centroids
+			next = rnd.Intn(len(points))
+		} else {
+			r := rnd.Float64() * sum
+			for next = 0; next < len(d2)-1 && r >= d2[next]; next++ {
+				r -= d2[next]
+			}
+		}
+		c := append([]float64{}, points[next]...)
+		centroids = append(centroids, c)
+		for i, p := range points {
+			d2[i] = math.Min(d2[i], dist2(p, c))
+		}
+	}
+	return centroids
+}
+
+// nearest returns the index of the centroid nearest to p and its squared distance
+func nearest(p []float64, centroids [][]float64) (int, float64) {
+	best, bestD := 0, math.Inf(1)
+	for c, q := range centroids {
+		if d := dist2(p, q); d < bestD {
+			best, bestD = c, d
+		}
+	}
+	return best, bestD
+}
+
+// furthest returns the index of the point furthest from the centroid of its cluster
+func furthest(points [][]float64, labels []int, centroids [][]float64) int {
+	best, bestD := 0, -1.0
+	for i, p := range points {
+		if d := dist2(p, centroids[labels[i]]); d > bestD {
+			best, bestD = i, d
+		}
+	}
+	return best
+}
+
+func dist2(x, y []float64) float64 {
+	d := 0.0
+	for i := range x {
+		d += (x[i] - y[i]) * (x[i] - y[i])
+	}
+	return d
+}
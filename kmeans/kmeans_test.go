@@ -0,0 +1,41 @@
+package kmeans
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKMeans(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	centres := [][]float64{{0, 0}, {10, 0}, {0, 10}}
+	points := [][]float64{}
+	for i := 0; i < 300; i++ {
+		c := centres[i%3]
+		points = append(points, []float64{c[0] + rnd.NormFloat64(), c[1] + rnd.NormFloat64()})
+	}
+	for _, seed := range []int64{1, 7} {
+		labels, centroids := KMeans(points, 3, WithSeed(seed))
+		for i, l := range labels {
+			if l != labels[i%3] {
+				t.Fatalf("seed %d: labels[%d] = %d, want %d", seed, i, l, labels[i%3])
+			}
+		}
+		for j, c := range centres {
+			got := centroids[labels[j]]
+			if math.Hypot(got[0]-c[0], got[1]-c[1]) > 0.3 {
+				t.Errorf("seed %d: centroid %v, want %v", seed, got, c)
+			}
+		}
+		if in := Inertia(points, labels, centroids); in > 2*300*1.2 {
+			t.Errorf("inertia = %f", in)
+		}
+	}
+	// Random seeding may converge to a local minimum
+	labels, _ := KMeans(points, 3, WithInit(Random), WithMaxIterations(5))
+	for i, l := range labels {
+		if l < 0 || l >= 3 {
+			t.Fatalf("labels[%d] = %d", i, l)
+		}
+	}
+}
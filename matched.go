@@ -0,0 +1,64 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"math"
+)
+
+/*
+MatchedFilter returns the normalized cross correlation of template with each
+window of x of len(template) samples:
+
+	c[n] = sum_k (t[k]-avg(t)) (x[n+k]-avg(x[n:n+L])) / sqrt(Et*Ex[n])
+
+where L = len(template), and Et and Ex[n] are the energies of the template and
+the window of x with their averages removed. c has len(x)-L+1 elements in
+[-1,1]; c[n] is 1 where x[n:n+L] is a positive scaled and offset copy of the
+template. Windows and templates with zero energy have c[n] = 0. The
+correlation is computed by FFT convolution. See peaks.DetectEvents.
+*/
+func MatchedFilter(x, template []float64) []float64 {
+	L := len(template)
+	if L == 0 || len(x) < L {
+		return []float64{}
+	}
+	t0 := Detrend(template, Constant)
+	tNorm := math.Sqrt(Dot(t0, t0))
+	c := make([]float64, len(x)-L+1)
+	if tNorm == 0 {
+		return c
+	}
+	rev := make([]float64, L)
+	for k, f := range t0 {
+		rev[L-1-k] = f
+	}
+	num := Conv(x, rev)[L-1 : len(x)]
+	// Running sums of x and x^2 give the energy of each window
+	sum, sumSq := 0.0, 0.0
+	for _, f := range x[:L] {
+		sum, sumSq = sum+f, sumSq+f*f
+	}
+	for n := range c {
+		if n > 0 {
+			out, in := x[n-1], x[n+L-1]
+			sum, sumSq = sum-out+in, sumSq-out*out+in*in
+		}
+		if e := sumSq - sum*sum/float64(L); e > 1e-12*sumSq && e > 0 {
+			c[n] = num[n] / (tNorm * math.Sqrt(e))
+		}
+	}
+	return c
+}
@@ -0,0 +1,31 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package godsp
+
+import (
+	"io/ioutil"
+)
+
+// mmapFile reads the file fname into memory on platforms without mmap
+func mmapFile(fname string) ([]byte, error) {
+	return ioutil.ReadFile(fname)
+}
+
+// munmap releases a mapping of mmapFile
+func munmap(b []byte) error {
+	return nil
+}
@@ -0,0 +1,488 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+/*
+This file reads and writes NumPy .npy and .npz files. Vectors are saved as
+1-D float64 arrays and matrices, e.g. channels, as 2-D float64 arrays with one
+row per vector. The loaders accept little and big endian float32, float64 and
+integer arrays in C or Fortran order, which are converted to float64.
+*/
+
+const npyMagic = "\x93NUMPY"
+
+// SaveNpy writes x to the file fname as a 1-D NumPy array. The function panics on write errors.
+func SaveNpy(fname string, x []float64) {
+	if err := SaveNpyErr(fname, x); err != nil {
+		panic(err)
+	}
+}
+
+// SaveNpyErr is SaveNpy returning the write error.
+func SaveNpyErr(fname string, x []float64) error {
+	return myioutil.WriteFile(fname, encodeNpy([]int{len(x)}, x))
+}
+
+// SaveNpyTo writes x to w as a 1-D NumPy array and returns the write error.
+func SaveNpyTo(w io.Writer, x []float64) error {
+	_, err := w.Write(encodeNpy([]int{len(x)}, x))
+	return err
+}
+
+/*
+SaveNpyMatrix writes X to the file fname as a 2-D NumPy array with one row per
+vector of X. The function panics if the vectors of X have different lengths or
+on write errors.
+*/
+func SaveNpyMatrix(fname string, X [][]float64) {
+	if err := SaveNpyMatrixErr(fname, X); err != nil {
+		panic(err)
+	}
+}
+
+/*
+SaveNpyMatrixErr is SaveNpyMatrix returning the write error or an error
+wrapping ErrLength if the vectors of X have different lengths.
+*/
+func SaveNpyMatrixErr(fname string, X [][]float64) error {
+	b, err := encodeNpyMatrix(X)
+	if err != nil {
+		return err
+	}
+	return myioutil.WriteFile(fname, b)
+}
+
+/*
+SaveNpyMatrixTo writes X to w as a 2-D NumPy array and returns the write error
+or an error wrapping ErrLength if the vectors of X have different lengths.
+*/
+func SaveNpyMatrixTo(w io.Writer, X [][]float64) error {
+	b, err := encodeNpyMatrix(X)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+/*
+LoadNpy returns the elements of the NumPy array in the file fname in C order.
+The function panics if the file cannot be read or is not a valid .npy file.
+*/
+func LoadNpy(fname string) []float64 {
+	x, err := LoadNpyErr(fname)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// LoadNpyErr returns LoadNpy(fname) or the error reading or parsing the file.
+func LoadNpyErr(fname string) ([]float64, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	_, x, err := decodeNpy(b)
+	return x, err
+}
+
+/*
+LoadNpyFrom returns the elements in C order of the NumPy array read from r or
+the error reading or parsing r.
+*/
+func LoadNpyFrom(r io.Reader) ([]float64, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, x, err := decodeNpy(b)
+	return x, err
+}
+
+/*
+LoadNpyMatrix returns the rows of the 2-D NumPy array in the file fname. A 1-D
+array is returned as a single row.
+The function panics if the file cannot be read or is not a valid .npy file
+with 1 or 2 dimensions.
+*/
+func LoadNpyMatrix(fname string) [][]float64 {
+	X, err := LoadNpyMatrixErr(fname)
+	if err != nil {
+		panic(err)
+	}
+	return X
+}
+
+// LoadNpyMatrixErr returns LoadNpyMatrix(fname) or the error reading or parsing the file.
+func LoadNpyMatrixErr(fname string) ([][]float64, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNpyMatrix(b)
+}
+
+/*
+LoadNpyMatrixFrom returns the rows of the 1-D or 2-D NumPy array read from r or
+the error reading or parsing r.
+*/
+func LoadNpyMatrixFrom(r io.Reader) ([][]float64, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNpyMatrix(b)
+}
+
+/*
+SaveNpz writes arrays to the file fname as a NumPy .npz archive, which contains
+the 2-D array arrays[name] as name.npy. The archive is read in NumPy by
+numpy.load(fname)[name]. The function panics if the vectors of a matrix have
+different lengths or on write errors.
+*/
+func SaveNpz(fname string, arrays map[string][][]float64) {
+	if err := SaveNpzErr(fname, arrays); err != nil {
+		panic(err)
+	}
+}
+
+/*
+SaveNpzErr is SaveNpz returning the write error or an error wrapping ErrLength
+if the vectors of a matrix have different lengths.
+*/
+func SaveNpzErr(fname string, arrays map[string][][]float64) error {
+	buf := new(bytes.Buffer)
+	if err := SaveNpzTo(buf, arrays); err != nil {
+		return err
+	}
+	return myioutil.WriteFile(fname, buf.Bytes())
+}
+
+/*
+SaveNpzTo writes arrays to w as a NumPy .npz archive and returns the write
+error or an error wrapping ErrLength if the vectors of a matrix have different
+lengths.
+*/
+func SaveNpzTo(w io.Writer, arrays map[string][][]float64) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		b, err := encodeNpyMatrix(arrays[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		w, err := zw.Create(name + ".npy")
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+/*
+LoadNpz returns the arrays of the NumPy .npz archive in the file fname by name,
+without the .npy extension. 1-D arrays are returned as a single row.
+The function panics if the file cannot be read or is not a valid archive of
+arrays with 1 or 2 dimensions.
+*/
+func LoadNpz(fname string) map[string][][]float64 {
+	arrays, err := LoadNpzErr(fname)
+	if err != nil {
+		panic(err)
+	}
+	return arrays
+}
+
+// LoadNpzErr returns LoadNpz(fname) or the error reading or parsing the file.
+func LoadNpzErr(fname string) (map[string][][]float64, error) {
+	zr, err := zip.OpenReader(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return loadNpz(&zr.Reader)
+}
+
+/*
+LoadNpzFrom returns the arrays of the NumPy .npz archive read from r by name or
+the error reading or parsing r.
+*/
+func LoadNpzFrom(r io.Reader) (map[string][][]float64, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	return loadNpz(zr)
+}
+
+func loadNpz(zr *zip.Reader) (map[string][][]float64, error) {
+	arrays := map[string][][]float64{}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".npy") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		X, err := decodeNpyMatrix(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		arrays[strings.TrimSuffix(f.Name, ".npy")] = X
+	}
+	return arrays, nil
+}
+
+// encodeNpy returns the .npy file of the float64 array with shape and elements x in C order
+func encodeNpy(shape []int, x []float64) []byte {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	shapeStr := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		shapeStr += ","
+	}
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%s), }", shapeStr)
+	// The header is padded with spaces and a newline to align the data to 64 bytes
+	preamble := len(npyMagic) + 2 + 2
+	pad := 64 - (preamble+len(header)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	header += strings.Repeat(" ", pad) + "\n"
+	b := make([]byte, preamble+len(header)+8*len(x))
+	copy(b, npyMagic)
+	b[6], b[7] = 1, 0
+	binary.LittleEndian.PutUint16(b[8:], uint16(len(header)))
+	copy(b[preamble:], header)
+	for i, f := range x {
+		binary.LittleEndian.PutUint64(b[preamble+len(header)+8*i:], math.Float64bits(f))
+	}
+	return b
+}
+
+// encodeNpyMatrix returns the .npy file of the 2-D array with rows X
+func encodeNpyMatrix(X [][]float64) ([]byte, error) {
+	cols := 0
+	if len(X) > 0 {
+		cols = len(X[0])
+	}
+	x := make([]float64, 0, len(X)*cols)
+	for i, row := range X {
+		if len(row) != cols {
+			return nil, fmt.Errorf("%w: len(X[%d]) (%d) != len(X[0]) (%d)", ErrLength, i, len(row), cols)
+		}
+		x = append(x, row...)
+	}
+	return encodeNpy([]int{len(X), cols}, x), nil
+}
+
+// decodeNpyMatrix returns the rows of the 1-D or 2-D array in the .npy file b
+func decodeNpyMatrix(b []byte) ([][]float64, error) {
+	shape, x, err := decodeNpy(b)
+	if err != nil {
+		return nil, err
+	}
+	switch len(shape) {
+	case 1:
+		return [][]float64{x}, nil
+	case 2:
+		X := make([][]float64, shape[0])
+		for i := range X {
+			X[i] = x[i*shape[1] : (i+1)*shape[1]]
+		}
+		return X, nil
+	}
+	return nil, fmt.Errorf("%w: npy array with %d dimensions", ErrArgument, len(shape))
+}
+
+// decodeNpy returns the shape and the elements in C order of the array in the .npy file b
+func decodeNpy(b []byte) (shape []int, x []float64, err error) {
+	if len(b) < 10 || string(b[:6]) != npyMagic {
+		return nil, nil, fmt.Errorf("%w: not a .npy file", ErrArgument)
+	}
+	var hdrLen32 uint32
+	var start int
+	switch b[6] {
+	case 1:
+		hdrLen32, start = uint32(binary.LittleEndian.Uint16(b[8:])), 10
+	case 2, 3:
+		if len(b) < 12 {
+			return nil, nil, fmt.Errorf("%w: truncated .npy header", ErrLength)
+		}
+		hdrLen32, start = binary.LittleEndian.Uint32(b[8:]), 12
+	default:
+		return nil, nil, fmt.Errorf("%w: .npy version %d", ErrArgument, b[6])
+	}
+	// The length is compared unsigned because it may not fit an int
+	if uint64(hdrLen32) > uint64(len(b)-start) {
+		return nil, nil, fmt.Errorf("%w: truncated .npy header", ErrLength)
+	}
+	hdrLen := int(hdrLen32)
+	header, data := string(b[start:start+hdrLen]), b[start+hdrLen:]
+	descr := npyField(header, "descr")
+	fortran := npyField(header, "fortran_order") == "True"
+	shape, err = npyShape(npyField(header, "shape"))
+	if err != nil {
+		return nil, nil, err
+	}
+	decode, size, err := npyDecoder(strings.Trim(descr, "'\""))
+	if err != nil {
+		return nil, nil, err
+	}
+	n, err := npyLen(shape, len(data)/size)
+	if err != nil {
+		return nil, nil, err
+	}
+	x = make([]float64, n)
+	for i := range x {
+		x[i] = decode(data[i*size:])
+	}
+	if fortran && len(shape) == 2 {
+		// Transpose from column major to row major
+		rows, cols := shape[0], shape[1]
+		c := make([]float64, n)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				c[i*cols+j] = x[j*rows+i]
+			}
+		}
+		x = c
+	} else if fortran && len(shape) > 2 {
+		return nil, nil, fmt.Errorf("%w: Fortran order array with %d dimensions", ErrArgument, len(shape))
+	}
+	return shape, x, nil
+}
+
+// npyField returns the value of key in the .npy header dictionary
+func npyField(header, key string) string {
+	i := strings.Index(header, "'"+key+"'")
+	if i < 0 {
+		return ""
+	}
+	v := strings.TrimSpace(header[i+len(key)+2:])
+	v = strings.TrimSpace(strings.TrimPrefix(v, ":"))
+	if strings.HasPrefix(v, "(") {
+		if j := strings.Index(v, ")"); j >= 0 {
+			return v[:j+1]
+		}
+	}
+	if j := strings.IndexAny(v, ",}"); j >= 0 {
+		v = v[:j]
+	}
+	return strings.TrimSpace(v)
+}
+
+/*
+npyLen returns the number of elements of an array of shape or an error wrapping
+ErrLength if it exceeds max. Each dimension is checked against max, so that the
+product cannot overflow.
+*/
+func npyLen(shape []int, max int) (int, error) {
+	n := 1
+	for _, d := range shape {
+		if d == 0 {
+			return 0, nil
+		}
+	}
+	for _, d := range shape {
+		if n > max/d {
+			return 0, fmt.Errorf("%w: .npy shape %v exceeds the %d elements of the data", ErrLength, shape, max)
+		}
+		n *= d
+	}
+	return n, nil
+}
+
+// npyShape parses a shape tuple such as (3, 4)
+func npyShape(s string) ([]int, error) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("%w: .npy shape %q", ErrArgument, s)
+	}
+	shape := []int{}
+	for _, d := range strings.Split(s[1:len(s)-1], ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: .npy shape %q", ErrArgument, s)
+		}
+		shape = append(shape, n)
+	}
+	return shape, nil
+}
+
+// npyDecoder returns the function that decodes an element of type descr and the size of the element
+func npyDecoder(descr string) (func([]byte) float64, int, error) {
+	if len(descr) < 3 {
+		return nil, 0, fmt.Errorf("%w: .npy dtype %q", ErrArgument, descr)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if descr[0] == '>' {
+		order = binary.BigEndian
+	}
+	switch descr[1:] {
+	case "f8":
+		return func(b []byte) float64 { return math.Float64frombits(order.Uint64(b)) }, 8, nil
+	case "f4":
+		return func(b []byte) float64 { return float64(math.Float32frombits(order.Uint32(b))) }, 4, nil
+	case "i8":
+		return func(b []byte) float64 { return float64(int64(order.Uint64(b))) }, 8, nil
+	case "i4":
+		return func(b []byte) float64 { return float64(int32(order.Uint32(b))) }, 4, nil
+	case "i2":
+		return func(b []byte) float64 { return float64(int16(order.Uint16(b))) }, 2, nil
+	case "i1":
+		return func(b []byte) float64 { return float64(int8(b[0])) }, 1, nil
+	case "u1":
+		return func(b []byte) float64 { return float64(b[0]) }, 1, nil
+	}
+	return nil, 0, fmt.Errorf("%w: .npy dtype %q", ErrArgument, descr)
+}
@@ -0,0 +1,120 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package onset computes onset detection functions from the frames of a
+short-time Fourier transform (godsp.STFT) and picks the onsets from them.
+Element n of an onset detection function corresponds to STFT frame n.
+
+See: J. P. Bello et al. A Tutorial on Onset Detection in Music Signals.
+IEEE Transactions on Speech and Audio Processing, 13(5), 2005.
+*/
+package onset
+
+import (
+	"math/cmplx"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/peaks"
+)
+
+/*
+SpectralFlux returns the sum over the bins of each frame of the half-wave
+rectified increase of magnitude from the previous frame.
+*/
+func SpectralFlux(frames [][]complex128) []float64 {
+	odf := make([]float64, len(frames))
+	for n := 1; n < len(frames); n++ {
+		for k, c := range frames[n] {
+			if d := cmplx.Abs(c) - cmplx.Abs(frames[n-1][k]); d > 0 {
+				odf[n] += d
+			}
+		}
+	}
+	return odf
+}
+
+/*
+HFC returns the high frequency content of each frame: the sum over the bins k
+of k*|X(k)|^2.
+*/
+func HFC(frames [][]complex128) []float64 {
+	odf := make([]float64, len(frames))
+	for n, frame := range frames {
+		for k, c := range frame {
+			m := cmplx.Abs(c)
+			odf[n] += float64(k) * m * m
+		}
+	}
+	return odf
+}
+
+/*
+ComplexDomain returns the sum over the bins of each frame of the distance
+between the bin and its value predicted from the magnitude and phase of the
+two previous frames. It detects both energy increases and phase deviations.
+*/
+func ComplexDomain(frames [][]complex128) []float64 {
+	odf := make([]float64, len(frames))
+	for n := 2; n < len(frames); n++ {
+		for k, c := range frames[n] {
+			p1, p2 := frames[n-1][k], frames[n-2][k]
+			target := cmplx.Rect(cmplx.Abs(p1), 2*cmplx.Phase(p1)-cmplx.Phase(p2))
+			odf[n] += cmplx.Abs(c - target)
+		}
+	}
+	return odf
+}
+
+/*
+Pick returns the indices of the peaks of the onset detection function odf
+that are at least sep frames apart and have a value >= threshold*max(odf).
+*/
+func Pick(odf []float64, sep int, threshold float64) []int {
+	if len(odf) == 0 {
+		return []int{}
+	}
+	max := godsp.Max(odf)
+	onsets := []int{}
+	for _, i := range peaks.Get(odf, sep) {
+		if max > 0 && odf[i] >= threshold*max {
+			onsets = append(onsets, i)
+		}
+	}
+	return onsets
+}
+
+/*
+PickAdaptive returns the indices of the peaks of the onset detection function
+odf that are at least sep frames apart and exceed the local median plus k times
+the median absolute deviation of the window of window frames centred on the
+peak. Unlike Pick, it follows changes of loudness across a signal.
+See peaks.GetAdaptive.
+*/
+func PickAdaptive(odf []float64, sep, window int, k float64) []int {
+	return peaks.GetAdaptive(odf, sep, window, peaks.LocalMedian, k)
+}
+
+/*
+Times returns the times in seconds of the frames with indices idx, where the
+frames were computed with hop and the signal has sampleRate. The time of a
+frame is the time of its first sample.
+*/
+func Times(idx []int, hop int, sampleRate float64) []float64 {
+	t := make([]float64, len(idx))
+	for i, n := range idx {
+		t[i] = float64(n*hop) / sampleRate
+	}
+	return t
+}
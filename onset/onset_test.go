@@ -0,0 +1,56 @@
+package onset
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/windows"
+)
+
+func TestPick(t *testing.T) {
+	fs := 8000.0
+	x := make([]float64, 8000)
+	// Tone bursts at 0.25 s and 0.625 s
+	for _, start := range []int{2000, 5000} {
+		for i := start; i < start+1000; i++ {
+			x[i] = math.Sin(2*math.Pi*440*float64(i)/fs) * math.Exp(-float64(i-start)/300)
+		}
+	}
+	frames := godsp.STFT(x, 256, 128, windows.Hann)
+	for name, odf := range map[string][]float64{
+		"flux": SpectralFlux(frames), "hfc": HFC(frames), "complex": ComplexDomain(frames),
+	} {
+		onsets := Times(Pick(odf, 10, 0.3), 128, fs)
+		if len(onsets) != 2 || math.Abs(onsets[0]-0.25) > 0.04 || math.Abs(onsets[1]-0.625) > 0.04 {
+			t.Errorf("%s: onsets = %v", name, onsets)
+		}
+	}
+}
+
+func TestPickAdaptive(t *testing.T) {
+	// Onsets every 20 frames with a level that falls by a factor of 100
+	odf := make([]float64, 200)
+	for i := range odf {
+		level := 10.0
+		if i >= 100 {
+			level = 0.1
+		}
+		odf[i] = level * (1 + 0.1*math.Sin(float64(i)))
+		if i%20 == 10 {
+			odf[i] = 3 * level
+		}
+	}
+	if onsets := Pick(odf, 5, 0.5); len(onsets) != 5 {
+		t.Errorf("Pick found %d onsets", len(onsets))
+	}
+	onsets := PickAdaptive(odf, 5, 15, 3)
+	if len(onsets) != 10 {
+		t.Fatalf("onsets = %v", onsets)
+	}
+	for i, n := range onsets {
+		if n != 20*i+10 {
+			t.Errorf("onsets[%d] = %d", i, n)
+		}
+	}
+}
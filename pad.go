@@ -0,0 +1,120 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+)
+
+// PadMode selects the samples that PadTo appends to a signal
+type PadMode int
+
+const (
+	// PadZero extends the signal with zeros: 1 2 3 | 0 0 0
+	PadZero PadMode = iota
+	// PadSymmetric mirrors the signal including its last sample: 1 2 3 | 3 2 1
+	PadSymmetric
+	// PadPeriodic repeats the signal from its start: 1 2 3 | 1 2 3
+	PadPeriodic
+	// PadReflect mirrors the signal excluding its last sample: 1 2 3 | 2 1 2
+	PadReflect
+	// PadEdge repeats the last sample of the signal: 1 2 3 | 3 3 3
+	PadEdge
+)
+
+/*
+PadTo returns a copy of x extended to length n with the samples selected by
+mode.
+The function panics if n < len(x), if x is empty and mode is not PadZero, or
+if mode is invalid.
+*/
+func PadTo[T Float](x []T, n int, mode PadMode) []T {
+	y, err := PadToErr(x, n, mode)
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
+
+/*
+PadToErr returns PadTo(x, n, mode) or an error wrapping ErrLength if n < len(x)
+or x is empty and mode is not PadZero, or ErrArgument if mode is invalid.
+*/
+func PadToErr[T Float](x []T, n int, mode PadMode) ([]T, error) {
+	N := len(x)
+	switch {
+	case n < N:
+		return nil, fmt.Errorf("%w: cannot pad %d samples to %d", ErrLength, N, n)
+	case mode < PadZero || mode > PadEdge:
+		return nil, fmt.Errorf("%w: pad mode %d", ErrArgument, mode)
+	case N == 0 && n > 0 && mode != PadZero:
+		return nil, fmt.Errorf("%w: cannot pad empty signal", ErrLength)
+	}
+	y := make([]T, n)
+	copy(y, x)
+	if mode == PadZero {
+		return y, nil
+	}
+	for i := N; i < n; i++ {
+		switch {
+		case mode == PadEdge || N == 1:
+			y[i] = x[N-1]
+		case mode == PadSymmetric:
+			j := i % (2 * N)
+			if j >= N {
+				j = 2*N - 1 - j
+			}
+			y[i] = x[j]
+		case mode == PadPeriodic:
+			y[i] = x[i%N]
+		case mode == PadReflect:
+			j := i % (2*N - 2)
+			if j >= N {
+				j = 2*N - 2 - j
+			}
+			y[i] = x[j]
+		}
+	}
+	return y, nil
+}
+
+/*
+PadToPow2 returns PadTo(x, NextPow2(len(x)), mode): x extended to the smallest
+power of 2 length, as required by the DWT and the radix-2 FFT. A signal whose
+length is a power of 2, or that is empty, is copied unchanged.
+*/
+func PadToPow2[T Float](x []T, mode PadMode) []T {
+	if len(x) == 0 {
+		return []T{}
+	}
+	return PadTo(x, NextPow2(len(x)), mode)
+}
+
+/*
+Truncate returns a copy of the first n samples of x, or a copy of x if
+len(x) <= n.
+The function panics if n < 0.
+*/
+func Truncate[T Float](x []T, n int) []T {
+	if n < 0 {
+		panic(fmt.Errorf("%w: n = %d", ErrArgument, n))
+	}
+	if n > len(x) {
+		n = len(x)
+	}
+	y := make([]T, n)
+	copy(y, x)
+	return y
+}
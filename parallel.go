@@ -0,0 +1,92 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"sync"
+)
+
+/*
+Option configures the functions that process a set of vectors, e.g.
+DownSampleAll, and the functions that read audio files.
+*/
+type Option func(*options)
+
+type options struct {
+	workers  int
+	progress ProgressFunc
+}
+
+func getOptions(opts []Option) *options {
+	o := &options{
+		workers: 1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+/*
+WithWorkers sets the number of goroutines that process the vectors of a set
+concurrently. The default is 1: the vectors are processed serially.
+runtime.GOMAXPROCS(0) is a good choice for multi-channel signals.
+The function panics if n < 1.
+*/
+func WithWorkers(n int) Option {
+	if n < 1 {
+		panic(fmt.Sprintf("Invalid number of workers %d", n))
+	}
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+/*
+ParallelMap returns f(x) for every x in xs. The elements of xs are processed
+concurrently by workers goroutines. The order of the results is the order
+of xs.
+The function panics if workers < 1.
+*/
+func ParallelMap[T, U any](xs []T, workers int, f func(T) U) []U {
+	if workers < 1 {
+		panic(fmt.Sprintf("Invalid number of workers %d", workers))
+	}
+	ys := make([]U, len(xs))
+	if workers == 1 {
+		for i, x := range xs {
+			ys[i] = f(x)
+		}
+		return ys
+	}
+	indices := make(chan int)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				ys[i] = f(xs[i])
+			}
+		}()
+	}
+	for i := range xs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return ys
+}
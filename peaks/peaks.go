@@ -20,6 +20,7 @@ each other than a minimum separation distance are merged to the left (lower inde
 package peaks
 
 import (
+	"fmt"
 	"math"
 	"sort"
 
@@ -45,6 +46,21 @@ func Get(x []float64, sep int) []int {
 	return pks
 }
 
+/*
+GetValleys returns a slice containing the indices of the valleys (minima) in x.
+sep is the minimum distance between 2 valleys. Valleys closer to each other
+than sep are merged to the lower index.
+*/
+func GetValleys(x []float64, sep int) []int {
+	vls := []int{}
+	for i := range x {
+		if isMin(i, i-sep, i+sep, x) {
+			vls = append(vls, i)
+		}
+	}
+	return vls
+}
+
 func getMaxIndex(x []float64) int {
 	i, max := 0, math.Inf(-1)
 	for j, y := range x {
@@ -78,6 +94,26 @@ func isMax(i, min, max int, x []float64) bool {
 	return true
 }
 
+func isMin(i, min, max int, x []float64) bool {
+	if min < 0 {
+		min = 0
+	}
+	if max > len(x) {
+		max = len(x)
+	}
+	for j := min; j < i; j++ {
+		if x[j] <= x[i] {
+			return false
+		}
+	}
+	for j := i + 1; j < max; j++ {
+		if x[j] < x[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func getWindow(i, sep int, x []float64) (min, max int) {
 	min, max = i-sep, i+sep
 	if min < 0 {
@@ -133,3 +169,67 @@ func markNeighbours(xi, sep int, pks []int) {
 		pks[i] = xi
 	}
 }
+
+// LocalCenter selects the local centre of the adaptive threshold of GetAdaptive
+type LocalCenter int
+
+const (
+	// LocalMedian is the median of the window
+	LocalMedian LocalCenter = iota
+	// LocalMean is the mean of the window
+	LocalMean
+)
+
+/*
+GetAdaptive returns the indices of the peaks of x, as by Get, that exceed an
+adaptive threshold: x[i] > c + k*MAD, where c is the median or mean, selected
+by center, and MAD the median absolute deviation from the median of the window
+of window samples centred on i. The threshold follows slow changes of the level
+of x, such as the loudness of an onset detection function across a track.
+The function panics if window < 1.
+*/
+func GetAdaptive(x []float64, sep, window int, center LocalCenter, k float64) []int {
+	if window < 1 {
+		panic(fmt.Sprintf("Invalid window %d", window))
+	}
+	pks := []int{}
+	dev := make([]float64, 0, window)
+	for _, i := range Get(x, sep) {
+		lo, hi := i-window/2, i+window/2+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(x) {
+			hi = len(x)
+		}
+		wdw := x[lo:hi]
+		med := godsp.Median(wdw)
+		dev = dev[:0]
+		for _, f := range wdw {
+			dev = append(dev, math.Abs(f-med))
+		}
+		c := med
+		if center == LocalMean {
+			c = godsp.Average(wdw)
+		}
+		if x[i] > c+k*godsp.Median(dev) {
+			pks = append(pks, i)
+		}
+	}
+	return pks
+}
+
+/*
+DetectEvents returns the indices of the peaks of the detection statistic stat,
+e.g. of godsp.MatchedFilter, that are >= threshold. Peaks closer to each
+other than sep are merged as by Get.
+*/
+func DetectEvents(stat []float64, threshold float64, sep int) []int {
+	events := []int{}
+	for _, i := range Get(stat, sep) {
+		if stat[i] >= threshold {
+			events = append(events, i)
+		}
+	}
+	return events
+}
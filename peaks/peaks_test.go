@@ -0,0 +1,101 @@
+package peaks
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/internal/benchdata"
+)
+
+func equalInts(x, y []int) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetValleys(t *testing.T) {
+	for _, test := range []struct {
+		x    []float64
+		sep  int
+		want []int
+	}{
+		{[]float64{3, 1, 2, 0, 4, 2, 2, 5, 1}, 2, []int{1, 3, 8}},
+		{[]float64{3, 1, 2, 0, 4, 2, 2, 5, 1}, 4, []int{3, 8}},
+		// A plateau is merged to its lower index
+		{[]float64{2, 1, 1, 3}, 2, []int{1}},
+		// Valleys at the edges
+		{[]float64{0, 2, 3, 1}, 2, []int{0, 3}},
+	} {
+		if got := GetValleys(test.x, test.sep); !equalInts(got, test.want) {
+			t.Errorf("GetValleys(%v, %d) = %v, want %v", test.x, test.sep, got, test.want)
+		}
+	}
+	x := benchdata.Signal(1000)
+	for _, sep := range []int{1, 3, 10, 100} {
+		if got, want := GetValleys(x, sep), Get(godsp.MulS(x, -1), sep); !equalInts(got, want) {
+			t.Errorf("sep %d: GetValleys(x) = %v, Get(-x) = %v", sep, got, want)
+		}
+	}
+}
+
+func TestDetectEvents(t *testing.T) {
+	template := make([]float64, 32)
+	for i := range template {
+		template[i] = math.Sin(float64(i*i) / 20)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 2000)
+	for i := range x {
+		x[i] = 0.1 * rnd.NormFloat64()
+	}
+	// The matched filter is invariant to the scale and offset of the template
+	offsets := []int{100, 700, 1500}
+	for j, offset := range offsets {
+		for i, f := range template {
+			x[offset+i] += float64(j+1)*f + 0.5
+		}
+	}
+	stat := godsp.MatchedFilter(x, template)
+	if got := DetectEvents(stat, 0.8, len(template)); !equalInts(got, offsets) {
+		t.Fatalf("events = %v, want %v", got, offsets)
+	}
+	// An event is detected if its statistic is >= threshold
+	weakest := offsets[0]
+	for _, i := range offsets {
+		if stat[i] < stat[weakest] {
+			weakest = i
+		}
+	}
+	if got := DetectEvents(stat, stat[weakest], len(template)); !equalInts(got, offsets) {
+		t.Errorf("threshold = stat[%d]: events = %v, want %v", weakest, got, offsets)
+	}
+	if got := DetectEvents(stat, math.Nextafter(stat[weakest], 2), len(template)); len(got) != 2 {
+		t.Errorf("threshold > stat[%d]: events = %v", weakest, got)
+	}
+	if got := DetectEvents(stat, 1.01, len(template)); len(got) != 0 {
+		t.Errorf("threshold > 1: events = %v", got)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, n := range benchdata.Sizes {
+		x := benchdata.Envelope(n, 441)
+		for _, sep := range []int{10, 200} {
+			b.Run(fmt.Sprintf("N=%d/sep=%d", n, sep), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					Get(x, sep)
+				}
+			})
+		}
+	}
+}
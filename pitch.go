@@ -0,0 +1,110 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+/*
+YINThreshold is the voicing threshold of PitchYIN. A frame is voiced if the
+minimum of its cumulative mean normalised difference function is below
+YINThreshold.
+*/
+const YINThreshold = 0.1
+
+/*
+PitchYIN returns the fundamental frequency in Hz of each frame of x estimated
+by the YIN algorithm:
+A. de Cheveigné and H. Kawahara. YIN, a fundamental frequency estimator for
+speech and music. J. Acoust. Soc. Am. 111(4), 2002.
+
+Frame i starts at sample i*hop and has length frameLen. The lowest detectable
+frequency is 2*sampleRate/frameLen. The f0 of an unvoiced frame is 0. See
+YINThreshold.
+The function panics if frameLen < 4 or hop < 1.
+*/
+func PitchYIN(x []float64, sampleRate float64, frameLen, hop int) []float64 {
+	f0, err := PitchYINErr(x, sampleRate, frameLen, hop)
+	if err != nil {
+		panic(err)
+	}
+	return f0
+}
+
+/*
+PitchYINErr returns PitchYIN(x, sampleRate, frameLen, hop) or an error wrapping
+ErrArgument if frameLen < 4 or hop < 1.
+*/
+func PitchYINErr(x []float64, sampleRate float64, frameLen, hop int) ([]float64, error) {
+	if err := checkFrames(frameLen, hop, 4); err != nil {
+		return nil, err
+	}
+	it := Frames(x, frameLen, hop, WithZeroCopy())
+	f0 := make([]float64, it.Len())
+	d := make([]float64, frameLen/2)
+	for it.Next() {
+		if tau := yin(it.Frame(), d); tau > 0 {
+			f0[it.Index()] = sampleRate / tau
+		}
+	}
+	return f0, nil
+}
+
+/*
+yin returns the period in samples of frame or 0 if frame is unvoiced. d is the
+workspace for the difference function and has length len(frame)/2.
+*/
+func yin(frame, d []float64) float64 {
+	W := len(d)
+	// Difference function
+	for tau := 1; tau < W; tau++ {
+		sum := 0.0
+		for j := 0; j < W; j++ {
+			diff := frame[j] - frame[j+tau]
+			sum += diff * diff
+		}
+		d[tau] = sum
+	}
+	// Cumulative mean normalised difference function
+	d[0] = 1
+	runningSum := 0.0
+	for tau := 1; tau < W; tau++ {
+		runningSum += d[tau]
+		if runningSum == 0 {
+			d[tau] = 1
+		} else {
+			d[tau] *= float64(tau) / runningSum
+		}
+	}
+	// Absolute threshold
+	for tau := 2; tau < W; tau++ {
+		if d[tau] < YINThreshold {
+			for tau+1 < W && d[tau+1] < d[tau] {
+				tau++
+			}
+			return parabolicMin(d, tau)
+		}
+	}
+	return 0
+}
+
+// parabolicMin returns the position of the vertex of the parabola through d[i-1:i+2]
+func parabolicMin(d []float64, i int) float64 {
+	if i < 1 || i+1 >= len(d) {
+		return float64(i)
+	}
+	den := d[i-1] - 2*d[i] + d[i+1]
+	if den == 0 {
+		return float64(i)
+	}
+	return float64(i) + (d[i-1]-d[i+1])/(2*den)
+}
@@ -0,0 +1,292 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package plot renders waveforms, envelopes with marked peaks, scalograms and
+spectrograms to images, which can be written as PNG files for inspection
+without exporting text files to other tools.
+*/
+package plot
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+// SpectrogramRange is the dynamic range in dB shown by Spectrogram
+const SpectrogramRange = 80.0
+
+// The colours of the images
+var (
+	Background    = color.RGBA{255, 255, 255, 255}
+	WaveColor     = color.RGBA{31, 119, 180, 255}
+	EnvelopeColor = color.RGBA{255, 127, 14, 255}
+	PeakColor     = color.RGBA{214, 39, 40, 255}
+)
+
+/*
+Waveform returns a width x height image of x. Each column of pixels shows the
+range of the samples of x that fall on it, so that long signals are drawn
+without aliasing. The vertical axis spans the range of x.
+The function panics if width or height is less than 1.
+*/
+func Waveform(x []float64, width, height int) *image.RGBA {
+	img := newImage(width, height)
+	lo, hi := valueRange(x)
+	drawSeries(img, x, lo, hi, WaveColor)
+	return img
+}
+
+/*
+Envelope returns a width x height image of the signal x, its envelope env and
+vertical markers at the indices peaks of env. x and env have the same sample
+rate and the vertical axis spans the range of both. x may be nil to draw only
+the envelope.
+The function panics if width or height is less than 1.
+*/
+func Envelope(x, env []float64, peaks []int, width, height int) *image.RGBA {
+	img := newImage(width, height)
+	lo, hi := valueRange(append(append([]float64{}, x...), env...))
+	drawSeries(img, x, lo, hi, WaveColor)
+	drawSeries(img, env, lo, hi, EnvelopeColor)
+	n := len(env)
+	if len(x) > n {
+		n = len(x)
+	}
+	for _, p := range peaks {
+		if p < 0 || p >= n {
+			continue
+		}
+		c := p * width / n
+		for r := 0; r < height; r++ {
+			img.SetRGBA(c, r, PeakColor)
+		}
+		if p < len(env) {
+			r := toRow(env[p], lo, hi, height)
+			for dc := -2; dc <= 2; dc++ {
+				for dr := -2; dr <= 2; dr++ {
+					img.SetRGBA(c+dc, r+dr, PeakColor)
+				}
+			}
+		}
+	}
+	return img
+}
+
+/*
+Scalogram returns a width x height heat map of the scalogram S, e.g. of
+cwt.Scalogram, in which row i contains the magnitudes at scale i. Time runs
+from left to right and row 0 is at the top. The colours map magnitudes from 0
+to the maximum of S linearly.
+The function panics if width or height is less than 1.
+*/
+func Scalogram(S [][]float64, width, height int) *image.RGBA {
+	max := 0.0
+	for _, row := range S {
+		for _, v := range row {
+			max = math.Max(max, v)
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+	return heatMap(len(S), rowLen(S), width, height, func(r, c int) float64 {
+		return S[r][c] / max
+	})
+}
+
+/*
+Spectrogram returns a width x height heat map of the magnitude spectrogram S,
+e.g. of godsp.Spectrogram, in which S[i] contains the frequency bins of frame i.
+Time runs from left to right and frequency from bottom to top. The colours map
+the magnitudes in dB from SpectrogramRange below the maximum of S to the
+maximum.
+The function panics if width or height is less than 1.
+*/
+func Spectrogram(S [][]float64, width, height int) *image.RGBA {
+	max := 0.0
+	for _, frame := range S {
+		for _, v := range frame {
+			max = math.Max(max, v)
+		}
+	}
+	bins := rowLen(S)
+	return heatMap(bins, len(S), width, height, func(r, c int) float64 {
+		m := S[c][bins-1-r]
+		if m <= 0 || max == 0 {
+			return 0
+		}
+		return 1 + 20*math.Log10(m/max)/SpectrogramRange
+	})
+}
+
+/*
+SavePNG writes img to the file fname as a PNG image.
+The function panics if the file cannot be written.
+*/
+func SavePNG(fname string, img image.Image) {
+	if err := SavePNGErr(fname, img); err != nil {
+		panic(err)
+	}
+}
+
+// SavePNGErr is SavePNG returning the write error.
+func SavePNGErr(fname string, img image.Image) error {
+	buf := new(bytes.Buffer)
+	if err := WritePNG(buf, img); err != nil {
+		return err
+	}
+	return myioutil.WriteFile(fname, buf.Bytes())
+}
+
+// WritePNG writes img to w as a PNG image and returns the write error.
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+/*
+Colormap returns the colour of v in [0,1] on a perceptually uniform dark blue
+to yellow scale. v is clipped to [0,1].
+*/
+func Colormap(v float64) color.RGBA {
+	if math.IsNaN(v) || v < 0 {
+		v = 0
+	} else if v > 1 {
+		v = 1
+	}
+	f := v * float64(len(viridis)-1)
+	i := int(f)
+	if i == len(viridis)-1 {
+		return viridis[i]
+	}
+	t := f - float64(i)
+	c0, c1 := viridis[i], viridis[i+1]
+	lerp := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + t*(float64(b)-float64(a)))) }
+	return color.RGBA{lerp(c0.R, c1.R), lerp(c0.G, c1.G), lerp(c0.B, c1.B), 255}
+}
+
+// viridis are equally spaced colours of the viridis colour map
+var viridis = []color.RGBA{
+	{68, 1, 84, 255},
+	{72, 40, 120, 255},
+	{62, 74, 137, 255},
+	{49, 104, 142, 255},
+	{38, 130, 142, 255},
+	{31, 158, 137, 255},
+	{53, 183, 121, 255},
+	{109, 205, 89, 255},
+	{180, 222, 44, 255},
+	{253, 231, 37, 255},
+}
+
+func newImage(width, height int) *image.RGBA {
+	if width < 1 || height < 1 {
+		panic("width and height must be at least 1")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = Background.R, Background.G, Background.B, Background.A
+	}
+	return img
+}
+
+// valueRange returns the range of x, widened if x is constant
+func valueRange(x []float64) (lo, hi float64) {
+	if len(x) == 0 {
+		return -1, 1
+	}
+	lo, hi = x[0], x[0]
+	for _, v := range x {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	return lo, hi
+}
+
+// toRow returns the pixel row of v on a vertical axis from lo at the bottom to hi at the top
+func toRow(v, lo, hi float64, height int) int {
+	r := int(math.Round((hi - v) / (hi - lo) * float64(height-1)))
+	if r < 0 {
+		return 0
+	}
+	if r > height-1 {
+		return height - 1
+	}
+	return r
+}
+
+/*
+drawSeries draws x across the width of img. Each column shows the range of the
+samples that fall on it and the first sample of the next column, so that
+consecutive columns are connected.
+*/
+func drawSeries(img *image.RGBA, x []float64, lo, hi float64, c color.RGBA) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	n := len(x)
+	if n == 0 {
+		return
+	}
+	for col := 0; col < width; col++ {
+		start, end := col*n/width, (col+1)*n/width
+		if start >= n {
+			break
+		}
+		if end < n {
+			end++
+		}
+		if end <= start {
+			end = start + 1
+		}
+		min, max := x[start], x[start]
+		for _, v := range x[start:end] {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+		for r := toRow(max, lo, hi, height); r <= toRow(min, lo, hi, height); r++ {
+			img.SetRGBA(col, r, c)
+		}
+	}
+}
+
+/*
+heatMap returns a width x height image of a rows x cols matrix with values
+value(r, c) in [0,1] by nearest neighbour sampling.
+*/
+func heatMap(rows, cols, width, height int, value func(r, c int) float64) *image.RGBA {
+	img := newImage(width, height)
+	if rows == 0 || cols == 0 {
+		return img
+	}
+	for y := 0; y < height; y++ {
+		r := y * rows / height
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, Colormap(value(r, x*cols/width)))
+		}
+	}
+	return img
+}
+
+func rowLen(M [][]float64) int {
+	if len(M) == 0 {
+		return 0
+	}
+	return len(M[0])
+}
@@ -0,0 +1,65 @@
+package plot
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestWaveform(t *testing.T) {
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * float64(i) / 1000)
+	}
+	img := Waveform(x, 100, 51)
+	// The maximum is at a quarter and the minimum at three quarters of the width
+	if img.RGBAAt(25, 0) != WaveColor || img.RGBAAt(75, 50) != WaveColor {
+		t.Error("extremes not drawn")
+	}
+	if img.RGBAAt(25, 50) != Background || img.RGBAAt(75, 0) != Background {
+		t.Error("unexpected pixels drawn")
+	}
+	// Every column is connected
+	for c := 0; c < 100; c++ {
+		drawn := false
+		for r := 0; r < 51; r++ {
+			drawn = drawn || img.RGBAAt(c, r) == WaveColor
+		}
+		if !drawn {
+			t.Fatalf("column %d is empty", c)
+		}
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	env := []float64{0, 1, 0, 0.5, 0}
+	img := Envelope(nil, env, []int{1, 3}, 50, 20)
+	if img.RGBAAt(10, 5) != PeakColor || img.RGBAAt(30, 19) != PeakColor {
+		t.Error("peak markers not drawn")
+	}
+	if img.RGBAAt(20, 5) == PeakColor {
+		t.Error("unexpected peak marker")
+	}
+}
+
+func TestHeatMaps(t *testing.T) {
+	S := [][]float64{{0, 1}, {0, 0}}
+	img := Spectrogram(S, 4, 4)
+	// Frame 0 on the left has its maximum in the upper bin
+	if img.RGBAAt(0, 0) != Colormap(1) || img.RGBAAt(0, 3) != Colormap(0) || img.RGBAAt(3, 0) != Colormap(0) {
+		t.Errorf("spectrogram = %v %v %v", img.RGBAAt(0, 0), img.RGBAAt(0, 3), img.RGBAAt(3, 0))
+	}
+	img = Scalogram([][]float64{{2, 1}}, 2, 1)
+	if img.RGBAAt(0, 0) != Colormap(1) || img.RGBAAt(1, 0) != Colormap(0.5) {
+		t.Error("scalogram colours")
+	}
+	fname := filepath.Join(t.TempDir(), "s.png")
+	SavePNG(fname, img)
+	buf := new(bytes.Buffer)
+	WritePNG(buf, img)
+	if dec, err := png.Decode(buf); err != nil || dec.Bounds() != img.Bounds() {
+		t.Errorf("png.Decode: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ppeaks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+/*
+peaksJSON is the JSON encoding of Peaks. The persistence of the global maximum,
+which is infinite, is encoded as null. Seq and Height are values of the
+original time series, also for valleys.
+*/
+type peaksJSON struct {
+	Seq     []float64  `json:"seq"`
+	Valleys bool       `json:"valleys,omitempty"`
+	Peaks   []peakJSON `json:"peaks"`
+}
+
+type peakJSON struct {
+	Index       int      `json:"index"`
+	Died        int      `json:"died"`
+	Left        int      `json:"left"`
+	Right       int      `json:"right"`
+	Height      float64  `json:"height"`
+	Persistence *float64 `json:"persistence"`
+}
+
+/*
+MarshalJSON encodes pks with its time series, so that the decoded Peaks can be
+queried by GetIndices, Max and MinMaxPersistence.
+*/
+func (pks *Peaks) MarshalJSON() ([]byte, error) {
+	pj := peaksJSON{Seq: pks.seq, Valleys: pks.valleys, Peaks: make([]peakJSON, len(pks.peaks))}
+	if pks.valleys {
+		pj.Seq = negate(pks.seq)
+	}
+	for i, pk := range pks.peaks {
+		pj.Peaks[i] = peakJSON{
+			Index:  pk.born,
+			Died:   pk.died,
+			Left:   pk.left,
+			Right:  pk.right,
+			Height: pks.height(pk.born),
+		}
+		if p := pk.getPersistence(pks.seq); !math.IsInf(p, 1) {
+			pj.Peaks[i].Persistence = &p
+		}
+	}
+	return json.Marshal(pj)
+}
+
+// UnmarshalJSON decodes Peaks encoded by MarshalJSON
+func (pks *Peaks) UnmarshalJSON(b []byte) error {
+	var pj peaksJSON
+	if err := json.Unmarshal(b, &pj); err != nil {
+		return err
+	}
+	pks.seq, pks.valleys = pj.Seq, pj.Valleys
+	if pks.valleys {
+		pks.seq = negate(pj.Seq)
+	}
+	pks.peaks = make([]*Peak, len(pj.Peaks))
+	for i, p := range pj.Peaks {
+		pks.peaks[i] = &Peak{born: p.Index, died: p.Died, left: p.Left, right: p.Right}
+	}
+	return nil
+}
+
+/*
+WritePeaksJSON writes pks to the file fname as JSON.
+The function panics if the file cannot be written.
+*/
+func WritePeaksJSON(pks *Peaks, fname string) {
+	b, err := json.Marshal(pks)
+	if err != nil {
+		panic(err)
+	}
+	if err := myioutil.WriteFile(fname, b); err != nil {
+		panic(err)
+	}
+}
+
+/*
+ReadPeaksJSON returns the Peaks written to the file fname by WritePeaksJSON.
+The function panics if the file cannot be read or decoded.
+*/
+func ReadPeaksJSON(fname string) *Peaks {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		panic(err)
+	}
+	pks := new(Peaks)
+	if err := json.Unmarshal(b, pks); err != nil {
+		panic(err)
+	}
+	return pks
+}
@@ -0,0 +1,170 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ppeaks
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Peak2D is a local maximum of a matrix, e.g. a spectrogram or scalogram.
+*/
+type Peak2D struct {
+	Row, Col int
+	Value    float64
+	/*
+		Persistence is the height of the peak above the saddle at which its
+		8-connected region merged into the region of a higher peak. It is +Inf
+		for the global maximum.
+	*/
+	Persistence float64
+}
+
+/*
+Options2D select the peaks returned by GetPeaks2D.
+*/
+type Options2D struct {
+	/*
+		Neighborhood is the radius of the (2*Neighborhood+1)^2 window centred on
+		a peak of which it is the maximum. The minimum and zero value is 1.
+	*/
+	Neighborhood int
+	// Threshold is the minimum value of a peak
+	Threshold float64
+	// MinPersistence is the minimum persistence of a peak
+	MinPersistence float64
+}
+
+/*
+GetPeaks2D returns the peaks of the matrix M, which must have rows of equal
+length, in decreasing order of persistence. Of equal values in a window the
+first in row major order is the peak. The persistence of the peaks is computed
+by the 2D analogue of GetPeaks over the 8-connected grid of M.
+*/
+func GetPeaks2D(M [][]float64, opts Options2D) []Peak2D {
+	rows := len(M)
+	if rows == 0 || len(M[0]) == 0 {
+		return []Peak2D{}
+	}
+	cols := len(M[0])
+	persistence := persistence2D(M)
+	r := opts.Neighborhood
+	if r < 1 {
+		r = 1
+	}
+	pks := []Peak2D{}
+	for i := range M {
+		for j, v := range M[i] {
+			if v < opts.Threshold || persistence[i*cols+j] < opts.MinPersistence ||
+				!isMax2D(M, i, j, r) {
+				continue
+			}
+			pks = append(pks, Peak2D{Row: i, Col: j, Value: v, Persistence: persistence[i*cols+j]})
+		}
+	}
+	sort.SliceStable(pks, func(i, j int) bool {
+		if pks[i].Persistence != pks[j].Persistence {
+			return pks[i].Persistence > pks[j].Persistence
+		}
+		return pks[i].Value > pks[j].Value
+	})
+	return pks
+}
+
+// isMax2D returns true if M[i][j] is the maximum of the window of radius r
+func isMax2D(M [][]float64, i, j, r int) bool {
+	v := M[i][j]
+	for k := i - r; k <= i+r; k++ {
+		if k < 0 || k >= len(M) {
+			continue
+		}
+		for l := j - r; l <= j+r; l++ {
+			if l < 0 || l >= len(M[k]) || (k == i && l == j) {
+				continue
+			}
+			// Equal values before M[i][j] in row major order take precedence
+			if M[k][l] > v || (M[k][l] == v && (k < i || (k == i && l < j))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+persistence2D returns the persistence of every element of M in row major order.
+Elements that are not the highest element of a region when it is merged have
+persistence 0.
+*/
+func persistence2D(M [][]float64) []float64 {
+	rows, cols := len(M), len(M[0])
+	n := rows * cols
+	value := func(idx int) float64 { return M[idx/cols][idx%cols] }
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool { return value(indices[i]) > value(indices[j]) })
+	// rank[idx] is the position of idx in descending order
+	rank := make([]int, n)
+	for r, idx := range indices {
+		rank[idx] = r
+	}
+
+	// Union-find of regions, each identified by the index of its highest element
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = none
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	persistence := make([]float64, n)
+	for _, idx := range indices {
+		parent[idx] = idx
+		i, j := idx/cols, idx%cols
+		for di := -1; di <= 1; di++ {
+			for dj := -1; dj <= 1; dj++ {
+				ni, nj := i+di, j+dj
+				if ni < 0 || ni >= rows || nj < 0 || nj >= cols {
+					continue
+				}
+				nb := ni*cols + nj
+				if parent[nb] == none {
+					continue
+				}
+				a, b := find(idx), find(nb)
+				if a == b {
+					continue
+				}
+				// The region with the lower peak dies, unless it is the region of idx alone
+				if rank[a] > rank[b] {
+					a, b = b, a
+				}
+				if b != idx {
+					persistence[b] = value(b) - value(idx)
+				}
+				parent[b] = a
+			}
+		}
+	}
+	persistence[find(indices[0])] = math.Inf(1)
+	return persistence
+}
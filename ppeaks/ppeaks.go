@@ -19,14 +19,21 @@ https://www.sthu.org/blog/13-perstopology-peakdetection/index.html.
 package ppeaks
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"math"
-    "sort"
-    
-    "github.com/goccmack/godsp"
+	"sort"
+
+	"github.com/goccmack/godsp"
+	myioutil "github.com/goccmack/goutil/ioutil"
 )
 
 const none = -1
 
+// ctxCheckInterval is the number of samples processed between checks of the context of GetPeaksContext
+const ctxCheckInterval = 4096
+
 type Peak struct {
 	born, died, left, right int
 }
@@ -43,6 +50,8 @@ func newPeak(startIdx int) *Peak {
 type Peaks struct {
 	peaks []*Peak
 	seq   []float64
+	// valleys is true if seq is the negated time series of GetValleys
+	valleys bool
 }
 
 func (p *Peak) getPersistence(seq []float64) float64 {
@@ -62,10 +71,19 @@ func GetPeaksInt(seq []int) *Peaks {
 }
 
 /*
-GetPeaks returns the peaks in a floating point time series. 
+GetPeaks returns the peaks in a floating point time series.
 Peaks are returnend in increasing order of their indices.
 */
 func GetPeaks(seq []float64) *Peaks {
+	pks, _ := GetPeaksContext(context.Background(), seq)
+	return pks
+}
+
+/*
+GetPeaksContext returns GetPeaks(seq) or ctx.Err() if ctx is done before the
+peaks are found. ctx is checked every ctxCheckInterval samples.
+*/
+func GetPeaksContext(ctx context.Context, seq []float64) (*Peaks, error) {
 	peaks := make([]*Peak, 0, 1024)
 	// Maps indices to peaks
 	idxtopeak := make([]int, len(seq))
@@ -76,7 +94,10 @@ func GetPeaks(seq []float64) *Peaks {
 	indices := godsp.Range(len(seq))
 	sort.SliceStable(indices, func(i, j int) bool { return seq[indices[i]] > seq[indices[j]] })
 	// Process each sample in descending order
-	for _, idx := range indices {
+	for n, idx := range indices {
+		if n%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		lftdone := (idx > 0 && idxtopeak[idx-1] != none)
 		rgtdone := (idx < len(seq)-1 && idxtopeak[idx+1] != none)
 		il := none
@@ -126,7 +147,27 @@ func GetPeaks(seq []float64) *Peaks {
 	return &Peaks{
 		peaks: peaks,
 		seq:   seq,
-	}
+	}, nil
+}
+
+/*
+GetValleysInt finds the valleys (minima) in an integer time series.
+See GetValleys.
+*/
+func GetValleysInt(seq []int) *Peaks {
+	return GetValleys(godsp.ToFloat(seq))
+}
+
+/*
+GetValleys returns the valleys (minima) in a floating point time series as the
+peaks of -seq. The persistence of a valley is its depth below the saddle at
+which it merges into a deeper valley, and Max returns the deepest valley.
+Valleys are returned in increasing order of their indices.
+*/
+func GetValleys(seq []float64) *Peaks {
+	pks := GetPeaks(negate(seq))
+	pks.valleys = true
+	return pks
 }
 
 /*
@@ -144,6 +185,72 @@ func (pks *Peaks) GetIndices(fracOfMaxPersistence float64) []int {
 	return indices
 }
 
+/*
+IndexOptions select peaks by GetIndicesWith. The zero value selects all peaks.
+*/
+type IndexOptions struct {
+	// FracOfMaxPersistence is the minimum fraction of the maximum persistence, as in GetIndices
+	FracOfMaxPersistence float64
+	// MinPersistence is the minimum absolute persistence
+	MinPersistence float64
+	/*
+		MinHeight is the minimum height of a peak if it is not nil. For valleys
+		it is the minimum value of the original time series at the valley.
+	*/
+	MinHeight *float64
+	/*
+		MinSeparation is the minimum distance in samples between selected peaks.
+		Of peaks closer than MinSeparation the peak with the highest persistence
+		is selected.
+	*/
+	MinSeparation int
+	// MaxPeaks is the maximum number of peaks, with the highest persistence, if it is > 0
+	MaxPeaks int
+}
+
+/*
+GetIndicesWith returns the indices in the original time series `seq` of the
+peaks selected by opts in increasing order.
+*/
+func (pks *Peaks) GetIndicesWith(opts IndexOptions) []int {
+	_, maxPersistence := pks.MinMaxPersistence()
+	cands := make([]*Peak, 0, len(pks.peaks))
+	for _, pk := range pks.peaks {
+		prs := pk.getPersistence(pks.seq)
+		if prs/maxPersistence < opts.FracOfMaxPersistence || prs < opts.MinPersistence ||
+			(opts.MinHeight != nil && pks.height(pk.born) < *opts.MinHeight) {
+			continue
+		}
+		cands = append(cands, pk)
+	}
+	// Strongest peaks first
+	sort.SliceStable(cands, func(i, j int) bool {
+		pi, pj := cands[i].getPersistence(pks.seq), cands[j].getPersistence(pks.seq)
+		if pi != pj {
+			return pi > pj
+		}
+		return pks.seq[cands[i].born] > pks.seq[cands[j].born]
+	})
+	indices := make([]int, 0, len(cands))
+	for _, pk := range cands {
+		if opts.MaxPeaks > 0 && len(indices) == opts.MaxPeaks {
+			break
+		}
+		separated := true
+		for _, i := range indices {
+			if d := pk.born - i; d < opts.MinSeparation && -d < opts.MinSeparation {
+				separated = false
+				break
+			}
+		}
+		if separated {
+			indices = append(indices, pk.born)
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
+
 /*
 Max returns the index in the original time series `seq` of the peak with the
 highest y-value. See GetIndices for fracOfMaxPersistence.
@@ -159,6 +266,112 @@ func (pks *Peaks) Max(fracOfMaxPersistence float64) int {
 	return biggest
 }
 
+/*
+PeakInfo describes a peak of a time series.
+*/
+type PeakInfo struct {
+	// Index is the index of the peak in the time series
+	Index int
+	// Height is the value of the time series at Index
+	Height float64
+	/*
+		Persistence is the height of the peak above the saddle at which it
+		merged into a higher peak. It is +Inf for the global maximum.
+	*/
+	Persistence float64
+	/*
+		LeftBase and RightBase are the indices of the first and last samples of
+		the region of the peak when it merged into a higher peak, or of the
+		time series for the global maximum.
+	*/
+	LeftBase, RightBase int
+}
+
+/*
+All returns the descriptions of all the peaks in increasing order of their
+indices.
+*/
+func (pks *Peaks) All() []PeakInfo {
+	info := make([]PeakInfo, len(pks.peaks))
+	for i, pk := range pks.peaks {
+		info[i] = PeakInfo{
+			Index:       pk.born,
+			Height:      pks.height(pk.born),
+			Persistence: pk.getPersistence(pks.seq),
+			LeftBase:    pk.left,
+			RightBase:   pk.right,
+		}
+	}
+	return info
+}
+
+/*
+PersistencePair is a point of the persistence diagram of a time series: the
+peak at Index is born at value Birth and dies at value Death of the saddle at
+which it merges into a higher peak.
+*/
+type PersistencePair struct {
+	Index        int
+	Birth, Death float64
+}
+
+/*
+Diagram returns the persistence diagram of the peaks in increasing order of
+their indices. The global maximum never dies; its Death is the minimum of the
+time series. The values of valleys are values of the original time series, so
+that Birth <= Death.
+*/
+func (pks *Peaks) Diagram() []PersistencePair {
+	min := 0
+	for i, f := range pks.seq {
+		if f < pks.seq[min] {
+			min = i
+		}
+	}
+	pairs := make([]PersistencePair, len(pks.peaks))
+	for i, pk := range pks.peaks {
+		died := pk.died
+		if died == none {
+			died = min
+		}
+		pairs[i] = PersistencePair{Index: pk.born, Birth: pks.height(pk.born), Death: pks.height(died)}
+	}
+	return pairs
+}
+
+/*
+WriteDiagram writes the persistence diagram of pks to the CSV file fname with
+columns index, birth, death and persistence, e.g. for plotting death against
+birth. The function panics if the file cannot be written.
+*/
+func WriteDiagram(pks *Peaks, fname string) {
+	buf := new(bytes.Buffer)
+	if err := WriteDiagramTo(buf, pks); err != nil {
+		panic(err)
+	}
+	if err := myioutil.WriteFile(fname, buf.Bytes()); err != nil {
+		panic(err)
+	}
+}
+
+// WriteDiagramTo writes the persistence diagram of pks to w in the format of WriteDiagram.
+func WriteDiagramTo(w io.Writer, pks *Peaks) error {
+	pairs := pks.Diagram()
+	X := make([][]float64, len(pairs))
+	for i, p := range pairs {
+		X[i] = []float64{float64(p.Index), p.Birth, p.Death, math.Abs(p.Birth - p.Death)}
+	}
+	return godsp.WriteFloatMatrixCSVTo(w, []string{"index", "birth", "death", "persistence"}, X, godsp.CSVOptions{})
+}
+
+// height returns the value of the original time series at i
+func (pks *Peaks) height(i int) float64 {
+	if pks.valleys {
+		return -pks.seq[i]
+	}
+	return pks.seq[i]
+}
+
 /*
 MinMaxPersistence returns the minimum and maximum persistence of the peaks in `seq`.
 */
@@ -175,3 +388,11 @@ func (pks *Peaks) MinMaxPersistence() (min, max float64) {
 	}
 	return
 }
+
+func negate(x []float64) []float64 {
+	neg := make([]float64, len(x))
+	for i, f := range x {
+		neg[i] = -f
+	}
+	return neg
+}
@@ -0,0 +1,194 @@
+package ppeaks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/goccmack/godsp/internal/benchdata"
+)
+
+func TestGetIndicesWith(t *testing.T) {
+	seq := []float64{0, 3, 1, 2, 0, 5, 1, 4, 0}
+	height := func(h float64) *float64 { return &h }
+	for _, tc := range []struct {
+		pks  *Peaks
+		opts IndexOptions
+		want []int
+	}{
+		{GetPeaks(seq), IndexOptions{}, []int{1, 3, 5, 7}},
+		{GetPeaks(seq), IndexOptions{MinSeparation: 3}, []int{1, 5}},
+		{GetPeaks(seq), IndexOptions{MaxPeaks: 2}, []int{5, 7}},
+		{GetPeaks(seq), IndexOptions{MinHeight: height(2.5)}, []int{1, 5, 7}},
+		{GetPeaks(seq), IndexOptions{MinPersistence: 3}, []int{1, 5, 7}},
+		// A minimum height of 0 is not ignored
+		{GetPeaks([]float64{-3, -1, -2, 1, -2}), IndexOptions{MinHeight: height(0)}, []int{3}},
+		{GetPeaks([]float64{-3, -1, -2, 1, -2}), IndexOptions{}, []int{1, 3}},
+		// For valleys the height is the value of seq
+		{GetValleys(seq), IndexOptions{}, []int{0, 2, 4, 6, 8}},
+		{GetValleys(seq), IndexOptions{MinHeight: height(1)}, []int{2, 6}},
+	} {
+		got := tc.pks.GetIndicesWith(tc.opts)
+		if len(got) != len(tc.want) {
+			t.Errorf("%+v: %v, want %v", tc.opts, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%+v: %v, want %v", tc.opts, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	/*
+		The peak at 3 merges into the peak at 1 at the saddle at 2, which
+		merges into the global maximum at 5 at the saddle at 4.
+	*/
+	pks := GetPeaks([]float64{0, 3, 1, 2, -1, 5, 1})
+	want := []struct {
+		info PeakInfo
+		died int
+	}{
+		{PeakInfo{Index: 1, Height: 3, Persistence: 4, LeftBase: 0, RightBase: 3}, 4},
+		{PeakInfo{Index: 3, Height: 2, Persistence: 1, LeftBase: 3, RightBase: 3}, 2},
+		{PeakInfo{Index: 5, Height: 5, Persistence: math.Inf(1), LeftBase: 0, RightBase: 6}, none},
+	}
+	all := pks.All()
+	if len(all) != len(want) {
+		t.Fatalf("All() = %+v", all)
+	}
+	for i, w := range want {
+		if all[i] != w.info {
+			t.Errorf("peak %d = %+v, want %+v", i, all[i], w.info)
+		}
+		if pks.peaks[i].born != w.info.Index || pks.peaks[i].died != w.died {
+			t.Errorf("peak %d born %d, died %d, want %d, %d", i, pks.peaks[i].born, pks.peaks[i].died, w.info.Index, w.died)
+		}
+	}
+	if min, max := pks.MinMaxPersistence(); min != 1 || max != 4 {
+		t.Errorf("MinMaxPersistence = %f, %f", min, max)
+	}
+}
+
+func TestGetValleys(t *testing.T) {
+	// The valley at 5 is the left end of a plateau and the valley at 8 is at the edge
+	seq := []float64{3, 1, 2, 0, 4, 2, 2, 5, 1}
+	want := []PeakInfo{
+		{Index: 1, Height: 1, Persistence: 1, LeftBase: 1, RightBase: 1},
+		{Index: 3, Height: 0, Persistence: math.Inf(1), LeftBase: 0, RightBase: 8},
+		{Index: 5, Height: 2, Persistence: 2, LeftBase: 5, RightBase: 6},
+		{Index: 8, Height: 1, Persistence: 4, LeftBase: 8, RightBase: 8},
+	}
+	got := GetValleys(seq).All()
+	if len(got) != len(want) {
+		t.Fatalf("valleys = %+v", got)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("valley %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if i := GetValleys(seq).Max(0); i != 3 {
+		t.Errorf("Max = %d, want 3", i)
+	}
+	// GetValleysInt scales the values of the time series by godsp.ToFloat
+	got = GetValleysInt([]int{3, 1, 2, 0, 4, 2, 2, 5, 1}).All()
+	if len(got) != len(want) {
+		t.Fatalf("int valleys = %+v", got)
+	}
+	for i := range got {
+		if got[i].Index != want[i].Index || got[i].LeftBase != want[i].LeftBase || got[i].RightBase != want[i].RightBase {
+			t.Errorf("int valley %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	// The valleys of x are the peaks of -x with the heights of x
+	x := benchdata.Signal(1000)
+	vls, pks := GetValleys(x).All(), GetPeaks(negate(x)).All()
+	if len(vls) != len(pks) {
+		t.Fatalf("%d valleys, %d peaks", len(vls), len(pks))
+	}
+	for i, v := range vls {
+		pk := pks[i]
+		pk.Height = -pk.Height
+		if v != pk {
+			t.Errorf("valley %d = %+v, peak = %+v", i, v, pks[i])
+		}
+	}
+}
+
+func TestGetPeaks2D(t *testing.T) {
+	M := [][]float64{
+		{0, 0, 0, 0, 0},
+		{0, 5, 0, 0, 0},
+		{0, 0, 1, 0, 0},
+		{0, 0, 0, 3, 0},
+		{0, 0, 0, 0, 2},
+		{0, 4, 4, 0, 0},
+	}
+	pks := GetPeaks2D(M, Options2D{})
+	want := []Peak2D{
+		{Row: 1, Col: 1, Value: 5, Persistence: math.Inf(1)},
+		{Row: 5, Col: 1, Value: 4, Persistence: 4},
+		{Row: 3, Col: 3, Value: 3, Persistence: 2},
+	}
+	if len(pks) != len(want) {
+		t.Fatalf("pks = %v", pks)
+	}
+	for i := range pks {
+		if pks[i] != want[i] {
+			t.Errorf("pks[%d] = %+v, want %+v", i, pks[i], want[i])
+		}
+	}
+	if pks := GetPeaks2D(M, Options2D{MinPersistence: 3}); len(pks) != 2 {
+		t.Errorf("MinPersistence: %v", pks)
+	}
+	if pks := GetPeaks2D(M, Options2D{Neighborhood: 2}); len(pks) != 2 {
+		t.Errorf("Neighborhood: %v", pks)
+	}
+}
+
+func TestDiagram(t *testing.T) {
+	pks := GetPeaks([]float64{0, 3, 1, 2, -1, 5, 1})
+	want := []PersistencePair{{1, 3, -1}, {3, 2, 1}, {5, 5, -1}}
+	for i, p := range pks.Diagram() {
+		if p != want[i] {
+			t.Errorf("pair %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+	buf := new(bytes.Buffer)
+	WriteDiagramTo(buf, GetValleys([]float64{2, 0, 1}))
+	if buf.String() != "index,birth,death,persistence\n1,0,2,2\n" {
+		t.Errorf("valley diagram = %q", buf)
+	}
+}
+
+func TestGetPeaksContext(t *testing.T) {
+	seq := []float64{0, 3, 1, 5, 2, 4, 0}
+	pks, err := GetPeaksContext(context.Background(), seq)
+	if err != nil || len(pks.All()) != len(GetPeaks(seq).All()) {
+		t.Errorf("GetPeaksContext: err = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetPeaksContext(ctx, seq); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetPeaksContext: err = %v", err)
+	}
+}
+
+func BenchmarkGetPeaks(b *testing.B) {
+	for _, n := range benchdata.Sizes {
+		x := benchdata.Envelope(n, 441)
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				GetPeaks(x)
+			}
+		})
+	}
+}
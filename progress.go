@@ -0,0 +1,114 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+/*
+ProgressFunc is called by long-running operations with the amount of work done
+out of total, e.g. bytes read or vectors processed. Calls are serialized and
+done does not decrease. The last call has done == total when the operation
+completes.
+*/
+type ProgressFunc func(done, total int)
+
+/*
+WithProgress sets the function that is called after each vector of a set is
+processed, or as an audio file is read, with the number of vectors or bytes
+done.
+*/
+func WithProgress(f ProgressFunc) Option {
+	return func(o *options) {
+		o.progress = f
+	}
+}
+
+/*
+NewProgressReader returns a reader that reads from r and calls progress with
+the number of bytes read so far and total after each read.
+*/
+func NewProgressReader(r io.Reader, total int, progress ProgressFunc) io.Reader {
+	return &progressReader{r: r, total: total, progress: progress}
+}
+
+type progressReader struct {
+	r        io.Reader
+	done     int
+	total    int
+	progress ProgressFunc
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.done += n
+		pr.progress(pr.done, pr.total)
+	}
+	return n, err
+}
+
+// readFile returns the contents of the file fname and reports the bytes read to o.progress
+func readFile(fname string, o *options) ([]byte, error) {
+	if o.progress == nil {
+		return ioutil.ReadFile(fname)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := progressFile(f, o)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// progressFile returns f or, if o has a progress function, a reader of f that reports the bytes read
+func progressFile(f *os.File, o *options) (io.Reader, error) {
+	if o.progress == nil {
+		return f, nil
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return NewProgressReader(f, int(info.Size()), o.progress), nil
+}
+
+/*
+mapAll returns ParallelMap(xs, o.workers, f) and reports the number of
+vectors processed to o.progress.
+*/
+func mapAll[T, U any](xs []T, o *options, f func(T) U) []U {
+	if o.progress == nil {
+		return ParallelMap(xs, o.workers, f)
+	}
+	var mu sync.Mutex
+	done := 0
+	return ParallelMap(xs, o.workers, func(x T) U {
+		y := f(x)
+		mu.Lock()
+		done++
+		o.progress(done, len(xs))
+		mu.Unlock()
+		return y
+	})
+}
@@ -0,0 +1,209 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+RingBuffer is a first-in first-out buffer of samples with a fixed capacity.
+When the buffer is full, Push overwrites the oldest samples.
+*/
+type RingBuffer struct {
+	buf      []float64
+	start, n int
+}
+
+/*
+NewRingBuffer returns an empty ring buffer of capacity samples.
+The function panics if capacity < 1.
+*/
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		panic(fmt.Errorf("%w: capacity = %d", ErrArgument, capacity))
+	}
+	return &RingBuffer{buf: make([]float64, capacity)}
+}
+
+// Push appends x to the buffer. The oldest samples are overwritten if the buffer is full.
+func (r *RingBuffer) Push(x ...float64) {
+	for _, f := range x {
+		r.buf[(r.start+r.n)%len(r.buf)] = f
+		if r.n < len(r.buf) {
+			r.n++
+		} else {
+			r.start = (r.start + 1) % len(r.buf)
+		}
+	}
+}
+
+/*
+Read removes the oldest min(len(dst), r.Len()) samples from the buffer, copies
+them to dst and returns their number.
+*/
+func (r *RingBuffer) Read(dst []float64) int {
+	n := len(dst)
+	if n > r.n {
+		n = r.n
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+	r.start = (r.start + n) % len(r.buf)
+	r.n -= n
+	return n
+}
+
+/*
+At returns sample i of the buffer, where 0 is the oldest sample.
+The function panics if i is not in [0,r.Len()).
+*/
+func (r *RingBuffer) At(i int) float64 {
+	if i < 0 || i >= r.n {
+		panic(fmt.Errorf("%w: index %d of %d samples", ErrArgument, i, r.n))
+	}
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// Slice returns a copy of the samples of the buffer from the oldest to the newest
+func (r *RingBuffer) Slice() []float64 {
+	s := make([]float64, r.n)
+	for i := range s {
+		s[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+	return s
+}
+
+// Len returns the number of samples in the buffer
+func (r *RingBuffer) Len() int {
+	return r.n
+}
+
+// Cap returns the capacity of the buffer
+func (r *RingBuffer) Cap() int {
+	return len(r.buf)
+}
+
+// Reset empties the buffer
+func (r *RingBuffer) Reset() {
+	r.start, r.n = 0, 0
+}
+
+// Interpolation selects the interpolation of the fractional delay of a DelayLine
+type Interpolation int
+
+const (
+	/*
+		LinearInterpolation interpolates linearly between the two samples
+		around the delay. It attenuates high frequencies for fractional delays.
+	*/
+	LinearInterpolation Interpolation = iota
+	/*
+		AllpassInterpolation interpolates by a first order allpass filter with
+		unit gain at all frequencies and the fractional delay at low
+		frequencies. It suits delays that change slowly, such as those of
+		feedback loops and modulation effects.
+	*/
+	AllpassInterpolation
+)
+
+/*
+DelayLine delays a stream of samples by a fractional number of samples.
+*/
+type DelayLine struct {
+	buf    []float64
+	pos    int
+	interp Interpolation
+	// The integer and fractional parts of the delay
+	delay int
+	frac  float64
+	// The allpass coefficient and state
+	eta, prevIn, prevOut float64
+}
+
+/*
+NewDelayLine returns a delay line for delays up to maxDelay samples with
+interpolation interp. The delay is 0 until it is set by SetDelay.
+The function panics if maxDelay < 1.
+*/
+func NewDelayLine(maxDelay int, interp Interpolation) *DelayLine {
+	if maxDelay < 1 {
+		panic(fmt.Errorf("%w: maxDelay = %d", ErrArgument, maxDelay))
+	}
+	return &DelayLine{buf: make([]float64, maxDelay+2), interp: interp}
+}
+
+/*
+SetDelay sets the delay in samples.
+The function panics if delay is not in [0,maxDelay].
+*/
+func (d *DelayLine) SetDelay(delay float64) {
+	if !(delay >= 0 && delay <= float64(len(d.buf)-2)) {
+		panic(fmt.Errorf("%w: delay %f not in [0,%d]", ErrArgument, delay, len(d.buf)-2))
+	}
+	d.delay = int(math.Floor(delay))
+	d.frac = delay - float64(d.delay)
+	if d.interp == AllpassInterpolation && d.frac < 0.1 && d.delay > 0 {
+		// The allpass pole approaches the unit circle as frac approaches 0
+		d.delay--
+		d.frac++
+	}
+	d.eta = (1 - d.frac) / (1 + d.frac)
+}
+
+// Delay returns the delay in samples
+func (d *DelayLine) Delay() float64 {
+	return float64(d.delay) + d.frac
+}
+
+// Process writes x to the delay line and returns the delayed sample
+func (d *DelayLine) Process(x float64) float64 {
+	d.buf[d.pos] = x
+	x0 := d.tap(d.delay)
+	var y float64
+	switch d.interp {
+	case AllpassInterpolation:
+		y = d.eta*x0 + d.prevIn - d.eta*d.prevOut
+		d.prevIn, d.prevOut = x0, y
+	default:
+		y = (1-d.frac)*x0 + d.frac*d.tap(d.delay+1)
+	}
+	d.pos = (d.pos + 1) % len(d.buf)
+	return y
+}
+
+// ProcessBlock returns the delayed samples of the block x
+func (d *DelayLine) ProcessBlock(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for i, f := range x {
+		y[i] = d.Process(f)
+	}
+	return y
+}
+
+// Reset clears the samples and state of the delay line. The delay is unchanged.
+func (d *DelayLine) Reset() {
+	for i := range d.buf {
+		d.buf[i] = 0
+	}
+	d.pos, d.prevIn, d.prevOut = 0, 0, 0
+}
+
+// tap returns the sample written k samples before the last sample
+func (d *DelayLine) tap(k int) float64 {
+	return d.buf[(d.pos-k+len(d.buf))%len(d.buf)]
+}
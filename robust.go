@@ -0,0 +1,95 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// madScale scales the median absolute deviation to the standard deviation of Gaussian noise
+const madScale = 1.4826
+
+/*
+MedianFilter returns the running median of x over windows of window samples
+centred on each x[i]. Near the ends of x the window is truncated to the
+samples in x.
+The function panics if window is not a positive odd number.
+*/
+func MedianFilter(x []float64, window int) []float64 {
+	checkOddWindow(window)
+	y := make([]float64, len(x))
+	buf := make([]float64, 0, window)
+	for i := range x {
+		y[i] = median(append(buf[:0], x[clip(i-window/2, len(x)):clip(i+window/2+1, len(x))]...))
+	}
+	return y
+}
+
+/*
+Hampel returns x with its outliers replaced by the running median. x[i] is an
+outlier if it differs from the median of the window of window samples centred
+on x[i] by more than nSigmas times the scaled median absolute deviation (MAD)
+of the window. The MAD is scaled by 1.4826 to estimate the standard deviation
+of Gaussian noise. Near the ends of x the window is truncated to the samples
+in x.
+The function panics if window is not a positive odd number.
+*/
+func Hampel(x []float64, window int, nSigmas float64) []float64 {
+	checkOddWindow(window)
+	y := make([]float64, len(x))
+	buf := make([]float64, 0, window)
+	for i, f := range x {
+		wdw := append(buf[:0], x[clip(i-window/2, len(x)):clip(i+window/2+1, len(x))]...)
+		med := median(wdw)
+		for j, g := range wdw {
+			wdw[j] = math.Abs(g - med)
+		}
+		if mad := madScale * median(wdw); math.Abs(f-med) > nSigmas*mad {
+			y[i] = med
+		} else {
+			y[i] = f
+		}
+	}
+	return y
+}
+
+// median returns the median of x. x is sorted in place.
+func median(x []float64) float64 {
+	sort.Float64s(x)
+	n := len(x)
+	if n%2 == 1 {
+		return x[n/2]
+	}
+	return (x[n/2-1] + x[n/2]) / 2
+}
+
+// clip returns i clipped to [0,n]
+func clip(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+func checkOddWindow(window int) {
+	if window < 1 || window%2 == 0 {
+		panic(fmt.Errorf("%w: window %d is not a positive odd number", ErrArgument, window))
+	}
+}
@@ -0,0 +1,149 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+RunningStats accumulates the statistics of a stream of samples in one pass
+without buffering the samples. The mean and variance are updated by Welford's
+algorithm. The zero value is an empty accumulator.
+*/
+type RunningStats struct {
+	n        int
+	mean, m2 float64
+	min, max float64
+}
+
+// Push adds x to the statistics
+func (s *RunningStats) Push(x float64) {
+	s.n++
+	if s.n == 1 {
+		s.min, s.max = x, x
+	} else {
+		s.min, s.max = math.Min(s.min, x), math.Max(s.max, x)
+	}
+	delta := x - s.mean
+	s.mean += delta / float64(s.n)
+	s.m2 += delta * (x - s.mean)
+}
+
+// PushAll adds all x to the statistics
+func (s *RunningStats) PushAll(x []float64) {
+	for _, f := range x {
+		s.Push(f)
+	}
+}
+
+// Count returns the number of samples pushed
+func (s *RunningStats) Count() int {
+	return s.n
+}
+
+// Mean returns the mean of the samples pushed, or 0 if none were pushed
+func (s *RunningStats) Mean() float64 {
+	return s.mean
+}
+
+/*
+Var returns the population variance of the samples pushed, or 0 if none were
+pushed.
+*/
+func (s *RunningStats) Var() float64 {
+	if s.n == 0 {
+		return 0
+	}
+	return s.m2 / float64(s.n)
+}
+
+// Std returns the population standard deviation of the samples pushed
+func (s *RunningStats) Std() float64 {
+	return math.Sqrt(s.Var())
+}
+
+// Min returns the minimum of the samples pushed, or 0 if none were pushed
+func (s *RunningStats) Min() float64 {
+	return s.min
+}
+
+// Max returns the maximum of the samples pushed, or 0 if none were pushed
+func (s *RunningStats) Max() float64 {
+	return s.max
+}
+
+// Reset empties the accumulator
+func (s *RunningStats) Reset() {
+	*s = RunningStats{}
+}
+
+/*
+RunningMovAvg is the causal moving average of a stream of samples over a
+fixed window.
+*/
+type RunningMovAvg struct {
+	buf  []float64
+	next int
+	full bool
+	sum  float64
+}
+
+/*
+NewRunningMovAvg returns a moving average over window samples.
+The function panics if window < 1.
+*/
+func NewRunningMovAvg(window int) *RunningMovAvg {
+	if window < 1 {
+		panic(fmt.Errorf("%w: window = %d", ErrArgument, window))
+	}
+	return &RunningMovAvg{buf: make([]float64, window)}
+}
+
+/*
+Push adds x to the window and returns the average of the last window samples,
+or of all samples pushed if fewer than window samples were pushed.
+*/
+func (m *RunningMovAvg) Push(x float64) float64 {
+	m.sum += x - m.buf[m.next]
+	m.buf[m.next] = x
+	m.next++
+	if m.next == len(m.buf) {
+		m.next, m.full = 0, true
+	}
+	return m.Avg()
+}
+
+/*
+Avg returns the current average of the window, or 0 if no samples were pushed.
+*/
+func (m *RunningMovAvg) Avg() float64 {
+	if m.full {
+		return m.sum / float64(len(m.buf))
+	}
+	if m.next == 0 {
+		return 0
+	}
+	return m.sum / float64(m.next)
+}
+
+// Reset empties the window
+func (m *RunningMovAvg) Reset() {
+	for i := range m.buf {
+		m.buf[i] = 0
+	}
+	m.next, m.full, m.sum = 0, false, 0
+}
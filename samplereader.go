@@ -0,0 +1,152 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+SampleReader gives random access to the samples of a signal, which need not be
+in memory, e.g. a channel of a memory-mapped wav file.
+*/
+type SampleReader interface {
+	// Len returns the number of samples of the signal
+	Len() int
+	/*
+		ReadSamplesAt copies the samples of the signal starting at sample off to
+		dst and returns the number of samples copied. If it copies fewer than
+		len(dst) samples it returns an error, which is io.EOF at the end of the
+		signal.
+	*/
+	ReadSamplesAt(dst []float64, off int) (int, error)
+}
+
+// NewSliceReader returns a SampleReader of the samples of x
+func NewSliceReader(x []float64) SampleReader {
+	return sliceReader(x)
+}
+
+type sliceReader []float64
+
+func (x sliceReader) Len() int {
+	return len(x)
+}
+
+func (x sliceReader) ReadSamplesAt(dst []float64, off int) (int, error) {
+	return readSamplesAt(len(x), dst, off, func(i int) float64 { return x[i] })
+}
+
+/*
+readSamplesAt copies sample(off), sample(off+1), ... of a signal of n samples to
+dst and returns the number of samples copied and io.EOF if it is less than
+len(dst).
+*/
+func readSamplesAt(n int, dst []float64, off int, sample func(i int) float64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: offset %d", ErrArgument, off)
+	}
+	i := 0
+	for ; i < len(dst) && off+i < n; i++ {
+		dst[i] = sample(off + i)
+	}
+	if i < len(dst) {
+		return i, io.EOF
+	}
+	return i, nil
+}
+
+/*
+WavMap is a memory-mapped wav file. Its samples are decoded on demand from the
+mapping, so that files larger than memory can be processed, e.g. by the
+iterator of FramesFrom. On platforms without mmap the file is read into memory.
+*/
+type WavMap struct {
+	mapping []byte
+	w       *wavFile
+	n       int
+	sample  func(i int) float64
+}
+
+/*
+MapWavFile returns the memory-mapped wav file fname or the error mapping or
+parsing the file. The samples are decoded as by ReadWavFile. The WavMap must be
+closed to unmap the file.
+*/
+func MapWavFile(fname string) (*WavMap, error) {
+	mapping, err := mmapFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	m := &WavMap{mapping: mapping}
+	if m.w, err = parseWav(mapping); err == nil {
+		m.n, m.sample, err = wavSampleDecoder(m.w.data, m.w.audioFormat, m.w.bitsPerSample)
+	}
+	if err != nil {
+		munmap(mapping)
+		return nil, err
+	}
+	return m, nil
+}
+
+// Format returns the sample rate in Hz, number of channels and bits per sample of the file
+func (m *WavMap) Format() (sampleRate, numChannels, bitsPerSample int) {
+	return m.w.sampleRate, m.w.numChannels, m.w.bitsPerSample
+}
+
+// Metadata returns the chunks of the file other than the samples
+func (m *WavMap) Metadata() *Metadata {
+	return m.w.meta
+}
+
+/*
+Channel returns a SampleReader of channel c of the file. A trailing incomplete
+frame is dropped.
+The function panics if c is not in [0,numChannels).
+*/
+func (m *WavMap) Channel(c int) SampleReader {
+	if c < 0 || c >= m.w.numChannels {
+		panic(fmt.Errorf("%w: channel %d of %d", ErrArgument, c, m.w.numChannels))
+	}
+	return &wavChannel{m, c}
+}
+
+/*
+Close unmaps the file. The SampleReaders of the channels must not be used after
+Close.
+*/
+func (m *WavMap) Close() error {
+	mapping := m.mapping
+	m.mapping = nil
+	return munmap(mapping)
+}
+
+// wavChannel is a channel of a WavMap
+type wavChannel struct {
+	m *WavMap
+	c int
+}
+
+func (ch *wavChannel) Len() int {
+	return ch.m.n / ch.m.w.numChannels
+}
+
+func (ch *wavChannel) ReadSamplesAt(dst []float64, off int) (int, error) {
+	numChannels := ch.m.w.numChannels
+	return readSamplesAt(ch.Len(), dst, off, func(i int) float64 {
+		return ch.m.sample(i*numChannels + ch.c)
+	})
+}
@@ -0,0 +1,123 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+SavitzkyGolay returns x smoothed, or its derivOrder'th derivative, by
+Savitzky-Golay filtering: each y[i] is the value, or derivative, at x[i] of the
+least squares polynomial of degree polyOrder fitted to the window of window
+samples centred on x[i]. Derivatives are per sample; divide by dt^derivOrder
+for the derivative in time. Near the ends of x the polynomial is fitted to the
+first or last window of x. The filter is symmetric and has no phase distortion.
+The function panics if window is not a positive odd number,
+polyOrder >= window, derivOrder > polyOrder or len(x) < window.
+*/
+func SavitzkyGolay(x []float64, window, polyOrder, derivOrder int) []float64 {
+	checkOddWindow(window)
+	if polyOrder < 0 || polyOrder >= window || derivOrder < 0 || derivOrder > polyOrder {
+		panic(fmt.Errorf("%w: window %d, polyOrder %d, derivOrder %d",
+			ErrArgument, window, polyOrder, derivOrder))
+	}
+	if len(x) < window {
+		panic(fmt.Errorf("%w: len(x) (%d) < window (%d)", ErrLength, len(x), window))
+	}
+	h := window / 2
+	fit := savgolFit(window, polyOrder)
+	y := make([]float64, len(x))
+	// Centre of x
+	c := savgolCoefficients(fit, h, derivOrder)
+	for i := h; i < len(x)-h; i++ {
+		y[i] = Dot(c, x[i-h:i+h+1])
+	}
+	// Ends of x
+	for t := 0; t < h; t++ {
+		c := savgolCoefficients(fit, t, derivOrder)
+		y[t] = Dot(c, x[:window])
+		c = savgolCoefficients(fit, window-1-t, derivOrder)
+		y[len(x)-1-t] = Dot(c, x[len(x)-window:])
+	}
+	return y
+}
+
+/*
+savgolFit returns the (polyOrder+1) x window matrix F = (A^T A)^-1 A^T where
+A[m][j] = (m-window/2)^j. F x is the vector of coefficients of the least squares
+polynomial fitted to the window x.
+*/
+func savgolFit(window, polyOrder int) [][]float64 {
+	n, h := polyOrder+1, window/2
+	// M = [A^T A | A^T]
+	M := make([][]float64, n)
+	for j := range M {
+		M[j] = make([]float64, n+window)
+		for m := 0; m < window; m++ {
+			M[j][n+m] = math.Pow(float64(m-h), float64(j))
+		}
+		for k := 0; k < n; k++ {
+			for m := 0; m < window; m++ {
+				M[j][k] += math.Pow(float64(m-h), float64(j+k))
+			}
+		}
+	}
+	// Gauss-Jordan elimination with partial pivoting
+	for col := 0; col < n; col++ {
+		p := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(M[r][col]) > math.Abs(M[p][col]) {
+				p = r
+			}
+		}
+		M[col], M[p] = M[p], M[col]
+		DivSTo(M[col], M[col], M[col][col])
+		for r := range M {
+			if r != col && M[r][col] != 0 {
+				f := M[r][col]
+				for k := range M[r] {
+					M[r][k] -= f * M[col][k]
+				}
+			}
+		}
+	}
+	F := make([][]float64, n)
+	for j := range F {
+		F[j] = M[j][n:]
+	}
+	return F
+}
+
+/*
+savgolCoefficients returns the filter coefficients that evaluate the
+derivOrder'th derivative of the fitted polynomial at window position t.
+*/
+func savgolCoefficients(F [][]float64, t, derivOrder int) []float64 {
+	tc := float64(t - len(F[0])/2)
+	c := make([]float64, len(F[0]))
+	for j := derivOrder; j < len(F); j++ {
+		// d^derivOrder/dt^derivOrder t^j = j!/(j-derivOrder)! t^(j-derivOrder)
+		f := math.Pow(tc, float64(j-derivOrder))
+		for k := j - derivOrder + 1; k <= j; k++ {
+			f *= float64(k)
+		}
+		for m := range c {
+			c[m] += f * F[j][m]
+		}
+	}
+	return c
+}
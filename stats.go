@@ -0,0 +1,101 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+Variance returns the population variance of x: the average of the squared
+deviations of x from its average.
+*/
+func Variance[T Float](x []T) T {
+	if len(x) == 0 {
+		return 0
+	}
+	return T(centralMoment(x, 2))
+}
+
+// Std returns the population standard deviation of x
+func Std[T Float](x []T) T {
+	return T(math.Sqrt(float64(Variance(x))))
+}
+
+/*
+Median returns the median of x. x is not modified.
+The function panics if x is empty.
+*/
+func Median[T Float](x []T) T {
+	return Percentile(x, 50)
+}
+
+/*
+Percentile returns the p'th percentile of x, 0 <= p <= 100, interpolated
+linearly between the closest ranks of the sorted x. x is not modified.
+The function panics if x is empty or p is outside [0,100].
+*/
+func Percentile[T Float](x []T, p float64) T {
+	if len(x) == 0 || p < 0 || p > 100 {
+		panic(fmt.Errorf("%w: len(x) %d, p %f", ErrArgument, len(x), p))
+	}
+	y := Convert[float64](x)
+	sort.Float64s(y)
+	r := p / 100 * float64(len(y)-1)
+	i := int(r)
+	if i == len(y)-1 {
+		return T(y[i])
+	}
+	return T(y[i] + (r-float64(i))*(y[i+1]-y[i]))
+}
+
+/*
+Skewness returns the population skewness of x: m3/m2^1.5, where mk is the
+k'th central moment of x. The skewness of x with zero variance is 0.
+*/
+func Skewness[T Float](x []T) T {
+	m2 := centralMoment(x, 2)
+	if m2 == 0 {
+		return 0
+	}
+	return T(centralMoment(x, 3) / math.Pow(m2, 1.5))
+}
+
+/*
+Kurtosis returns the excess kurtosis of x: m4/m2^2 - 3, where mk is the k'th
+central moment of x. The excess kurtosis of a Gaussian is 0. The kurtosis of x
+with zero variance is 0.
+*/
+func Kurtosis[T Float](x []T) T {
+	m2 := centralMoment(x, 2)
+	if m2 == 0 {
+		return 0
+	}
+	return T(centralMoment(x, 4)/(m2*m2) - 3)
+}
+
+// centralMoment returns the k'th central moment of x
+func centralMoment[T Float](x []T, k int) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	avg, sum := float64(Average(x)), 0.0
+	for _, f := range x {
+		sum += math.Pow(float64(f)-avg, float64(k))
+	}
+	return sum / float64(len(x))
+}
@@ -0,0 +1,194 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+/*
+Window returns a window function of length N. A nil Window is a rectangular
+window.
+*/
+type Window func(N int) []float64
+
+/*
+STFT returns the short-time Fourier transform of x. Frame i starts at sample
+i*hop and has length frameLen. Each frame is multiplied by window before it is
+transformed. Only the frameLen/2+1 non-negative frequency bins of each frame
+are returned, where bin k has frequency k*sampleRate/frameLen.
+A tail of x that does not fill a frame is not transformed.
+The function panics if frameLen < 1 or hop < 1.
+*/
+func STFT(x []float64, frameLen, hop int, window Window) [][]complex128 {
+	frames, err := STFTErr(x, frameLen, hop, window)
+	if err != nil {
+		panic(err)
+	}
+	return frames
+}
+
+/*
+STFTErr returns STFT(x, frameLen, hop, window) or an error wrapping ErrArgument
+if frameLen < 1 or hop < 1.
+*/
+func STFTErr(x []float64, frameLen, hop int, window Window) ([][]complex128, error) {
+	if err := checkFrames(frameLen, hop, 1); err != nil {
+		return nil, err
+	}
+	var w []float64
+	if window != nil {
+		w = window(frameLen)
+	}
+	it := Frames(x, frameLen, hop, WithZeroCopy())
+	frames := make([][]complex128, it.Len())
+	frame := make([]complex128, frameLen)
+	for it.Next() {
+		for j, f := range it.Frame() {
+			if w != nil {
+				frame[j] = complex(f*w[j], 0)
+			} else {
+				frame[j] = complex(f, 0)
+			}
+		}
+		frames[it.Index()] = FFT(frame)[:frameLen/2+1]
+	}
+	return frames, nil
+}
+
+/*
+ISTFT returns the inverse of the short-time Fourier transform frames of STFT
+with the same frameLen, hop and window. Each frame is inverse transformed,
+multiplied by window and overlap-added at i*hop. Each sample of the sum is
+divided by the sum of the squared windows that overlap it, so that
+ISTFT(STFT(x)) reconstructs x for any window that covers every sample, even if
+the window does not satisfy the COLA constraint. The frames may be modified
+before the inverse transform, e.g. by masking, in which case the result is the
+signal whose STFT is closest to the modified frames in the least squares sense.
+The result has length (len(frames)-1)*hop + frameLen. Samples not covered by a
+non-zero window value are zero.
+The function panics if frameLen < 1, hop < 1 or a frame does not have
+frameLen/2+1 bins.
+*/
+func ISTFT(frames [][]complex128, frameLen, hop int, window Window) []float64 {
+	x, err := ISTFTErr(frames, frameLen, hop, window)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+/*
+ISTFTErr returns ISTFT(frames, frameLen, hop, window) or an error wrapping
+ErrArgument if frameLen < 1 or hop < 1 or ErrLength if a frame does not have
+frameLen/2+1 bins.
+*/
+func ISTFTErr(frames [][]complex128, frameLen, hop int, window Window) ([]float64, error) {
+	if err := checkFrames(frameLen, hop, 1); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return []float64{}, nil
+	}
+	w := constant(frameLen, 1)
+	if window != nil {
+		w = window(frameLen)
+	}
+	x := make([]float64, (len(frames)-1)*hop+frameLen)
+	wsum := make([]float64, len(x))
+	spectrum := make([]complex128, frameLen)
+	for i, frame := range frames {
+		if len(frame) != frameLen/2+1 {
+			return nil, fmt.Errorf("%w: frame %d has %d bins, want %d", ErrLength, i, len(frame), frameLen/2+1)
+		}
+		// Restore the negative frequencies of the real frame
+		copy(spectrum, frame)
+		for k := len(frame); k < frameLen; k++ {
+			spectrum[k] = cmplx.Conj(frame[frameLen-k])
+		}
+		start := i * hop
+		for j, v := range IFFTReal(spectrum) {
+			x[start+j] += v * w[j]
+			wsum[start+j] += w[j] * w[j]
+		}
+	}
+	for i, s := range wsum {
+		if s > 1e-10 {
+			x[i] /= s
+		} else {
+			x[i] = 0
+		}
+	}
+	return x, nil
+}
+
+/*
+COLA reports whether window of length frameLen satisfies the constant
+overlap-add constraint for hop: the sum of the window shifted by multiples of
+hop is equal to the constant sum at every sample. A frame-domain filter
+applied to frames of such a window can be synthesized by simple overlap-add
+with gain sum.
+The function panics if frameLen < 1 or hop < 1.
+*/
+func COLA(window Window, frameLen, hop int) (sum float64, ok bool) {
+	if err := checkFrames(frameLen, hop, 1); err != nil {
+		panic(err)
+	}
+	w := constant(frameLen, 1)
+	if window != nil {
+		w = window(frameLen)
+	}
+	sums := make([]float64, hop)
+	for j, v := range w {
+		sums[j%hop] += v
+	}
+	min, max := sums[0], sums[0]
+	for _, s := range sums {
+		min, max = math.Min(min, s), math.Max(max, s)
+	}
+	return sums[0], max-min <= 1e-10*math.Max(math.Abs(max), 1)
+}
+
+/*
+Spectrogram returns the magnitudes of the STFT of x. See STFT for the layout of
+the frames.
+*/
+func Spectrogram(x []float64, frameLen, hop int, window Window) [][]float64 {
+	frames := STFT(x, frameLen, hop, window)
+	S := make([][]float64, len(frames))
+	for i, frame := range frames {
+		S[i] = make([]float64, len(frame))
+		for k, c := range frame {
+			S[i][k] = cmplx.Abs(c)
+		}
+	}
+	return S
+}
+
+/*
+PowerSpectrogram returns the squared magnitudes of the STFT of x. See STFT for
+the layout of the frames.
+*/
+func PowerSpectrogram(x []float64, frameLen, hop int, window Window) [][]float64 {
+	S := Spectrogram(x, frameLen, hop, window)
+	for _, frame := range S {
+		for k, m := range frame {
+			frame[k] = m * m
+		}
+	}
+	return S
+}
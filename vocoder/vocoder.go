@@ -0,0 +1,157 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package vocoder implements time stretching and pitch shifting by a phase
+vocoder with identity phase locking. The signal is analysed by godsp.STFT with
+a periodic Hann window. The analysis frames are advanced by hop/ratio samples
+and the magnitudes interpolated between frames, while the synthesis frames are
+overlap-added every hop samples. The phases of the spectral peaks are
+accumulated at the instantaneous frequency of each peak, and the other bins
+keep their phase relative to the nearest peak.
+
+See: J. Laroche and M. Dolson. Improved Phase Vocoder Time-Scale Modification
+of Audio. IEEE Transactions on Speech and Audio Processing, 7(3), 1999.
+
+A frameLen of 2048 and hop of frameLen/4 are typical for audio at 44.1 kHz.
+*/
+package vocoder
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/windows"
+)
+
+/*
+TimeStretch returns x stretched in time by ratio without changing its pitch.
+The result has round(len(x)*ratio) samples: ratio > 1 slows x down and
+ratio < 1 speeds it up.
+The function panics if ratio <= 0, frameLen < 4 or hop is not in
+[1,frameLen/2].
+*/
+func TimeStretch(x []float64, ratio float64, frameLen, hop int) []float64 {
+	if ratio <= 0 || frameLen < 4 || hop < 1 || hop > frameLen/2 {
+		panic(fmt.Sprintf("Invalid ratio %f, frameLen %d or hop %d", ratio, frameLen, hop))
+	}
+	outLen := int(math.Round(float64(len(x)) * ratio))
+	if len(x) == 0 {
+		return make([]float64, outLen)
+	}
+	window := windows.Periodic(windows.Hann)
+	w := window(frameLen)
+	// Pad x so that every sample of x is covered by whole frames
+	padded := make([]float64, frameLen/2+len(x)+frameLen+hop)
+	copy(padded[frameLen/2:], x)
+	frames := godsp.STFT(padded, frameLen, hop, window)
+
+	numBins := frameLen/2 + 1
+	omega := make([]float64, numBins)
+	for k := range omega {
+		omega[k] = 2 * math.Pi * float64(k) * float64(hop) / float64(frameLen)
+	}
+	phase := make([]float64, numBins)
+	for k, c := range frames[0] {
+		phase[k] = cmplx.Phase(c)
+	}
+	mag := make([]float64, numBins)
+	Y := make([]complex128, frameLen)
+
+	numSteps := int(math.Ceil(float64(len(frames)-1) * ratio))
+	y := make([]float64, (numSteps-1)*hop+frameLen)
+	wsum := make([]float64, len(y))
+	for j := 0; j < numSteps; j++ {
+		t := float64(j) / ratio
+		i := int(t)
+		if i+1 >= len(frames) {
+			break
+		}
+		alpha := t - float64(i)
+		for k := range mag {
+			mag[k] = (1-alpha)*cmplx.Abs(frames[i][k]) + alpha*cmplx.Abs(frames[i+1][k])
+		}
+		lockPhases(Y[:numBins], mag, phase, frames[i])
+		for k := 1; k < numBins-1; k++ {
+			Y[frameLen-k] = cmplx.Conj(Y[k])
+		}
+		frame := godsp.IFFTReal(Y)
+		start := j * hop
+		for n, f := range frame {
+			y[start+n] += f * w[n]
+			wsum[start+n] += w[n] * w[n]
+		}
+		// Advance the phases by the instantaneous frequencies of the bins
+		for k := range phase {
+			dphi := cmplx.Phase(frames[i+1][k]) - cmplx.Phase(frames[i][k]) - omega[k]
+			phase[k] += omega[k] + princarg(dphi)
+		}
+	}
+	for n := range y {
+		if wsum[n] > 1e-8 {
+			y[n] /= wsum[n]
+		}
+	}
+	out := make([]float64, outLen)
+	copy(out, y[frameLen/2:])
+	return out
+}
+
+/*
+PitchShift returns x shifted in pitch by semitones without changing its
+duration. x is time stretched by 2^(semitones/12) and resampled to its
+original length by godsp.InterpSinc.
+See TimeStretch for frameLen and hop.
+*/
+func PitchShift(x []float64, semitones float64, frameLen, hop int) []float64 {
+	if len(x) == 0 {
+		return []float64{}
+	}
+	y := TimeStretch(x, math.Pow(2, semitones/12), frameLen, hop)
+	return godsp.InterpTo(y, len(x), godsp.InterpSinc)
+}
+
+/*
+lockPhases stores the spectrum with magnitudes mag in Y. The phases of the
+peaks of mag are phase. Every other bin has the phase of its nearest peak
+plus its phase offset from the peak in the analysis frame X.
+*/
+func lockPhases(Y []complex128, mag, phase []float64, X []complex128) {
+	peaks := make([]int, 0, len(mag)/2)
+	for k := range mag {
+		if (k == 0 || mag[k] > mag[k-1]) && (k == len(mag)-1 || mag[k] >= mag[k+1]) {
+			peaks = append(peaks, k)
+		}
+	}
+	p := 0
+	for k := range Y {
+		// Move to the nearest peak
+		for p+1 < len(peaks) && peaks[p+1]-k < k-peaks[p] {
+			p++
+		}
+		ph := phase[k]
+		if len(peaks) > 0 {
+			pk := peaks[p]
+			ph = phase[pk] + cmplx.Phase(X[k]) - cmplx.Phase(X[pk])
+		}
+		Y[k] = cmplx.Rect(mag[k], ph)
+	}
+}
+
+// princarg returns phi wrapped to [-pi,pi)
+func princarg(phi float64) float64 {
+	return phi - 2*math.Pi*math.Floor((phi+math.Pi)/(2*math.Pi))
+}
@@ -0,0 +1,43 @@
+package vocoder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goccmack/godsp"
+	"github.com/goccmack/godsp/gen"
+)
+
+func TestTimeStretch(t *testing.T) {
+	fs := 8000.0
+	x := gen.Sine(440, fs, 1, 0.5, 0)
+	for _, ratio := range []float64{0.75, 1.5} {
+		y := TimeStretch(x, ratio, 1024, 256)
+		if want := int(math.Round(8000 * ratio)); len(y) != want {
+			t.Fatalf("len(y) = %d, want %d", len(y), want)
+		}
+		if f := pitch(y[1024:len(y)-1024], fs); math.Abs(f-440) > 5 {
+			t.Errorf("ratio %f: pitch = %f", ratio, f)
+		}
+		if rms := godsp.RMS(y[1024 : len(y)-1024]); math.Abs(rms-0.5/math.Sqrt2) > 0.05 {
+			t.Errorf("ratio %f: rms = %f", ratio, rms)
+		}
+	}
+}
+
+func TestPitchShift(t *testing.T) {
+	fs := 8000.0
+	x := gen.Sine(300, fs, 1, 0.5, 0)
+	y := PitchShift(x, 7, 1024, 256)
+	if len(y) != len(x) {
+		t.Fatalf("len(y) = %d", len(y))
+	}
+	want := 300 * math.Pow(2, 7.0/12)
+	if f := pitch(y[1024:len(y)-1024], fs); math.Abs(f-want) > 5 {
+		t.Errorf("pitch = %f, want %f", f, want)
+	}
+}
+
+func pitch(x []float64, fs float64) float64 {
+	return godsp.Median(godsp.PitchYIN(x, fs, 512, 256))
+}
@@ -0,0 +1,289 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// wav format tags
+const (
+	wavFormatPCM        = 1
+	wavFormatIEEEFloat  = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+/*
+Metadata contains the chunks of a wav file other than the samples.
+*/
+type Metadata struct {
+	// Chunks are the IDs of all chunks of the file in file order
+	Chunks []string
+	// Info contains the text tags of the LIST INFO chunk, e.g. Info["INAM"] is the title
+	Info map[string]string
+	// Bext is the broadcast wave extension chunk, or nil if the file has none
+	Bext *Bext
+	// CuePoints are the sample offsets of the cue points of the cue chunk
+	CuePoints []int
+}
+
+// Bext contains the text fields and time reference of a broadcast wave bext chunk
+type Bext struct {
+	Description         string
+	Originator          string
+	OriginatorReference string
+	OriginationDate     string
+	OriginationTime     string
+	// TimeReference is the sample count since midnight of the first sample
+	TimeReference uint64
+}
+
+// wavFile is a parsed wav file
+type wavFile struct {
+	audioFormat   int
+	numChannels   int
+	sampleRate    int
+	bitsPerSample int
+	data          []byte
+	meta          *Metadata
+}
+
+/*
+parseWav parses the RIFF chunks of the wav file b. Unknown chunks are skipped.
+A data chunk whose size exceeds the file is truncated to the end of the file,
+as written by interrupted or streaming recorders. Other malformed chunks
+return an error.
+*/
+func parseWav(b []byte) (*wavFile, error) {
+	if len(b) < 12 || string(b[:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: not a RIFF WAVE file", ErrArgument)
+	}
+	w := &wavFile{meta: &Metadata{Info: map[string]string{}}}
+	hasFmt, hasData := false, false
+	for pos := 12; pos+8 <= len(b); {
+		id, size32 := string(b[pos:pos+4]), binary.LittleEndian.Uint32(b[pos+4:])
+		pos += 8
+		w.meta.Chunks = append(w.meta.Chunks, id)
+		// The size is compared unsigned because it may not fit an int
+		if uint64(size32) > uint64(len(b)-pos) {
+			if id != "data" {
+				return nil, fmt.Errorf("%w: %q chunk size %d exceeds file", ErrLength, id, size32)
+			}
+			size32 = uint32(len(b) - pos)
+		}
+		size := int(size32)
+		chunk := b[pos : pos+size]
+		switch id {
+		case "fmt ":
+			if err := w.parseFmt(chunk); err != nil {
+				return nil, err
+			}
+			hasFmt = true
+		case "data":
+			w.data, hasData = chunk, true
+		case "LIST":
+			parseInfo(chunk, w.meta.Info)
+		case "bext":
+			w.meta.Bext = parseBext(chunk)
+		case "cue ":
+			w.meta.CuePoints = parseCue(chunk)
+		}
+		// Chunks are padded to an even size
+		pos += size + size%2
+	}
+	if !hasFmt || !hasData {
+		return nil, fmt.Errorf("%w: wav file without fmt or data chunk", ErrArgument)
+	}
+	return w, nil
+}
+
+// parseFmt parses the fmt chunk
+func (w *wavFile) parseFmt(chunk []byte) error {
+	if len(chunk) < 16 {
+		return fmt.Errorf("%w: fmt chunk size %d", ErrLength, len(chunk))
+	}
+	w.audioFormat = int(binary.LittleEndian.Uint16(chunk))
+	w.numChannels = int(binary.LittleEndian.Uint16(chunk[2:]))
+	w.sampleRate = int(binary.LittleEndian.Uint32(chunk[4:]))
+	w.bitsPerSample = int(binary.LittleEndian.Uint16(chunk[14:]))
+	if w.audioFormat == wavFormatExtensible {
+		// The format tag is the first 2 bytes of the sub format GUID
+		if len(chunk) < 26 {
+			return fmt.Errorf("%w: extensible fmt chunk size %d", ErrLength, len(chunk))
+		}
+		w.audioFormat = int(binary.LittleEndian.Uint16(chunk[24:]))
+	}
+	if w.numChannels == 0 {
+		return fmt.Errorf("%w: wav file has no channels", ErrArgument)
+	}
+	return nil
+}
+
+// parseInfo adds the tags of a LIST INFO chunk to info
+func parseInfo(chunk []byte, info map[string]string) {
+	if len(chunk) < 4 || string(chunk[:4]) != "INFO" {
+		return
+	}
+	for pos := 4; pos+8 <= len(chunk); {
+		id, size32 := string(chunk[pos:pos+4]), binary.LittleEndian.Uint32(chunk[pos+4:])
+		pos += 8
+		if uint64(size32) > uint64(len(chunk)-pos) {
+			return
+		}
+		size := int(size32)
+		info[id] = cString(chunk[pos : pos+size])
+		pos += size + size%2
+	}
+}
+
+// parseBext returns the bext chunk, or nil if it is too short
+func parseBext(chunk []byte) *Bext {
+	if len(chunk) < 346 {
+		return nil
+	}
+	return &Bext{
+		Description:         cString(chunk[:256]),
+		Originator:          cString(chunk[256:288]),
+		OriginatorReference: cString(chunk[288:320]),
+		OriginationDate:     cString(chunk[320:330]),
+		OriginationTime:     cString(chunk[330:338]),
+		TimeReference:       binary.LittleEndian.Uint64(chunk[338:]),
+	}
+}
+
+// parseCue returns the sample offsets of the cue points of a cue chunk
+func parseCue(chunk []byte) []int {
+	if len(chunk) < 4 {
+		return nil
+	}
+	n := int(binary.LittleEndian.Uint32(chunk))
+	cues := []int{}
+	for i := 0; i < n && 4+24*(i+1) <= len(chunk); i++ {
+		// Each cue point is ID, position, chunk ID, chunk start, block start, sample offset
+		cues = append(cues, int(binary.LittleEndian.Uint32(chunk[4+24*i+20:])))
+	}
+	return cues
+}
+
+// cString returns b up to its first NUL byte
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+/*
+wavSampleDecoder returns the number of samples in data, which have the
+audioFormat and bitsPerSample of a wav file, and a function that returns sample
+i. PCM samples are mapped linearly from their integer range to [0,1]. IEEE float
+samples are returned unchanged.
+*/
+func wavSampleDecoder(data []byte, audioFormat, bitsPerSample int) (n int, sample func(i int) float64, err error) {
+	switch {
+	case audioFormat == wavFormatPCM && bitsPerSample == 8:
+		// 8 bit wav samples are unsigned
+		return len(data), func(i int) float64 {
+			return float64(data[i]) / math.MaxUint8
+		}, nil
+	case audioFormat == wavFormatPCM:
+		return pcmDecoder(data, bitsPerSample, false)
+	case audioFormat == wavFormatIEEEFloat && bitsPerSample == 32:
+		return len(data) / 4, func(i int) float64 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+		}, nil
+	case audioFormat == wavFormatIEEEFloat && bitsPerSample == 64:
+		return len(data) / 8, func(i int) float64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		}, nil
+	}
+	return 0, nil, fmt.Errorf("%w: wav audio format %d with %d bits per sample",
+		ErrArgument, audioFormat, bitsPerSample)
+}
+
+/*
+decodeWavChannels returns the demultiplexed samples of w with element type T.
+The samples are decoded directly to their channels, without a multiplexed
+float64 copy. A trailing incomplete frame is dropped.
+*/
+func decodeWavChannels[T Float](w *wavFile) ([][]T, error) {
+	n, sample, err := wavSampleDecoder(w.data, w.audioFormat, w.bitsPerSample)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([][]T, w.numChannels)
+	chanLen := n / w.numChannels
+	for c := range channels {
+		channels[c] = make([]T, chanLen)
+	}
+	for i, j := 0, 0; j < chanLen; j++ {
+		for _, ch := range channels {
+			ch[j] = T(sample(i))
+			i++
+		}
+	}
+	return channels, nil
+}
+
+/*
+decodePCM returns the signed integer samples of bitsPerSample in data mapped
+linearly from their integer range to [0,1].
+*/
+func decodePCM(data []byte, bitsPerSample int, bigEndian bool) ([]float64, error) {
+	n, sample, err := pcmDecoder(data, bitsPerSample, bigEndian)
+	if err != nil {
+		return nil, err
+	}
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = sample(i)
+	}
+	return x, nil
+}
+
+/*
+pcmDecoder returns the number of signed integer samples of bitsPerSample in
+data and a function that returns sample i mapped linearly from its integer
+range to [0,1].
+*/
+func pcmDecoder(data []byte, bitsPerSample int, bigEndian bool) (n int, sample func(i int) float64, err error) {
+	bytesPerSample := bitsPerSample / 8
+	if bitsPerSample%8 != 0 || bytesPerSample < 1 || bytesPerSample > 4 {
+		return 0, nil, fmt.Errorf("%w: PCM bits per sample %d", ErrArgument, bitsPerSample)
+	}
+	offset, span := math.Ldexp(1, bitsPerSample-1), math.Ldexp(1, bitsPerSample)-1
+	return len(data) / bytesPerSample, func(i int) float64 {
+		b := data[i*bytesPerSample : (i+1)*bytesPerSample]
+		return (float64(pcmSample(b, bigEndian)) + offset) / span
+	}, nil
+}
+
+// pcmSample returns the signed integer in b
+func pcmSample(b []byte, bigEndian bool) int64 {
+	var v int64
+	for i := range b {
+		if bigEndian {
+			v = v<<8 | int64(b[i])
+		} else {
+			v = v<<8 | int64(b[len(b)-1-i])
+		}
+	}
+	// Sign extend
+	shift := 64 - 8*uint(len(b))
+	return v << shift >> shift
+}
@@ -15,42 +15,118 @@
 package godsp
 
 import (
-	"bytes"
+	"io"
 	"io/ioutil"
-
-	"github.com/mjibson/go-dsp/wav"
 )
 
 /*
 ReadWavFile returns the demultiplexed channels of a wav file, and the sample rate in Hz.
+PCM, IEEE float and extensible wav files are supported.
+8, 16, 24 and 32 bit PCM samples are mapped linearly to [0,1]. 32 and 64 bit
+IEEE float samples are returned unchanged.
+The progress of reading the file is reported to the function of WithProgress.
 */
-func ReadWavFile(wavName string) (channels [][]float64, sampleRate, bitsPerSample int) {
-	buf, err := ioutil.ReadFile(wavName)
+func ReadWavFile(wavName string, opts ...Option) (channels [][]float64, sampleRate, bitsPerSample int) {
+	channels, sampleRate, bitsPerSample, err := ReadWavFileErr(wavName, opts...)
 	if err != nil {
 		panic(err)
 	}
-	rdr, err := wav.New(bytes.NewBuffer(buf))
+	return
+}
+
+/*
+ReadWavFileErr returns ReadWavFile(wavName) or the error reading or decoding
+the file. Chunks other than fmt and data are ignored. See ReadWavMetadata.
+*/
+func ReadWavFileErr(wavName string, opts ...Option) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	buf, err := readFile(wavName, getOptions(opts))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return decodeWav[float64](buf)
+}
+
+/*
+ReadWavFile32 returns the channels of a wav file as ReadWavFile, but with
+float32 samples. The samples are decoded directly to float32, which halves the
+memory of the channels of long files.
+*/
+func ReadWavFile32(wavName string, opts ...Option) (channels [][]float32, sampleRate, bitsPerSample int) {
+	channels, sampleRate, bitsPerSample, err := ReadWavFile32Err(wavName, opts...)
 	if err != nil {
 		panic(err)
 	}
-	numSamples, numChannels := rdr.Samples, int(rdr.NumChannels)
-	sampleRate = int(rdr.SampleRate)
-	bitsPerSample = int(rdr.Header.BitsPerSample)
-	channels = make([][]float64, numChannels)
-	chanLen := numSamples / numChannels
-	for i := range channels {
-		channels[i] = make([]float64, chanLen)
+	return
+}
+
+/*
+ReadWavFile32Err returns ReadWavFile32(wavName) or the error reading or
+decoding the file.
+*/
+func ReadWavFile32Err(wavName string, opts ...Option) (channels [][]float32, sampleRate, bitsPerSample int, err error) {
+	buf, err := readFile(wavName, getOptions(opts))
+	if err != nil {
+		return nil, 0, 0, err
 	}
-	samples, err := rdr.ReadFloats(rdr.Samples)
+	return decodeWav[float32](buf)
+}
+
+/*
+ReadWavFrom returns the demultiplexed channels, sample rate and bits per sample
+of the wav file read from r, as by ReadWavFile, or the error reading or
+decoding r.
+*/
+func ReadWavFrom(r io.Reader) (channels [][]float64, sampleRate, bitsPerSample int, err error) {
+	buf, err := ioutil.ReadAll(r)
 	if err != nil {
-		panic(err)
+		return nil, 0, 0, err
 	}
-	for i, j := 0, 0; i < len(samples); {
-		for _, ch := range channels {
-			ch[j] = float64(samples[i])
-			i++
-		}
-		j++
+	return decodeWav[float64](buf)
+}
+
+/*
+decodeWav returns the channels with element type T, sample rate and bits per
+sample of the wav file b
+*/
+func decodeWav[T Float](b []byte) (channels [][]T, sampleRate, bitsPerSample int, err error) {
+	w, err := parseWav(b)
+	if err != nil {
+		return nil, 0, 0, err
 	}
-	return
+	if channels, err = decodeWavChannels[T](w); err != nil {
+		return nil, 0, 0, err
+	}
+	return channels, w.sampleRate, w.bitsPerSample, nil
+}
+
+/*
+ReadWavMetadata returns the metadata of a wav file or the error reading or
+parsing the file.
+*/
+func ReadWavMetadata(wavName string) (*Metadata, error) {
+	buf, err := ioutil.ReadFile(wavName)
+	if err != nil {
+		return nil, err
+	}
+	return wavMetadata(buf)
+}
+
+/*
+ReadWavMetadataFrom returns the metadata of the wav file read from r or the
+error reading or parsing r.
+*/
+func ReadWavMetadataFrom(r io.Reader) (*Metadata, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return wavMetadata(buf)
+}
+
+func wavMetadata(b []byte) (*Metadata, error) {
+	w, err := parseWav(b)
+	if err != nil {
+		return nil, err
+	}
+	return w.meta, nil
 }
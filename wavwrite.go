@@ -0,0 +1,156 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package godsp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	myioutil "github.com/goccmack/goutil/ioutil"
+)
+
+// WavEncoding selects the encoding of the samples written by WriteWavFile
+type WavEncoding int
+
+const (
+	// WavPCM encodes the samples as integers, the inverse of the mapping of ReadWavFile
+	WavPCM WavEncoding = iota
+	// WavFloat encodes the samples as IEEE floats
+	WavFloat
+)
+
+// wavHeaderLen is the length of the RIFF header, fmt chunk and data chunk header of WriteWavTo
+const wavHeaderLen = 44
+
+/*
+WriteWavFile writes the channels to the wav file wavName with sampleRate in Hz.
+The channels are interleaved. PCM samples of 8, 16, 24 and 32 bits are mapped
+linearly from [0,1] to their integer range, which is the inverse of the
+mapping of ReadWavFile. PCM samples outside [0,1] are clipped. IEEE float
+samples of 32 and 64 bits are written unchanged.
+The function panics if the file cannot be written or the arguments are invalid.
+See WriteWavTo.
+*/
+func WriteWavFile(wavName string, channels [][]float64, sampleRate, bitsPerSample int, encoding WavEncoding) {
+	if err := WriteWavFileErr(wavName, channels, sampleRate, bitsPerSample, encoding); err != nil {
+		panic(err)
+	}
+}
+
+// WriteWavFileErr is WriteWavFile returning the error.
+func WriteWavFileErr(wavName string, channels [][]float64, sampleRate, bitsPerSample int, encoding WavEncoding) error {
+	buf := new(bytes.Buffer)
+	if err := WriteWavTo(buf, channels, sampleRate, bitsPerSample, encoding); err != nil {
+		return err
+	}
+	return myioutil.WriteFile(wavName, buf.Bytes())
+}
+
+/*
+WriteWavTo writes the channels to w in the format of WriteWavFile. It returns
+the write error, an error wrapping ErrArgument if there are no channels,
+sampleRate < 1 or encoding does not support bitsPerSample, or an error
+wrapping ErrLength if the channels have different lengths or the data does not
+fit a wav file.
+*/
+func WriteWavTo(w io.Writer, channels [][]float64, sampleRate, bitsPerSample int, encoding WavEncoding) error {
+	format, encode, err := wavSampleEncoder(encoding, bitsPerSample)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 || sampleRate < 1 {
+		return fmt.Errorf("%w: %d channels, sample rate %d", ErrArgument, len(channels), sampleRate)
+	}
+	n := len(channels[0])
+	for i, ch := range channels {
+		if len(ch) != n {
+			return fmt.Errorf("%w: len(channels[%d]) (%d) != len(channels[0]) (%d)", ErrLength, i, len(ch), n)
+		}
+	}
+	numCh, bytesPerSample := len(channels), bitsPerSample/8
+	dataLen := uint64(n) * uint64(numCh) * uint64(bytesPerSample)
+	if dataLen > math.MaxUint32-(wavHeaderLen-8) {
+		return fmt.Errorf("%w: %d bytes of samples", ErrLength, dataLen)
+	}
+	b := make([]byte, wavHeaderLen+int(dataLen))
+	le := binary.LittleEndian
+	copy(b, "RIFF")
+	le.PutUint32(b[4:], uint32(wavHeaderLen-8+dataLen))
+	copy(b[8:], "WAVEfmt ")
+	le.PutUint32(b[16:], 16)
+	le.PutUint16(b[20:], uint16(format))
+	le.PutUint16(b[22:], uint16(numCh))
+	le.PutUint32(b[24:], uint32(sampleRate))
+	le.PutUint32(b[28:], uint32(sampleRate*numCh*bytesPerSample))
+	le.PutUint16(b[32:], uint16(numCh*bytesPerSample))
+	le.PutUint16(b[34:], uint16(bitsPerSample))
+	copy(b[36:], "data")
+	le.PutUint32(b[40:], uint32(dataLen))
+	pos := wavHeaderLen
+	for i := 0; i < n; i++ {
+		for _, ch := range channels {
+			encode(b[pos:pos+bytesPerSample], ch[i])
+			pos += bytesPerSample
+		}
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+/*
+wavSampleEncoder returns the wav audio format of encoding and bitsPerSample and
+a function that encodes a sample to b, which has bitsPerSample/8 bytes. It is
+the inverse of wavSampleDecoder.
+*/
+func wavSampleEncoder(encoding WavEncoding, bitsPerSample int) (audioFormat int, encode func(b []byte, f float64), err error) {
+	switch {
+	case encoding == WavPCM && bitsPerSample == 8:
+		// 8 bit wav samples are unsigned
+		return wavFormatPCM, func(b []byte, f float64) {
+			b[0] = byte(clipSample(math.Round(f*math.MaxUint8), 0, math.MaxUint8))
+		}, nil
+	case encoding == WavPCM && (bitsPerSample == 16 || bitsPerSample == 24 || bitsPerSample == 32):
+		offset, span := math.Ldexp(1, bitsPerSample-1), math.Ldexp(1, bitsPerSample)-1
+		return wavFormatPCM, func(b []byte, f float64) {
+			v := int64(clipSample(math.Round(f*span-offset), -offset, offset-1))
+			for i := range b {
+				b[i] = byte(v >> (8 * uint(i)))
+			}
+		}, nil
+	case encoding == WavFloat && bitsPerSample == 32:
+		return wavFormatIEEEFloat, func(b []byte, f float64) {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(f)))
+		}, nil
+	case encoding == WavFloat && bitsPerSample == 64:
+		return wavFormatIEEEFloat, func(b []byte, f float64) {
+			binary.LittleEndian.PutUint64(b, math.Float64bits(f))
+		}, nil
+	}
+	return 0, nil, fmt.Errorf("%w: wav encoding %d with %d bits per sample", ErrArgument, encoding, bitsPerSample)
+}
+
+// clipSample returns f limited to [lo,hi]. NaN is clipped to lo.
+func clipSample(f, lo, hi float64) float64 {
+	if !(f >= lo) {
+		return lo
+	}
+	if f > hi {
+		return hi
+	}
+	return f
+}
@@ -0,0 +1,175 @@
+//  Copyright 2019 Marius Ackerman
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+/*
+Package windows has window functions for spectral analysis and FIR filter
+design. All windows are symmetric. Use Periodic to obtain the periodic windows
+required for perfect reconstruction by overlap-add.
+
+Windows with a parameter are passed to godsp.STFT by a closure:
+
+	godsp.STFT(x, 1024, 256, func(N int) []float64 { return windows.Kaiser(N, 8) })
+*/
+package windows
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/goccmack/godsp"
+)
+
+/*
+ApplyWindow returns x multiplied element-wise by the window w.
+The function panics if len(x) != len(w).
+*/
+func ApplyWindow(x, w []float64) []float64 {
+	if len(x) != len(w) {
+		panic(fmt.Sprintf("len(x) (%d) != len(w) (%d)", len(x), len(w)))
+	}
+	y := make([]float64, len(x))
+	for i := range x {
+		y[i] = x[i] * w[i]
+	}
+	return y
+}
+
+/*
+Periodic returns the periodic version of window, which is the symmetric window
+of length N+1 without its last sample.
+*/
+func Periodic(window godsp.Window) godsp.Window {
+	return func(N int) []float64 {
+		return window(N + 1)[:N]
+	}
+}
+
+// Rectangular returns the rectangular window of length N
+func Rectangular(N int) []float64 {
+	return cosineSum(N, 1)
+}
+
+// Hann returns the Hann window of length N
+func Hann(N int) []float64 {
+	return cosineSum(N, 0.5, 0.5)
+}
+
+// Hamming returns the Hamming window of length N
+func Hamming(N int) []float64 {
+	return cosineSum(N, 0.54, 0.46)
+}
+
+// Blackman returns the Blackman window of length N
+func Blackman(N int) []float64 {
+	return cosineSum(N, 0.42, 0.5, 0.08)
+}
+
+// BlackmanHarris returns the 4-term Blackman-Harris window of length N
+func BlackmanHarris(N int) []float64 {
+	return cosineSum(N, 0.35875, 0.48829, 0.14128, 0.01168)
+}
+
+/*
+Gaussian returns the Gaussian window of length N with standard deviation sigma
+samples.
+*/
+func Gaussian(N int, sigma float64) []float64 {
+	w := make([]float64, N)
+	c := float64(N-1) / 2
+	for n := range w {
+		d := (float64(n) - c) / sigma
+		w[n] = math.Exp(-d * d / 2)
+	}
+	return w
+}
+
+/*
+Kaiser returns the Kaiser window of length N with shape parameter beta.
+beta = 0 gives a rectangular window; larger values give lower side lobes and a
+wider main lobe.
+*/
+func Kaiser(N int, beta float64) []float64 {
+	w := make([]float64, N)
+	if N == 1 {
+		w[0] = 1
+		return w
+	}
+	for n := range w {
+		r := 2*float64(n)/float64(N-1) - 1
+		w[n] = besselI0(beta*math.Sqrt(1-r*r)) / besselI0(beta)
+	}
+	return w
+}
+
+/*
+Tukey returns the Tukey (tapered cosine) window of length N. alpha is the
+fraction of the window inside the cosine tapers: alpha = 0 gives a rectangular
+window and alpha = 1 a Hann window.
+*/
+func Tukey(N int, alpha float64) []float64 {
+	w := make([]float64, N)
+	if N == 1 || alpha <= 0 {
+		for n := range w {
+			w[n] = 1
+		}
+		return w
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+	width := alpha * float64(N-1) / 2
+	for n := range w {
+		m := float64(n)
+		if m > float64(N-1)/2 {
+			m = float64(N-1) - m
+		}
+		if m < width {
+			w[n] = 0.5 * (1 - math.Cos(math.Pi*m/width))
+		} else {
+			w[n] = 1
+		}
+	}
+	return w
+}
+
+/*
+cosineSum returns the window sum_k (-1)^k a[k] cos(2 pi k n / (N-1)) of
+length N.
+*/
+func cosineSum(N int, a ...float64) []float64 {
+	w := make([]float64, N)
+	if N == 1 {
+		w[0] = 1
+		return w
+	}
+	for n := range w {
+		sign := 1.0
+		for k, ak := range a {
+			w[n] += sign * ak * math.Cos(2*math.Pi*float64(k*n)/float64(N-1))
+			sign = -sign
+		}
+	}
+	return w
+}
+
+// besselI0 returns the modified Bessel function of the first kind of order 0.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > 1e-17*sum; k++ {
+		f := x / (2 * float64(k))
+		term *= f * f
+		sum += term
+	}
+	return sum
+}
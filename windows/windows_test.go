@@ -0,0 +1,138 @@
+package windows
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goccmack/godsp"
+)
+
+var allWindows = []struct {
+	name string
+	f    godsp.Window
+	// end is the value of the first and last samples
+	end float64
+}{
+	{"Rectangular", Rectangular, 1},
+	{"Hann", Hann, 0},
+	{"Hamming", Hamming, 0.08},
+	{"Blackman", Blackman, 0},
+	{"BlackmanHarris", BlackmanHarris, 0.35875 - 0.48829 + 0.14128 - 0.01168},
+	{"Gaussian", func(N int) []float64 { return Gaussian(N, 2) }, math.Exp(-4.0 / 2)},
+	{"Kaiser", func(N int) []float64 { return Kaiser(N, 8) }, 1 / besselI0(8)},
+	{"Tukey", func(N int) []float64 { return Tukey(N, 0.5) }, 0},
+}
+
+func TestSymmetric(t *testing.T) {
+	for _, win := range allWindows {
+		for _, N := range []int{9, 10} {
+			w := win.f(N)
+			if len(w) != N {
+				t.Fatalf("%s(%d): len = %d", win.name, N, len(w))
+			}
+			for n := range w {
+				if math.Abs(w[n]-w[N-1-n]) > 1e-12 {
+					t.Errorf("%s(%d): w[%d] = %f, w[%d] = %f", win.name, N, n, w[n], N-1-n, w[N-1-n])
+				}
+				if w[n] < -1e-12 || w[n] > 1+1e-12 {
+					t.Errorf("%s(%d): w[%d] = %f", win.name, N, n, w[n])
+				}
+			}
+		}
+		// The odd length windows have their peak of 1 at the centre
+		w := win.f(9)
+		if math.Abs(w[4]-1) > 1e-12 {
+			t.Errorf("%s(9): w[4] = %f, want 1", win.name, w[4])
+		}
+		if math.Abs(w[0]-win.end) > 1e-12 {
+			t.Errorf("%s(9): w[0] = %g, want %g", win.name, w[0], win.end)
+		}
+		if w := win.f(1); len(w) != 1 || w[0] != 1 {
+			t.Errorf("%s(1) = %v", win.name, w)
+		}
+	}
+}
+
+func TestCoefficients(t *testing.T) {
+	// At n = (N-1)/4 the cosines of the Blackman-Harris window are 0, -1, 0
+	if w := BlackmanHarris(9); math.Abs(w[2]-(0.35875-0.14128)) > 1e-12 {
+		t.Errorf("BlackmanHarris(9)[2] = %f", w[2])
+	}
+	if w := Hamming(9); math.Abs(w[2]-0.54) > 1e-12 {
+		t.Errorf("Hamming(9)[2] = %f", w[2])
+	}
+	if w := Hann(9); math.Abs(w[2]-0.5) > 1e-12 {
+		t.Errorf("Hann(9)[2] = %f", w[2])
+	}
+	if w := Blackman(9); math.Abs(w[2]-0.34) > 1e-12 {
+		t.Errorf("Blackman(9)[2] = %f", w[2])
+	}
+	if i0 := besselI0(1); math.Abs(i0-1.2660658777520082) > 1e-15 {
+		t.Errorf("besselI0(1) = %.16f", i0)
+	}
+	// The Gaussian window at one standard deviation from the centre
+	if w := Gaussian(9, 2); math.Abs(w[2]-math.Exp(-0.5)) > 1e-12 {
+		t.Errorf("Gaussian(9, 2)[2] = %f", w[2])
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		got  []float64
+		want []float64
+	}{
+		{"Tukey(0)", Tukey(10, 0), Rectangular(10)},
+		{"Tukey(1)", Tukey(10, 1), Hann(10)},
+		{"Tukey(2)", Tukey(10, 2), Hann(10)},
+		{"Kaiser(0)", Kaiser(10, 0), Rectangular(10)},
+	} {
+		for n := range test.want {
+			if math.Abs(test.got[n]-test.want[n]) > 1e-12 {
+				t.Errorf("%s: w[%d] = %f, want %f", test.name, n, test.got[n], test.want[n])
+			}
+		}
+	}
+	// The middle half of Tukey(0.5) is flat
+	w := Tukey(21, 0.5)
+	for n := 5; n <= 15; n++ {
+		if w[n] != 1 {
+			t.Errorf("Tukey(21, 0.5)[%d] = %f", n, w[n])
+		}
+	}
+}
+
+func TestPeriodic(t *testing.T) {
+	w, sym := Periodic(Hann)(8), Hann(9)
+	if len(w) != 8 {
+		t.Fatalf("len = %d", len(w))
+	}
+	for n := range w {
+		if w[n] != sym[n] {
+			t.Errorf("w[%d] = %f, want %f", n, w[n], sym[n])
+		}
+		// The periodic Hann window overlap-adds to 1 at 50% overlap
+		if n < 4 && math.Abs(w[n]+w[n+4]-1) > 1e-12 {
+			t.Errorf("w[%d] + w[%d] = %f", n, n+4, w[n]+w[n+4])
+		}
+	}
+}
+
+func TestApplyWindow(t *testing.T) {
+	x, w := []float64{1, 2, 3, 4}, []float64{0, 0.5, 1, 0.25}
+	y := ApplyWindow(x, w)
+	for i, want := range []float64{0, 1, 3, 1} {
+		if y[i] != want {
+			t.Errorf("y[%d] = %f, want %f", i, y[i], want)
+		}
+	}
+	if x[1] != 2 {
+		t.Error("x was modified")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for len(x) != len(w)")
+		}
+	}()
+	ApplyWindow(x, w[:3])
+}